@@ -0,0 +1,341 @@
+package aip
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
+)
+
+const (
+	// WILDCARD é o caminho do FieldMask que seleciona todos os campos
+	WILDCARD string = "*"
+
+	// DESC e ASC são as direções de ordenação aceitas no "order_by"
+	DESC string = "desc"
+	ASC  string = "asc"
+
+	// check é a chave da verificação dos filtros e da ordenação no
+	// conteúdo do page_token
+	check string = "check"
+)
+
+// ErrInvalidPageToken é devolvido quando o page_token não foi gerado por
+// NextPageToken ou foi gerado para outros filtros ou outra ordenação.
+var ErrInvalidPageToken = errors.New("invalid page_token")
+
+// ListRequest são os campos de paginação, seleção e ordenação de uma
+// request de listagem (AIP-132).
+type ListRequest struct {
+	// ReadMask são os caminhos do google.protobuf.FieldMask
+	ReadMask []string
+	// Filter é a expressão AIP-160, que depende de
+	// gosparse.FilterExpressions
+	Filter string
+	// OrderBy é a ordenação no formato "created_at desc, title"
+	OrderBy string
+	// PageSize é o tamanho da página, zero utiliza o tamanho padrão
+	PageSize int32
+	// PageToken é o token devolvido por NextPageToken na request anterior
+	PageToken string
+}
+
+// Values devolve a querystring equivalente à request, aceita por
+// gosparse.Gosparse.Parse.
+//
+// A verificação do page_token depende do Query, portanto é feita somente
+// por Parse.
+func (r ListRequest) Values() (url.Values, error) {
+	values, _, err := r.values()
+	return values, err
+}
+
+// values devolve a querystring equivalente à request e a verificação
+// contida no page_token.
+func (r ListRequest) values() (url.Values, string, error) {
+	query := url.Values{}
+
+	if r.PageSize < 0 {
+		return nil, "", fmt.Errorf("page_size should not be negative")
+	}
+
+	mask, err := ParseFieldMask(r.ReadMask)
+	if err != nil {
+		return nil, "", err
+	}
+
+	order, err := ParseOrderBy(r.OrderBy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	page, checksum, err := decodeToken(r.PageToken)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, values := range []url.Values{mask, order, page} {
+		for key, value := range values {
+			query[key] = value
+		}
+	}
+
+	if strings.TrimSpace(r.Filter) != "" {
+		query.Set(filter.SEARCH_PARAM, r.Filter)
+	}
+
+	if r.PageSize > 0 {
+		query.Set(param(pagination.SIZE), strconv.Itoa(int(r.PageSize)))
+	}
+
+	return query, checksum, nil
+}
+
+// Parse converte a request e devolve o Query extraído pelo Gosparse,
+// repassando o contexto para o Authorizer.
+//
+// Caso o page_token tenha sido gerado para outros filtros ou outra
+// ordenação será devolvido ErrInvalidPageToken.
+func Parse(ctx context.Context, g gosparse.Gosparse, r ListRequest) (*gosparse.Query, error) {
+	query, checksum, err := r.values()
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := g.ParseContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if checksum != "" && checksum != fingerprint(q) {
+		return nil, ErrInvalidPageToken
+	}
+
+	return q, nil
+}
+
+// FieldMask -----------------------------
+
+// FieldMask devolve os caminhos do FieldMask com a seleção dos dados
+// primários do Query, em ordem alfabética, inversa de ParseFieldMask.
+//
+// Caso a seleção não tenha sido solicitada (seleção padrão) será devolvido
+// nil, ou seja, um FieldMask vazio.
+func FieldMask(q *gosparse.Query) []string {
+	fields := q.Encode().Get(sparsefieldsets.SEARCH_PARAM)
+	if fields == "" {
+		return nil
+	}
+
+	return strings.Split(fields, ",")
+}
+
+// ParseFieldMask devolve o parâmetro "fields" com os caminhos do
+// FieldMask:
+//
+//	ParseFieldMask([]string{"title", "author.name"})
+//	// url.Values{"fields": {"title,author.name"}}
+//
+// Um FieldMask vazio ou com somente o caminho "*" seleciona todos os
+// campos, portanto devolve uma query vazia.
+func ParseFieldMask(paths []string) (url.Values, error) {
+	query := url.Values{}
+
+	if len(paths) == 1 && paths[0] == WILDCARD {
+		return query, nil
+	}
+
+	for _, path := range paths {
+		if !validPath(path) {
+			return nil, fmt.Errorf("invalid field mask path %q", path)
+		}
+	}
+
+	if len(paths) > 0 {
+		query.Set(sparsefieldsets.SEARCH_PARAM, strings.Join(paths, ","))
+	}
+
+	return query, nil
+}
+
+// validPath indica se o caminho não é vazio e não tem segmentos vazios ou
+// caracteres reservados da querystring.
+func validPath(path string) bool {
+	if path == "" || strings.ContainsAny(path, ", *") {
+		return false
+	}
+
+	for _, segment := range strings.Split(path, sparsefieldsets.PATH_SEPARATOR) {
+		if segment == "" {
+			return false
+		}
+	}
+
+	return true
+}
+
+// OrderBy -------------------------------
+
+// OrderBy devolve a ordenação do Query no formato do "order_by", inversa
+// de ParseOrderBy:
+//
+//	// sort=-created_at,title
+//	OrderBy(q) // "created_at desc, title"
+func OrderBy(q *gosparse.Query) string {
+	fields := make([]string, 0, len(q.Sort))
+
+	for _, key := range q.Sort {
+		if key.Sorting == sort.DESC {
+			fields = append(fields, key.Field+" "+DESC)
+			continue
+		}
+
+		fields = append(fields, key.Field)
+	}
+
+	return strings.Join(fields, ", ")
+}
+
+// ParseOrderBy devolve o parâmetro "sort" com a ordenação do "order_by",
+// uma lista de campos separados por vírgula com a direção opcional
+// (padrão "asc"):
+//
+//	ParseOrderBy("created_at desc, title")
+//	// url.Values{"sort": {"-created_at,title"}}
+//
+// Espaços em excesso são ignorados.
+func ParseOrderBy(orderBy string) (url.Values, error) {
+	if strings.TrimSpace(orderBy) == "" {
+		return url.Values{}, nil
+	}
+
+	keys := make([]sort.Key, 0, strings.Count(orderBy, ",")+1)
+
+	for _, part := range strings.Split(orderBy, ",") {
+		tokens := strings.Fields(part)
+		if len(tokens) == 0 || len(tokens) > 2 || !validPath(tokens[0]) {
+			return nil, fmt.Errorf("invalid order_by %q", orderBy)
+		}
+
+		key := sort.Key{Field: tokens[0], Sorting: sort.ASC}
+
+		if len(tokens) == 2 {
+			switch strings.ToLower(tokens[1]) {
+			case DESC:
+				key.Sorting = sort.DESC
+			case ASC:
+			default:
+				return nil, fmt.Errorf("invalid order_by direction %q", tokens[1])
+			}
+		}
+
+		keys = append(keys, key)
+	}
+
+	return sort.Encode(keys), nil
+}
+
+// PageToken -----------------------------
+
+// NextPageToken devolve o page_token da página seguinte à página do Query.
+//
+// O token deve ser devolvido somente quando houver mais resultados, a
+// última página é indicada por um next_page_token vazio (AIP-158). Sem
+// paginação configurada (tamanho da página menor ou igual a zero) não há
+// página seguinte, portanto o token é vazio.
+//
+// O token não é assinado: a verificação (crc32) cobre somente os filtros
+// e a ordenação, não o deslocamento. Um cliente pode forjar um token para
+// qualquer deslocamento, portanto o deslocamento deve ser tratado como
+// qualquer outro valor enviado pelo cliente, sem conceder acesso além do
+// que os filtros já permitem.
+func NextPageToken(q *gosparse.Query) string {
+	size := q.Page.Get(pagination.SIZE)
+	if size <= 0 {
+		return ""
+	}
+
+	offset := (q.Page.Get(pagination.NUMBER) - 1) * size
+	if q.Page.IsSet(pagination.OFFSET) {
+		offset = q.Page.Get(pagination.OFFSET)
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	payload := url.Values{}
+	payload.Set(param(pagination.OFFSET), strconv.Itoa(offset+size))
+	payload.Set(check, fingerprint(q))
+
+	return base64.RawURLEncoding.EncodeToString([]byte(payload.Encode()))
+}
+
+// ParsePageToken devolve o parâmetro "page" com a posição da página do
+// page_token:
+//
+//	ParsePageToken(token) // url.Values{"page[offset]": {"20"}}
+//
+// Um token vazio indica a primeira página, portanto devolve uma query
+// vazia.
+func ParsePageToken(token string) (url.Values, error) {
+	page, _, err := decodeToken(token)
+	return page, err
+}
+
+// decodeToken devolve o parâmetro "page" e a verificação do page_token.
+func decodeToken(token string) (url.Values, string, error) {
+	if token == "" {
+		return url.Values{}, "", nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, "", ErrInvalidPageToken
+	}
+
+	payload, err := url.ParseQuery(string(raw))
+	if err != nil || len(payload) != 2 {
+		return nil, "", ErrInvalidPageToken
+	}
+
+	offset, err := strconv.Atoi(payload.Get(param(pagination.OFFSET)))
+	if err != nil || offset < 0 || payload.Get(check) == "" {
+		return nil, "", ErrInvalidPageToken
+	}
+
+	page := url.Values{}
+	page.Set(param(pagination.OFFSET), strconv.Itoa(offset))
+
+	return page, payload.Get(check), nil
+}
+
+// fingerprint devolve a verificação dos filtros e da ordenação do Query.
+func fingerprint(q *gosparse.Query) string {
+	query := filter.Encode(q.Filter)
+	if !q.Condition.Empty() {
+		query.Set(filter.SEARCH_PARAM, q.Condition.String())
+	}
+	for key, values := range sort.Encode(q.Sort) {
+		query[key] = values
+	}
+
+	return strconv.FormatUint(uint64(crc32.ChecksumIEEE([]byte(query.Encode()))), 36)
+}
+
+// param devolve o nome do parâmetro "page" do campo.
+//
+//	param(pagination.OFFSET) // page[offset]
+func param[T ~string](field T) string {
+	return pagination.PAGE_PARAM + "[" + string(field) + "]"
+}
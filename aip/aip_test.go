@@ -0,0 +1,241 @@
+package aip
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/stretchr/testify/require"
+)
+
+var gs = gosparse.New(
+	gosparse.TypeName("articles"),
+	gosparse.NestedFields(),
+	gosparse.FilterExpressions(),
+	gosparse.AcceptRelations("author"),
+	gosparse.AcceptFieldset("articles", "title", "body", "author"),
+	gosparse.AcceptFieldset("author", "name"),
+	gosparse.AcceptPaths("title", "body", "author", "author.name"),
+	gosparse.AcceptFilters("title"),
+	gosparse.AcceptSortBy("title", "created_at"),
+	gosparse.AcceptPagination(20),
+)
+
+func TestParseFieldMask(t *testing.T) {
+	testtable := []struct {
+		desc   string
+		paths  []string
+		expect url.Values
+		err    string
+	}{
+		{
+			desc:   "should select the paths",
+			paths:  []string{"title", "author.name"},
+			expect: url.Values{"fields": {"title,author.name"}},
+		},
+		{
+			desc:   "should select every field with an empty mask",
+			paths:  nil,
+			expect: url.Values{},
+		},
+		{
+			desc:   "should select every field with the wildcard",
+			paths:  []string{"*"},
+			expect: url.Values{},
+		},
+		{
+			desc:  "should reject the wildcard with other paths",
+			paths: []string{"*", "title"},
+			err:   `invalid field mask path "*"`,
+		},
+		{
+			desc:  "should reject empty segments",
+			paths: []string{"author..name"},
+			err:   `invalid field mask path "author..name"`,
+		},
+		{
+			desc:  "should reject commas",
+			paths: []string{"title,body"},
+			err:   `invalid field mask path "title,body"`,
+		},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := ParseFieldMask(tc.paths)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.expect, got)
+		})
+	}
+}
+
+func TestParseOrderBy(t *testing.T) {
+	testtable := []struct {
+		desc    string
+		orderBy string
+		expect  url.Values
+		err     string
+	}{
+		{
+			desc:    "should convert the order",
+			orderBy: "created_at desc, title",
+			expect:  url.Values{"sort": {"-created_at,title"}},
+		},
+		{
+			desc:    "should ignore redundant spaces",
+			orderBy: "  title   ASC ,created_at  DESC ",
+			expect:  url.Values{"sort": {"title,-created_at"}},
+		},
+		{
+			desc:    "should accept nested fields",
+			orderBy: "author.name",
+			expect:  url.Values{"sort": {"author.name"}},
+		},
+		{
+			desc:    "should return an empty query without order",
+			orderBy: " ",
+			expect:  url.Values{},
+		},
+		{
+			desc:    "should reject unknown directions",
+			orderBy: "title descending",
+			err:     `invalid order_by direction "descending"`,
+		},
+		{
+			desc:    "should reject empty fields",
+			orderBy: "title,,created_at",
+			err:     `invalid order_by "title,,created_at"`,
+		},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := ParseOrderBy(tc.orderBy)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.expect, got)
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	request := ListRequest{
+		ReadMask: []string{"title", "author.name"},
+		Filter:   `title = "Go*"`,
+		OrderBy:  "created_at desc, title",
+		PageSize: 5,
+	}
+
+	q, err := Parse(context.Background(), gs, request)
+	require.Nil(t, err)
+
+	require.Equal(t, []string{"author.name", "title"}, FieldMask(q))
+	require.Equal(t, "created_at desc, title", OrderBy(q))
+	require.Equal(t, "author", q.Include[0].Path)
+	require.Equal(t, []string{"Go"}, q.Filter["title"].Values)
+
+	// a primeira página começa no offset 0, a seguinte no 5
+	request.PageToken = NextPageToken(q)
+
+	page, err := ParsePageToken(request.PageToken)
+	require.Nil(t, err)
+	require.Equal(t, url.Values{"page[offset]": {"5"}}, page)
+
+	next, err := Parse(context.Background(), gs, request)
+	require.Nil(t, err)
+
+	request.PageToken = NextPageToken(next)
+
+	page, err = ParsePageToken(request.PageToken)
+	require.Nil(t, err)
+	require.Equal(t, url.Values{"page[offset]": {"10"}}, page)
+}
+
+func TestFieldMaskDefault(t *testing.T) {
+	q, err := Parse(context.Background(), gs, ListRequest{})
+	require.Nil(t, err)
+
+	require.Nil(t, FieldMask(q))
+	require.Equal(t, "", OrderBy(q))
+}
+
+func TestNextPageTokenFromNumber(t *testing.T) {
+	q, err := gs.Parse(url.Values{"page[number]": {"3"}})
+	require.Nil(t, err)
+
+	page, err := ParsePageToken(NextPageToken(q))
+	require.Nil(t, err)
+	require.Equal(t, url.Values{"page[offset]": {"60"}}, page)
+}
+
+func TestNextPageTokenWithoutPagination(t *testing.T) {
+	var unpaged gosparse.Gosparse
+
+	q, err := unpaged.Parse(url.Values{})
+	require.Nil(t, err)
+	require.Equal(t, "", NextPageToken(q))
+}
+
+func TestParseInvalid(t *testing.T) {
+	q, err := Parse(context.Background(), gs, ListRequest{OrderBy: "title"})
+	require.Nil(t, err)
+
+	token := NextPageToken(q)
+
+	testtable := []struct {
+		desc    string
+		request ListRequest
+		err     string
+	}{
+		{
+			desc:    "should reject tokens of another order",
+			request: ListRequest{OrderBy: "title desc", PageToken: token},
+			err:     ErrInvalidPageToken.Error(),
+		},
+		{
+			desc:    "should reject tokens of another filter",
+			request: ListRequest{OrderBy: "title", Filter: "title = Go", PageToken: token},
+			err:     ErrInvalidPageToken.Error(),
+		},
+		{
+			desc:    "should reject tokens of another condition tree",
+			request: ListRequest{OrderBy: "title", Filter: `title = "Go" OR NOT title = "R*"`, PageToken: token},
+			err:     ErrInvalidPageToken.Error(),
+		},
+		{
+			desc:    "should reject malformed tokens",
+			request: ListRequest{PageToken: "not a token"},
+			err:     ErrInvalidPageToken.Error(),
+		},
+		{
+			desc:    "should reject negative page sizes",
+			request: ListRequest{PageSize: -1},
+			err:     "page_size should not be negative",
+		},
+		{
+			desc:    "should reject fields out of the schema",
+			request: ListRequest{OrderBy: "body"},
+			err:     "unsupported sorting by: body",
+		},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := Parse(context.Background(), gs, tc.request)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+
+	_, err = Parse(context.Background(), gs, ListRequest{OrderBy: "title", PageToken: token})
+	require.Nil(t, err)
+}
@@ -0,0 +1,39 @@
+// Package aip
+//
+// Serviços gRPC recebem os parâmetros de listagem nos campos da mensagem,
+// seguindo as AIPs do Google, e não na querystring: a seleção em um
+// google.protobuf.FieldMask, a ordenação em "order_by" e a paginação em
+// "page_size" / "page_token" e os filtros em "filter".
+//
+// O pacote converte esses campos para os parâmetros do gosparse e de volta,
+// sem depender do protobuf, portanto recebe somente os caminhos do
+// FieldMask ([]string):
+//
+//	q, err := aip.Parse(ctx, gs, aip.ListRequest{
+//		ReadMask:  req.GetReadMask().GetPaths(),
+//		Filter:    req.GetFilter(),
+//		OrderBy:   req.GetOrderBy(),
+//		PageSize:  req.GetPageSize(),
+//		PageToken: req.GetPageToken(),
+//	})
+//
+//	// ...
+//	res.NextPageToken = aip.NextPageToken(q)
+//
+// Os caminhos do FieldMask são relativos aos dados primários, portanto os
+// caminhos de relacionamento ("author.name") dependem da seleção aninhada
+// (gosparse.NestedFields), e o filtro é uma expressão AIP-160, que depende
+// de gosparse.FilterExpressions.
+//
+// O page_token é opaco para o cliente e guarda a posição (offset) da
+// próxima página e uma verificação dos filtros e da ordenação da request,
+// de modo que um token não pode ser reutilizado com outra ordenação.
+//
+// # References
+//
+//   - https://google.aip.dev/132
+//   - https://google.aip.dev/157
+//   - https://google.aip.dev/158
+//   - https://google.aip.dev/160
+//   - https://google.aip.dev/161
+package aip
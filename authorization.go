@@ -0,0 +1,35 @@
+package gosparse
+
+import "github.com/jeanmolossi/gosparse/internal/authorization"
+
+// Authorizer é consultado para cada campo selecionado, filtrado, ordenado
+// ou incluído pelo cliente (veja Authorize).
+type Authorizer = authorization.Authorizer
+
+// AuthorizerFunc permite utilizar uma função comum como Authorizer
+type AuthorizerFunc = authorization.AuthorizerFunc
+
+// Operation é a operação autorizada em um campo
+type Operation = authorization.Operation
+
+// Policy é a política aplicada quando o acesso a um campo é negado
+type Policy = authorization.Policy
+
+// ForbiddenError é o erro devolvido quando o acesso a um campo é negado
+// com a política REJECT. Deve ser respondido como 403 Forbidden.
+type ForbiddenError = authorization.ForbiddenError
+
+const (
+	SELECT  = authorization.SELECT
+	FILTER  = authorization.FILTER
+	SORT    = authorization.SORT
+	INCLUDE = authorization.INCLUDE
+
+	REJECT = authorization.REJECT
+	STRIP  = authorization.STRIP
+)
+
+// ErrForbidden é o erro base de todo ForbiddenError
+//
+//	errors.Is(err, gosparse.ErrForbidden)
+var ErrForbidden = authorization.ErrForbidden
@@ -0,0 +1,23 @@
+// Package elastic
+//
+// Converte o resultado da querystring (gosparse.Query) no corpo de uma
+// busca do Elasticsearch / OpenSearch (Query DSL):
+//
+//	GET /articles?fields[articles]=title&filter[price_gte]=10&sort=-created_at
+//
+//	{
+//	  "_source": {"includes": ["title"]},
+//	  "query": {"bool": {"filter": [{"range": {"price": {"gte": 10}}}]}},
+//	  "size": 10,
+//	  "sort": [{"created_at": {"order": "desc"}}]
+//	}
+//
+// Os filtros são montados no contexto de filtro (sem pontuação) da query
+// "bool", e os predicados negativos (NEQ, NIN, NULL) em "must_not".
+//
+// # References
+//
+//   - https://www.elastic.co/guide/en/elasticsearch/reference/current/query-dsl-bool-query.html
+//   - https://www.elastic.co/guide/en/elasticsearch/reference/current/paginate-search-results.html
+//   - https://opensearch.org/docs/latest/query-dsl/
+package elastic
@@ -0,0 +1,369 @@
+package elastic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/backend"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
+)
+
+// Source é a seleção de campos retornados em cada documento
+type Source struct {
+	Includes []string `json:"includes"`
+}
+
+// Search é o corpo de uma busca.
+type Search struct {
+	// Query é a query "bool" com os filtros, ou "match_all" quando não
+	// há filtros
+	Query map[string]any `json:"query"`
+	// Sort são os campos ordenados, na ordem solicitada
+	Sort []map[string]any `json:"sort,omitempty"`
+	// Source são os atributos selecionados dos dados primários, nil
+	// quando todos os campos devem ser retornados
+	Source *Source `json:"_source,omitempty"`
+	// From é a quantidade de documentos ignorados, omitido com SearchAfter
+	From int64 `json:"from,omitempty"`
+	// Size é a quantidade máxima de documentos retornados, omitido sem
+	// paginação configurada para manter o padrão do Elasticsearch
+	Size int64 `json:"size,omitempty"`
+	// SearchAfter são os valores de ordenação do último documento da
+	// página anterior
+	SearchAfter []any `json:"search_after,omitempty"`
+}
+
+// JSON devolve o corpo da busca em JSON.
+func (s *Search) JSON() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+// Builder monta o corpo da busca a partir do gosparse.Query.
+//
+// O valor zero de Builder mantém os valores dos filtros como texto, para
+// converter os valores para o tipo de cada campo utilize a opção Schema.
+type Builder struct {
+	// Schema é utilizado para converter os valores dos filtros para o tipo
+	// de cada campo da estrutura e para mapear os campos para o caminho no
+	// documento (veja gosparse.Storage)
+	Schema *gosparse.Schema
+}
+
+// BuilderOpt é uma assinatura para opções de configuração
+// para o construtor de Builder
+type BuilderOpt func(*Builder)
+
+// Schema é uma opção do construtor de *Builder. Essa função recebe o Schema
+// compilado da estrutura, utilizado para converter os valores dos filtros.
+func Schema(schema *gosparse.Schema) BuilderOpt {
+	return func(b *Builder) {
+		b.Schema = schema
+	}
+}
+
+// Build recebe o Query e monta o corpo da busca paginado com from / size.
+func (b Builder) Build(query *gosparse.Query) (*Search, error) {
+	filters, err := b.Condition(query.Where())
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := b.order(query.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	from, size := Paginate(query.Page)
+
+	search := &Search{
+		Query: filters,
+		Sort:  order,
+		From:  from,
+		Size:  size,
+	}
+
+	if attrs := b.source(query.Select(sparsefieldsets.PRIMARY)); len(attrs) > 0 {
+		search.Source = &Source{Includes: attrs}
+	}
+
+	return search, nil
+}
+
+// BuildAfter funciona como Build, mas pagina com search_after a partir dos
+// valores de ordenação do último documento da página anterior. O
+// deslocamento (from) não é utilizado.
+//
+//	search, err := builder.BuildAfter(query, "2023-01-01T00:00:00Z", "42")
+func (b Builder) BuildAfter(query *gosparse.Query, after ...any) (*Search, error) {
+	search, err := b.Build(query)
+	if err != nil {
+		return nil, err
+	}
+
+	search.From = 0
+	search.SearchAfter = after
+	return search, nil
+}
+
+// Filter recebe os filtros e monta a query "bool":
+//
+//	filter[price_gte]=10    // {"range": {"price": {"gte": 10}}}
+//	filter[status_in]=a,b   // {"terms": {"status": ["a", "b"]}}
+//	filter[title_start]=Go  // {"prefix": {"title": "Go"}}
+//	filter[deleted_at_null] // must_not {"exists": {"field": "deleted_at"}}
+//
+// Os campos são montados em ordem alfabética, portanto o resultado é
+// determinístico. Sem filtros será devolvida a query "match_all".
+func (b Builder) Filter(filters filter.Filters) (map[string]any, error) {
+	return b.Condition(filter.All(filters))
+}
+
+// Condition recebe a árvore de condições e monta a query "bool". As folhas
+// da conjunção são montadas como em Filter e os demais nós com as cláusulas
+// da query "bool":
+//
+//	AND // {"bool": {"filter": [...]}}
+//	OR  // {"bool": {"should": [...], "minimum_should_match": 1}}
+//	NOT // {"bool": {"must_not": [...]}}
+func (b Builder) Condition(c filter.Condition) (map[string]any, error) {
+	switch c.Operator {
+	case filter.LEAF:
+		return b.Condition(filter.And(c))
+	case filter.NOT:
+		negated, err := b.Condition(c.Conditions[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"bool": map[string]any{"must_not": []any{negated}}}, nil
+	case filter.OR:
+		should := make([]any, 0, len(c.Conditions))
+		for _, nested := range c.Conditions {
+			query, err := b.Condition(nested)
+			if err != nil {
+				return nil, err
+			}
+
+			should = append(should, query)
+		}
+
+		return map[string]any{"bool": map[string]any{"should": should, "minimum_should_match": 1}}, nil
+	}
+
+	if c.Empty() {
+		return map[string]any{"match_all": map[string]any{}}, nil
+	}
+
+	must := make([]any, 0, len(c.Conditions))
+	mustNot := make([]any, 0)
+
+	for _, nested := range c.Conditions {
+		if nested.Operator != filter.LEAF {
+			query, err := b.Condition(nested)
+			if err != nil {
+				return nil, err
+			}
+
+			must = append(must, query)
+			continue
+		}
+
+		values, err := backend.Values(b.Schema, nested.Field, nested.Filter)
+		if err != nil {
+			return nil, err
+		}
+
+		path, err := backend.Path(b.Schema, nested.Field)
+		if err != nil {
+			return nil, err
+		}
+
+		clause, negate, err := clause(path, nested.Filter, values)
+		if err != nil {
+			return nil, err
+		}
+
+		if negate {
+			mustNot = append(mustNot, clause)
+			continue
+		}
+
+		must = append(must, clause)
+	}
+
+	conditions := map[string]any{}
+	if len(must) > 0 {
+		conditions["filter"] = must
+	}
+
+	if len(mustNot) > 0 {
+		conditions["must_not"] = mustNot
+	}
+
+	return map[string]any{"bool": conditions}, nil
+}
+
+// ranges relaciona os predicados de comparação com os operadores da
+// query "range"
+var ranges = map[filter.Predicate]string{
+	filter.GT:  "gt",
+	filter.GTE: "gte",
+	filter.LT:  "lt",
+	filter.LTE: "lte",
+}
+
+// clause monta a query do campo a partir do predicado e dos valores e
+// indica se ela deve ser negada (must_not).
+func clause(field string, f filter.Field, values []any) (map[string]any, bool, error) {
+	predicate := f.Predicate
+
+	switch predicate {
+	case filter.NONE, filter.EQ, filter.IN:
+		return terms(field, values), false, nil
+	case filter.NEQ, filter.NIN:
+		return terms(field, values), true, nil
+	case filter.START:
+		value, err := backend.Single(field, predicate, values)
+		if err != nil {
+			return nil, false, err
+		}
+
+		return map[string]any{"prefix": map[string]any{field: value}}, false, nil
+	case filter.END:
+		value, err := backend.Single(field, predicate, values)
+		if err != nil {
+			return nil, false, err
+		}
+
+		wildcard := map[string]any{"value": "*" + escapeWildcard(value.(string))}
+		return map[string]any{"wildcard": map[string]any{field: wildcard}}, false, nil
+	case filter.NULL, filter.NOT_NULL:
+		present, err := backend.Flag(field, f)
+		if err != nil {
+			return nil, false, err
+		}
+
+		exists := map[string]any{"exists": map[string]any{"field": field}}
+		return exists, present == (predicate == filter.NULL), nil
+	case filter.BLANK:
+		blank, err := backend.Flag(field, f)
+		if err != nil {
+			return nil, false, err
+		}
+
+		// um campo em branco não existe ou é um texto vazio
+		query := map[string]any{"bool": map[string]any{
+			"should": []any{
+				map[string]any{"bool": map[string]any{"must_not": []any{
+					map[string]any{"exists": map[string]any{"field": field}},
+				}}},
+				map[string]any{"term": map[string]any{field: ""}},
+			},
+			"minimum_should_match": 1,
+		}}
+
+		return query, !blank, nil
+	}
+
+	operator, known := ranges[predicate]
+	if !known {
+		return nil, false, fmt.Errorf("unsupported predicate %s on filter %s", predicate, field)
+	}
+
+	value, err := backend.Single(field, predicate, values)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return map[string]any{"range": map[string]any{field: map[string]any{operator: value}}}, false, nil
+}
+
+// terms monta a query "term" para um único valor ou "terms" para vários.
+func terms(field string, values []any) map[string]any {
+	if len(values) == 1 {
+		return map[string]any{"term": map[string]any{field: values[0]}}
+	}
+
+	return map[string]any{"terms": map[string]any{field: values}}
+}
+
+// escapeWildcard escapa os caracteres especiais da query "wildcard".
+func escapeWildcard(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`).Replace(value)
+}
+
+// Sort recebe os campos ordenados e monta o array "sort".
+//
+//	// sort=-created_at,title
+//	[{"created_at": {"order": "desc"}}, {"title": {"order": "asc"}}]
+func Sort(keys []sort.Key) []map[string]any {
+	fields := make([]map[string]any, 0, len(keys))
+
+	for _, key := range keys {
+		order := "asc"
+		if key.Sorting == sort.DESC {
+			order = "desc"
+		}
+
+		fields = append(fields, map[string]any{key.Field: map[string]any{"order": order}})
+	}
+
+	return fields
+}
+
+// order monta o array "sort" com o caminho de cada campo.
+func (b Builder) order(keys []sort.Key) ([]map[string]any, error) {
+	mapped := make([]sort.Key, 0, len(keys))
+
+	for _, key := range keys {
+		path, err := backend.Path(b.Schema, key.Field)
+		if err != nil {
+			return nil, err
+		}
+
+		key.Field = path
+		mapped = append(mapped, key)
+	}
+
+	return Sort(mapped), nil
+}
+
+// source devolve o caminho de cada atributo selecionado e das dependências
+// dos campos computados. Os campos virtuais não existem no documento,
+// portanto são ignorados.
+func (b Builder) source(attrs []string) []string {
+	if b.Schema != nil {
+		attrs = b.Schema.Requires(attrs)
+	}
+
+	paths := make([]string, 0, len(attrs))
+
+	for _, attr := range attrs {
+		if path, err := backend.Path(b.Schema, attr); err == nil {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// Paginate recebe o Page e devolve o deslocamento (from) e o tamanho da
+// página (size).
+//
+// Quando page[offset] é enviado ele é utilizado como from, caso contrário
+// from é calculado a partir de page[number]. Sem paginação configurada o
+// size é 0 e omitido do corpo da busca.
+func Paginate(page pagination.Page) (from, size int64) {
+	return backend.Paginate(page)
+}
+
+// Constructor -----------------
+
+func New(opt ...BuilderOpt) *Builder {
+	return backend.New(opt...)
+}
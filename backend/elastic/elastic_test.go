@@ -0,0 +1,219 @@
+package elastic_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/backend/elastic"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+type Article struct {
+	_         struct{}   `gosparse:"type:articles"`
+	Title     string     `gosparse:"name:title;select;sort;filter"`
+	Price     float64    `gosparse:"name:price;select;sort;filter"`
+	Stock     int        `gosparse:"name:stock;filter"`
+	Tags      []string   `gosparse:"name:tags;select;filter"`
+	CreatedAt time.Time  `gosparse:"name:created_at;select;sort;filter"`
+	DeletedAt *time.Time `gosparse:"name:deleted_at;filter"`
+}
+
+// golden compara o JSON com o arquivo testdata/<name>.golden, atualizado
+// com "go test ./backend/elastic -update".
+func golden(t *testing.T, name string, search *elastic.Search) {
+	t.Helper()
+
+	got, err := json.MarshalIndent(search, "", "  ")
+	require.Nil(t, err)
+	got = append(got, '\n')
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		require.Nil(t, os.WriteFile(path, got, 0o644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, string(want), string(got))
+}
+
+func TestBuild(t *testing.T) {
+	schema := gosparse.MustCompile[Article]()
+	builder := elastic.New(elastic.Schema(schema))
+
+	testtable := []struct {
+		golden string
+		query  url.Values
+	}{
+		{
+			golden: "match_all",
+			query:  url.Values{},
+		},
+		{
+			golden: "terms",
+			query:  url.Values{"filter[title]": {"gosparse"}, "filter[tags_in]": {"go,db"}, "filter[stock_nin]": {"0,1"}},
+		},
+		{
+			golden: "range",
+			query:  url.Values{"filter[price_gte]": {"9.9"}, "filter[created_at_lt]": {"2023-01-01"}},
+		},
+		{
+			golden: "text",
+			query:  url.Values{"filter[title_start]": {"Go"}, "filter[tags_end]": {"*.go?"}},
+		},
+		{
+			golden: "exists",
+			query:  url.Values{"filter[deleted_at_null]": {""}, "filter[created_at_notnull]": {"true"}, "filter[title_blank]": {"false"}},
+		},
+		{
+			golden: "full",
+			query: url.Values{
+				"fields":           {"title,price"},
+				"filter[price_gt]": {"10"},
+				"sort":             {"-created_at,title"},
+				"page[number]":     {"3"},
+				"page[size]":       {"20"},
+			},
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.golden, func(t *testing.T) {
+			query, err := schema.Gosparse().Parse(tt.query)
+			require.Nil(t, err)
+
+			search, err := builder.Build(query)
+			require.Nil(t, err)
+
+			golden(t, tt.golden, search)
+		})
+	}
+}
+
+func TestBuildCondition(t *testing.T) {
+	schema, err := gosparse.Compile(Article{}, gosparse.FilterExpressions())
+	require.Nil(t, err)
+
+	query, err := schema.Gosparse().Parse(url.Values{
+		"filter": {`price > 10 AND (stock = 1 OR NOT title = "Go*")`},
+	})
+	require.Nil(t, err)
+
+	search, err := elastic.New(elastic.Schema(schema)).Build(query)
+	require.Nil(t, err)
+
+	golden(t, "condition", search)
+}
+
+func TestBuildAfter(t *testing.T) {
+	schema := gosparse.MustCompile[Article]()
+
+	query, err := schema.Gosparse().Parse(url.Values{"sort": {"-created_at"}, "page[number]": {"4"}})
+	require.Nil(t, err)
+
+	search, err := elastic.New(elastic.Schema(schema)).BuildAfter(query, "2023-01-01T00:00:00Z")
+	require.Nil(t, err)
+
+	golden(t, "search_after", search)
+}
+
+func TestBuildWithStorage(t *testing.T) {
+	schema, err := gosparse.Compile(Article{},
+		gosparse.MapColumn("title", "title.keyword"),
+		gosparse.VirtualField("price", "price * 0.9"),
+	)
+	require.Nil(t, err)
+
+	builder := elastic.New(elastic.Schema(schema))
+
+	query, err := schema.Gosparse().Parse(url.Values{
+		"fields":        {"title,price"},
+		"filter[title]": {"gosparse"},
+		"sort":          {"-title"},
+	})
+	require.Nil(t, err)
+
+	search, err := builder.Build(query)
+	require.Nil(t, err)
+	require.Equal(t, map[string]any{"bool": map[string]any{"filter": []any{
+		map[string]any{"term": map[string]any{"title.keyword": "gosparse"}},
+	}}}, search.Query)
+	require.Equal(t, []map[string]any{{"title.keyword": map[string]any{"order": "desc"}}}, search.Sort)
+	require.Equal(t, &elastic.Source{Includes: []string{"title.keyword"}}, search.Source)
+
+	query, err = schema.Gosparse().Parse(url.Values{"sort": {"price"}})
+	require.Nil(t, err)
+
+	_, err = builder.Build(query)
+	require.EqualError(t, err, "unsupported field price: virtual fields are not stored")
+}
+
+func TestFilterErrors(t *testing.T) {
+	builder := elastic.New(elastic.Schema(gosparse.MustCompile[Article]()))
+
+	testtable := []struct {
+		desc  string
+		query url.Values
+		err   error
+	}{
+		{
+			desc:  "should fail invalid value",
+			query: url.Values{"filter[stock_gt]": {"many"}},
+			err:   fmt.Errorf("invalid filter stock: value many is not a valid int"),
+		},
+		{
+			desc:  "should fail range with many values",
+			query: url.Values{"filter[price_lte]": {"1,2"}},
+			err:   fmt.Errorf("filter price with predicate lte requires a single value"),
+		},
+		{
+			desc:  "should fail presence without boolean",
+			query: url.Values{"filter[deleted_at_notnull]": {"yes"}},
+			err:   fmt.Errorf("filter deleted_at with predicate notnull requires a boolean value"),
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			filters, err := filter.Decode(tt.query)
+			require.Nil(t, err)
+
+			_, err = builder.Filter(filters)
+			require.EqualError(t, err, tt.err.Error())
+		})
+	}
+}
+
+func TestBuildWithoutPagination(t *testing.T) {
+	gs := gosparse.MustCompile[Article]().Gosparse()
+	gs.Pagination = nil
+
+	query, err := gs.Parse(url.Values{"fields": {"title"}})
+	require.Nil(t, err)
+
+	search, err := elastic.New(elastic.Schema(gosparse.MustCompile[Article]())).Build(query)
+	require.Nil(t, err)
+
+	body, err := search.JSON()
+	require.Nil(t, err)
+	require.Equal(t, `{"query":{"match_all":{}},"_source":{"includes":["title"]}}`, string(body))
+}
+
+func TestJSON(t *testing.T) {
+	search := &elastic.Search{Query: map[string]any{"match_all": map[string]any{}}, Size: 10}
+
+	body, err := search.JSON()
+	require.Nil(t, err)
+	require.True(t, bytes.Equal([]byte(`{"query":{"match_all":{}},"size":10}`), body))
+}
@@ -0,0 +1,24 @@
+// Package memory
+//
+// Aplica o resultado da querystring (gosparse.Query) sobre uma lista de
+// estruturas já carregada em memória, útil em testes, caches e fontes de
+// dados pequenas:
+//
+//	GET /articles?filter[price_gte]=10&sort=-created_at&page[number]=2
+//
+//	result, err := memory.Apply(gosparse.MustCompile[Article](), articles, query)
+//	// result.Items []Article
+//	// result.Total int64
+//
+// Os valores de cada campo são lidos com gosparse.Schema.Value, portanto os
+// atributos de relacionamento (author.name) e os campos computados
+// (gosparse.ComputedField) também podem ser filtrados e ordenados. O
+// mapeamento de colunas não se aplica aos valores em memória.
+//
+// Os filtros de campos em listas (slices / arrays) atendem ao predicado
+// quando algum dos itens atende, como no MongoDB:
+//
+//	// Tags: []string{"go", "api"}
+//	filter[tags]=go       // true
+//	filter[tags_nin]=go   // false
+package memory
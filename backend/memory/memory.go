@@ -0,0 +1,455 @@
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	stdsort "sort"
+	"strings"
+	"time"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/backend"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Result é o resultado da aplicação do Query.
+type Result[T any] struct {
+	// Items são os itens da página solicitada
+	Items []T
+	// Total é a quantidade total de itens que atendem aos filtros
+	Total int64
+}
+
+// Apply recebe o Schema, os itens e o Query e devolve a página solicitada
+// dos itens que atendem aos filtros, na ordem solicitada.
+//
+// T deve ser a estrutura do Schema. A lista recebida não é alterada e os
+// itens são devolvidos completos, ou seja, a seleção de campos (fields) não
+// é aplicada. Sem paginação configurada todos os itens são devolvidos.
+func Apply[T any](schema *gosparse.Schema, items []T, query *gosparse.Query) (*Result[T], error) {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t != schema.Type {
+		return nil, fmt.Errorf("can not apply query of %s to %s", schema.Type, t)
+	}
+
+	filtered, err := Condition(schema, items, query.Where())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := Sort(schema, filtered, query.Sort); err != nil {
+		return nil, err
+	}
+
+	result := &Result[T]{Items: filtered, Total: int64(len(filtered))}
+
+	offset, size := backend.Paginate(query.Page)
+	if offset > result.Total {
+		offset = result.Total
+	}
+
+	result.Items = result.Items[offset:]
+	if backend.Paginated(query.Page) && size < int64(len(result.Items)) {
+		result.Items = result.Items[:size]
+	}
+
+	return result, nil
+}
+
+// Filter devolve uma nova lista com os itens que atendem a todos os
+// filtros.
+func Filter[T any](schema *gosparse.Schema, items []T, filters filter.Filters) ([]T, error) {
+	return Condition(schema, items, filter.All(filters))
+}
+
+// Condition devolve uma nova lista com os itens que atendem à árvore de
+// condições (veja gosparse.Query.Where).
+func Condition[T any](schema *gosparse.Schema, items []T, c filter.Condition) ([]T, error) {
+	// os valores das folhas são convertidos uma única vez
+	tree, err := compile(schema, c)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]T, 0, len(items))
+
+	for _, item := range items {
+		matched, err := tree.match(schema, item)
+		if err != nil {
+			return nil, err
+		}
+
+		if matched {
+			filtered = append(filtered, item)
+		}
+	}
+
+	return filtered, nil
+}
+
+// node é um nó da árvore de condições com os valores das folhas já
+// convertidos para o tipo de cada campo.
+type node struct {
+	condition filter.Condition
+	values    []any
+	nested    []node
+}
+
+// compile converte os valores das folhas da condição.
+func compile(schema *gosparse.Schema, c filter.Condition) (node, error) {
+	n := node{condition: c}
+
+	if c.Operator == filter.LEAF {
+		values, err := backend.Values(schema, c.Field, c.Filter)
+		if err != nil {
+			return n, err
+		}
+
+		n.values = values
+		return n, nil
+	}
+
+	for _, nested := range c.Conditions {
+		compiled, err := compile(schema, nested)
+		if err != nil {
+			return n, err
+		}
+
+		n.nested = append(n.nested, compiled)
+	}
+
+	return n, nil
+}
+
+// match indica se o item atende à condição do nó.
+func (n node) match(schema *gosparse.Schema, item any) (bool, error) {
+	switch n.condition.Operator {
+	case filter.LEAF:
+		value, err := schema.Value(item, n.condition.Field)
+		if err != nil {
+			return false, err
+		}
+
+		return match(n.condition.Field, n.condition.Filter, value, n.values)
+	case filter.NOT:
+		matched, err := n.nested[0].match(schema, item)
+		return !matched, err
+	}
+
+	// AND é atendido quando nenhum nó falha e OR quando algum nó é atendido
+	or := n.condition.Operator == filter.OR
+	for _, nested := range n.nested {
+		matched, err := nested.match(schema, item)
+		if err != nil {
+			return false, err
+		}
+
+		if matched == or {
+			return or, nil
+		}
+	}
+
+	return !or, nil
+}
+
+// Sort ordena os itens pelos campos, na ordem solicitada. A ordenação é
+// estável e os valores nulos são os menores.
+func Sort[T any](schema *gosparse.Schema, items []T, keys []sort.Key) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	// os valores são lidos uma única vez por item
+	values := make([][]any, len(items))
+	for i, item := range items {
+		values[i] = make([]any, 0, len(keys))
+
+		for _, key := range keys {
+			value, err := schema.Value(item, key.Field)
+			if err != nil {
+				return err
+			}
+
+			values[i] = append(values[i], value)
+		}
+	}
+
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+
+	var failed error
+	stdsort.SliceStable(order, func(i, j int) bool {
+		for k, key := range keys {
+			result, err := compare(values[order[i]][k], values[order[j]][k])
+			if err != nil {
+				if failed == nil {
+					failed = fmt.Errorf("unsupported sorting by %s: %w", key.Field, err)
+				}
+
+				return false
+			}
+
+			if result == 0 {
+				continue
+			}
+
+			if key.Sorting == sort.DESC {
+				return result > 0
+			}
+
+			return result < 0
+		}
+
+		return false
+	})
+
+	if failed != nil {
+		return failed
+	}
+
+	sorted := make([]T, len(items))
+	for i, index := range order {
+		sorted[i] = items[index]
+	}
+
+	copy(items, sorted)
+	return nil
+}
+
+// match indica se o valor do campo atende ao predicado do filtro. Os
+// valores do filtro já estão convertidos para o tipo do campo.
+func match(field string, f filter.Field, value any, values []any) (bool, error) {
+	switch f.Predicate {
+	case filter.NULL, filter.NOT_NULL:
+		flag, err := backend.Flag(field, f)
+		if err != nil {
+			return false, err
+		}
+
+		return null(value) == (flag == (f.Predicate == filter.NULL)), nil
+	case filter.BLANK:
+		flag, err := backend.Flag(field, f)
+		if err != nil {
+			return false, err
+		}
+
+		return blank(value) == flag, nil
+	case filter.START, filter.END:
+		raw, err := backend.Single(field, f.Predicate, f.Values)
+		if err != nil {
+			return false, err
+		}
+
+		return some(elements(value), func(element any) (bool, error) {
+			text, ok := str(element)
+			if !ok {
+				return false, fmt.Errorf("filter %s with predicate %s requires a text field", field, f.Predicate)
+			}
+
+			if f.Predicate == filter.START {
+				return strings.HasPrefix(text, raw), nil
+			}
+
+			return strings.HasSuffix(text, raw), nil
+		})
+	case filter.NONE, filter.EQ, filter.IN:
+		return some(elements(value), func(element any) (bool, error) {
+			return equals(element, values)
+		})
+	case filter.NEQ, filter.NIN:
+		found, err := some(elements(value), func(element any) (bool, error) {
+			return equals(element, values)
+		})
+
+		return !found, err
+	}
+
+	accept, known := comparisons[f.Predicate]
+	if !known {
+		return false, fmt.Errorf("unsupported predicate %s on filter %s", f.Predicate, field)
+	}
+
+	target, err := backend.Single(field, f.Predicate, values)
+	if err != nil {
+		return false, err
+	}
+
+	return some(elements(value), func(element any) (bool, error) {
+		// valores nulos não são comparáveis
+		if null(element) {
+			return false, nil
+		}
+
+		result, err := compare(element, target)
+		if err != nil {
+			return false, fmt.Errorf("invalid filter %s: %w", field, err)
+		}
+
+		return accept(result), nil
+	})
+}
+
+// comparisons relaciona os predicados de comparação com o resultado de
+// compare aceito
+var comparisons = map[filter.Predicate]func(result int) bool{
+	filter.GT:  func(result int) bool { return result > 0 },
+	filter.GTE: func(result int) bool { return result >= 0 },
+	filter.LT:  func(result int) bool { return result < 0 },
+	filter.LTE: func(result int) bool { return result <= 0 },
+}
+
+// some indica se algum dos elementos atende a accept.
+func some(elements []any, accept func(element any) (bool, error)) (bool, error) {
+	for _, element := range elements {
+		ok, err := accept(element)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+
+	return false, nil
+}
+
+// equals indica se o elemento é igual a algum dos valores.
+func equals(element any, values []any) (bool, error) {
+	return some(values, func(value any) (bool, error) {
+		if null(element) {
+			return false, nil
+		}
+
+		result, err := compare(element, value)
+		return result == 0, err
+	})
+}
+
+// elements devolve os itens de um valor em lista (slice / array), ou o
+// próprio valor.
+func elements(value any) []any {
+	v := deref(value)
+	if !v.IsValid() || (v.Kind() != reflect.Slice && v.Kind() != reflect.Array) {
+		return []any{value}
+	}
+
+	items := make([]any, 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		items = append(items, v.Index(i).Interface())
+	}
+
+	return items
+}
+
+// deref devolve o valor referenciado, inválido para referências nulas.
+func deref(value any) reflect.Value {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+
+		v = v.Elem()
+	}
+
+	return v
+}
+
+// null indica se o valor é nulo: uma referência, lista ou map nulo.
+func null(value any) bool {
+	v := deref(value)
+	if !v.IsValid() {
+		return true
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.IsNil()
+	}
+
+	return false
+}
+
+// blank indica se o valor é nulo ou um texto vazio.
+func blank(value any) bool {
+	text, ok := str(value)
+	return null(value) || (ok && text == "")
+}
+
+// str devolve o texto de um valor do tipo texto.
+func str(value any) (string, bool) {
+	v := deref(value)
+	if !v.IsValid() || v.Kind() != reflect.String {
+		return "", false
+	}
+
+	return v.String(), true
+}
+
+// compare compara dois valores do mesmo tipo básico e devolve -1, 0 ou 1.
+// Os valores nulos são menores que os demais.
+func compare(a, b any) (int, error) {
+	x, y := deref(a), deref(b)
+
+	switch {
+	case !x.IsValid() && !y.IsValid():
+		return 0, nil
+	case !x.IsValid():
+		return -1, nil
+	case !y.IsValid():
+		return 1, nil
+	}
+
+	if x.Type() == timeType && y.Type() == timeType {
+		return x.Interface().(time.Time).Compare(y.Interface().(time.Time)), nil
+	}
+
+	switch {
+	case integer(x.Kind()) && integer(y.Kind()):
+		return order(x.Int(), y.Int()), nil
+	case unsigned(x.Kind()) && unsigned(y.Kind()):
+		return order(x.Uint(), y.Uint()), nil
+	case float(x.Kind()) && float(y.Kind()):
+		return order(x.Float(), y.Float()), nil
+	case x.Kind() == reflect.String && y.Kind() == reflect.String:
+		return order(x.String(), y.String()), nil
+	case x.Kind() == reflect.Bool && y.Kind() == reflect.Bool:
+		return order(boolean(x.Bool()), boolean(y.Bool())), nil
+	}
+
+	return 0, fmt.Errorf("can not compare %s with %s", x.Type(), y.Type())
+}
+
+// order compara dois valores ordenáveis e devolve -1, 0 ou 1.
+func order[T int64 | uint64 | float64 | string](x, y T) int {
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+
+	return 0
+}
+
+// boolean converte o booleano para inteiro, false é menor que true.
+func boolean(b bool) int64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func integer(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func unsigned(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+func float(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
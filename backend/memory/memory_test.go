@@ -0,0 +1,132 @@
+package memory_test
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/backend/memory"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/stretchr/testify/require"
+)
+
+type Article struct {
+	_          struct{}   `gosparse:"type:articles"`
+	ID         int        `gosparse:"name:id;select;sort;filter"`
+	Title      string     `gosparse:"name:title;select;sort;filter"`
+	Price      float64    `gosparse:"name:price;select;sort;filter"`
+	Tags       []string   `gosparse:"name:tags;select;filter"`
+	DeletedAt  *time.Time `gosparse:"name:deleted_at;select;sort;filter"`
+	Discounted float64    `gosparse:"name:discounted;select;sort;filter"`
+	Author     *Author    `gosparse:"name:author;relation"`
+}
+
+type Author struct {
+	Name string `gosparse:"name:name;select;sort;filter"`
+}
+
+func articles() []Article {
+	deleted := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	return []Article{
+		{ID: 1, Title: "Go", Price: 10, Tags: []string{"go", "api"}, Author: &Author{Name: "Jean"}},
+		{ID: 2, Title: "Rust", Price: 30, Tags: []string{"rust"}, DeletedAt: &deleted},
+		{ID: 3, Title: "Gopher", Price: 20, Author: &Author{Name: "Ana"}},
+		{ID: 4, Title: "", Price: 20},
+	}
+}
+
+func TestApply(t *testing.T) {
+	schema, err := gosparse.Compile(Article{},
+		gosparse.AcceptPagination(2),
+		gosparse.FilterExpressions(),
+		gosparse.ComputedField("discounted", func(item any) (any, error) {
+			return item.(Article).Price * 0.5, nil
+		}, "price"),
+	)
+	require.Nil(t, err)
+
+	testtable := []struct {
+		desc  string
+		query url.Values
+		ids   []int
+		total int64
+		err   error
+	}{
+		{desc: "should paginate without filters", query: url.Values{}, ids: []int{1, 2}, total: 4},
+		{desc: "should skip pages", query: url.Values{"page[number]": {"2"}}, ids: []int{3, 4}, total: 4},
+		{desc: "should return empty page after the end", query: url.Values{"page[offset]": {"10"}}, ids: []int{}, total: 4},
+		{desc: "should filter equality", query: url.Values{"filter[id]": {"2"}}, ids: []int{2}, total: 1},
+		{desc: "should filter in", query: url.Values{"filter[id_in]": {"1,3"}}, ids: []int{1, 3}, total: 2},
+		{desc: "should filter not in", query: url.Values{"filter[id_nin]": {"1,3"}}, ids: []int{2, 4}, total: 2},
+		{desc: "should filter range", query: url.Values{"filter[price_gte]": {"20"}, "filter[id_lt]": {"4"}}, ids: []int{2, 3}, total: 2},
+		{desc: "should filter prefix", query: url.Values{"filter[title_start]": {"Go"}}, ids: []int{1, 3}, total: 2},
+		{desc: "should filter suffix", query: url.Values{"filter[title_end]": {"st"}}, ids: []int{2}, total: 1},
+		{desc: "should filter any list item", query: url.Values{"filter[tags]": {"api"}}, ids: []int{1}, total: 1},
+		{desc: "should filter null", query: url.Values{"filter[deleted_at_null]": {""}}, ids: []int{1, 3}, total: 3},
+		{desc: "should filter not null", query: url.Values{"filter[deleted_at_notnull]": {""}}, ids: []int{2}, total: 1},
+		{desc: "should filter blank", query: url.Values{"filter[title_blank]": {""}}, ids: []int{4}, total: 1},
+		{desc: "should filter relation attribute", query: url.Values{"filter[author.name]": {"Ana"}}, ids: []int{3}, total: 1},
+		{desc: "should filter computed field", query: url.Values{"filter[discounted_gt]": {"10"}}, ids: []int{2}, total: 1},
+		{desc: "should filter or and not", query: url.Values{"filter": {`price >= 20 AND (title = "Rust" OR NOT title = "Go*")`}}, ids: []int{2, 4}, total: 2},
+		{desc: "should sort desc with ties", query: url.Values{"sort": {"-price,id"}}, ids: []int{2, 3}, total: 4},
+		{desc: "should sort nulls first", query: url.Values{"sort": {"author.name,id"}}, ids: []int{2, 4}, total: 4},
+		{
+			desc:  "should fail invalid value",
+			query: url.Values{"filter[price_gt]": {"ten"}},
+			err:   fmt.Errorf("invalid filter price: value ten is not a valid float64"),
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			query, err := schema.Gosparse().Parse(tt.query)
+			require.Nil(t, err)
+
+			result, err := memory.Apply(schema, articles(), query)
+			if tt.err != nil {
+				require.EqualError(t, err, tt.err.Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tt.total, result.Total)
+
+			ids := make([]int, 0, len(result.Items))
+			for _, item := range result.Items {
+				ids = append(ids, item.ID)
+			}
+
+			require.Equal(t, tt.ids, ids)
+		})
+	}
+}
+
+func TestApplyKeepsItems(t *testing.T) {
+	schema := gosparse.MustCompile[Article]()
+	items := articles()
+
+	query, err := schema.Gosparse().Parse(url.Values{"sort": {"-id"}})
+	require.Nil(t, err)
+
+	result, err := memory.Apply(schema, items, query)
+	require.Nil(t, err)
+	require.Equal(t, 4, result.Items[0].ID)
+	require.Equal(t, 1, items[0].ID)
+
+	_, err = memory.Apply(schema, []Author{}, query)
+	require.EqualError(t, err, "can not apply query of memory_test.Article to memory_test.Author")
+}
+
+func TestFilterTextPredicate(t *testing.T) {
+	schema := gosparse.MustCompile[Article]()
+
+	// o Parse rejeita o predicado, mas os filtros podem ser montados
+	// diretamente
+	_, err := memory.Filter(schema, articles(), filter.Filters{
+		"price": {Predicate: gosparse.START, Values: []string{"1"}},
+	})
+	require.EqualError(t, err, "filter price with predicate start requires a text field")
+}
@@ -0,0 +1,34 @@
+// Package mongo
+//
+// Converte o resultado da querystring (gosparse.Query) nos documentos
+// utilizados por uma consulta "find" do MongoDB:
+//
+//	GET /articles?fields[articles]=title&filter[price_gte]=10&sort=-created_at&page[number]=2
+//
+//	db.articles.find({"price": {"$gte": 10}}, {"title": 1})
+//		.sort({"created_at": -1})
+//		.skip(10)
+//		.limit(10)
+//
+// Os documentos são montados com map[string]any e D (ordenado), compatíveis
+// com o driver oficial sem depender dele:
+//
+//	find, err := mongo.New(mongo.Schema(articles)).Build(query)
+//
+//	opts := options.Find().
+//		SetProjection(find.Projection).
+//		SetSkip(find.Skip).
+//		SetLimit(find.Limit)
+//
+//	sort := bson.D{}
+//	for _, e := range find.Sort {
+//		sort = append(sort, bson.E{Key: e.Key, Value: e.Value})
+//	}
+//
+//	cursor, err := collection.Find(ctx, find.Filter, opts.SetSort(sort))
+//
+// # References
+//
+//   - https://www.mongodb.com/docs/manual/reference/operator/query/
+//   - https://www.mongodb.com/docs/manual/reference/method/db.collection.find/
+package mongo
@@ -0,0 +1,357 @@
+package mongo
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/backend"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
+)
+
+// E é um elemento de um documento ordenado
+type E struct {
+	Key   string
+	Value any
+}
+
+// D é um documento ordenado, utilizado quando a ordem das chaves importa,
+// como no documento de ordenação.
+//
+//	D{{"created_at", -1}, {"title", 1}}
+type D []E
+
+// Find armazena os documentos de uma consulta "find".
+type Find struct {
+	// Filter é o documento de filtro, vazio quando não há filtros
+	Filter map[string]any
+	// Sort é o documento de ordenação, na ordem solicitada
+	Sort D
+	// Projection é o documento de projeção dos dados primários, nil quando
+	// todos os campos devem ser retornados
+	Projection map[string]any
+	// Skip é a quantidade de documentos ignorados
+	Skip int64
+	// Limit é a quantidade máxima de documentos retornados
+	Limit int64
+}
+
+// Builder monta os documentos do MongoDB a partir do gosparse.Query.
+//
+// O valor zero de Builder mantém os valores dos filtros como texto, para
+// converter os valores para o tipo de cada campo utilize a opção Schema.
+type Builder struct {
+	// Schema é utilizado para converter os valores dos filtros para o tipo
+	// de cada campo da estrutura e para mapear os campos para o caminho no
+	// documento (veja gosparse.Storage)
+	Schema *gosparse.Schema
+}
+
+// BuilderOpt é uma assinatura para opções de configuração
+// para o construtor de Builder
+type BuilderOpt func(*Builder)
+
+// Schema é uma opção do construtor de *Builder. Essa função recebe o Schema
+// compilado da estrutura, utilizado para converter os valores dos filtros.
+//
+//	mongo.New(mongo.Schema(gosparse.MustCompile[Article]()))
+func Schema(schema *gosparse.Schema) BuilderOpt {
+	return func(b *Builder) {
+		b.Schema = schema
+	}
+}
+
+// Build recebe o Query e monta todos os documentos da consulta.
+func (b Builder) Build(query *gosparse.Query) (*Find, error) {
+	filters, err := b.Condition(query.Where())
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := b.order(query.Sort)
+	if err != nil {
+		return nil, err
+	}
+
+	skip, limit := Paginate(query.Page)
+
+	return &Find{
+		Filter:     filters,
+		Sort:       order,
+		Projection: b.projection(query.Select(sparsefieldsets.PRIMARY)),
+		Skip:       skip,
+		Limit:      limit,
+	}, nil
+}
+
+// Filter recebe os filtros e monta o documento de filtro:
+//
+//	filter[price_gte]=10      // {"price": {"$gte": 10}}
+//	filter[status_in]=a,b     // {"status": {"$in": ["a", "b"]}}
+//	filter[title_start]=Go    // {"title": {"$regex": "^Go"}}
+//	filter[deleted_at_null]   // {"deleted_at": {"$exists": false}}
+//
+// Os filtros sem predicado são igualdades, ou "$in" quando recebem mais de
+// um valor.
+func (b Builder) Filter(filters filter.Filters) (map[string]any, error) {
+	document := make(map[string]any, len(filters))
+
+	for field, f := range filters {
+		values, err := backend.Values(b.Schema, field, f)
+		if err != nil {
+			return nil, err
+		}
+
+		condition, err := condition(field, f, values)
+		if err != nil {
+			return nil, err
+		}
+
+		path, err := backend.Path(b.Schema, field)
+		if err != nil {
+			return nil, err
+		}
+
+		document[path] = condition
+	}
+
+	return document, nil
+}
+
+// Condition recebe a árvore de condições e monta o documento de filtro. As
+// folhas da conjunção são montadas como em Filter e os demais nós com os
+// operadores lógicos:
+//
+//	// price > 10 AND (status = "open" OR NOT archived)
+//	{"$and": [
+//		{"price": {"$gt": 10}},
+//		{"$or": [{"status": {"$eq": "open"}}, {"archived": {"$eq": "false"}}]},
+//	]}
+//
+// A negação de um nó é montada com "$nor".
+func (b Builder) Condition(c filter.Condition) (map[string]any, error) {
+	switch c.Operator {
+	case filter.LEAF:
+		return b.Filter(filter.Filters{c.Field: c.Filter})
+	case filter.NOT:
+		negated, err := b.Condition(c.Conditions[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"$nor": []any{negated}}, nil
+	case filter.OR:
+		documents, err := b.documents(c.Conditions)
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"$or": documents}, nil
+	}
+
+	filters := make(filter.Filters)
+	nested := make([]filter.Condition, 0)
+
+	for _, item := range c.Conditions {
+		if _, duplicate := filters[item.Field]; item.Operator == filter.LEAF && !duplicate {
+			filters[item.Field] = item.Filter
+			continue
+		}
+
+		nested = append(nested, item)
+	}
+
+	document, err := b.Filter(filters)
+	if err != nil || len(nested) == 0 {
+		return document, err
+	}
+
+	documents, err := b.documents(nested)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(filters) > 0 {
+		documents = append([]any{document}, documents...)
+	}
+
+	return map[string]any{"$and": documents}, nil
+}
+
+// documents monta o documento de filtro de cada condição.
+func (b Builder) documents(conditions []filter.Condition) ([]any, error) {
+	documents := make([]any, 0, len(conditions))
+
+	for _, c := range conditions {
+		document, err := b.Condition(c)
+		if err != nil {
+			return nil, err
+		}
+
+		documents = append(documents, document)
+	}
+
+	return documents, nil
+}
+
+// operators relaciona os predicados de comparação com os operadores
+var operators = map[filter.Predicate]string{
+	filter.EQ:  "$eq",
+	filter.NEQ: "$ne",
+	filter.GT:  "$gt",
+	filter.GTE: "$gte",
+	filter.LT:  "$lt",
+	filter.LTE: "$lte",
+}
+
+// condition monta a condição do campo a partir do predicado e dos valores.
+func condition(field string, f filter.Field, values []any) (any, error) {
+	predicate := f.Predicate
+
+	switch predicate {
+	case filter.NONE:
+		if len(values) == 1 {
+			return values[0], nil
+		}
+
+		return map[string]any{"$in": values}, nil
+	case filter.IN:
+		return map[string]any{"$in": values}, nil
+	case filter.NIN:
+		return map[string]any{"$nin": values}, nil
+	case filter.START, filter.END:
+		value, err := backend.Single(field, predicate, values)
+		if err != nil {
+			return nil, err
+		}
+
+		pattern := regexp.QuoteMeta(value.(string))
+		if predicate == filter.START {
+			return map[string]any{"$regex": "^" + pattern}, nil
+		}
+
+		return map[string]any{"$regex": pattern + "$"}, nil
+	case filter.NULL, filter.NOT_NULL:
+		present, err := backend.Flag(field, f)
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]any{"$exists": present == (predicate == filter.NOT_NULL)}, nil
+	case filter.BLANK:
+		blank, err := backend.Flag(field, f)
+		if err != nil {
+			return nil, err
+		}
+
+		if blank {
+			return map[string]any{"$in": []any{nil, ""}}, nil
+		}
+
+		return map[string]any{"$nin": []any{nil, ""}}, nil
+	}
+
+	operator, known := operators[predicate]
+	if !known {
+		return nil, fmt.Errorf("unsupported predicate %s on filter %s", predicate, field)
+	}
+
+	value, err := backend.Single(field, predicate, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{operator: value}, nil
+}
+
+// Sort recebe os campos ordenados e monta o documento de ordenação.
+//
+//	// sort=-created_at,title
+//	D{{"created_at", -1}, {"title", 1}}
+func Sort(keys []sort.Key) D {
+	document := make(D, 0, len(keys))
+
+	for _, key := range keys {
+		direction := 1
+		if key.Sorting == sort.DESC {
+			direction = -1
+		}
+
+		document = append(document, E{Key: key.Field, Value: direction})
+	}
+
+	return document
+}
+
+// order monta o documento de ordenação com o caminho de cada campo.
+func (b Builder) order(keys []sort.Key) (D, error) {
+	mapped := make([]sort.Key, 0, len(keys))
+
+	for _, key := range keys {
+		path, err := backend.Path(b.Schema, key.Field)
+		if err != nil {
+			return nil, err
+		}
+
+		key.Field = path
+		mapped = append(mapped, key)
+	}
+
+	return Sort(mapped), nil
+}
+
+// projection monta o documento de projeção com o caminho de cada atributo
+// e das dependências dos campos computados. Os campos virtuais não existem
+// no documento, portanto são ignorados.
+func (b Builder) projection(attrs []string) map[string]any {
+	if b.Schema != nil {
+		attrs = b.Schema.Requires(attrs)
+	}
+
+	paths := make([]string, 0, len(attrs))
+
+	for _, attr := range attrs {
+		if path, err := backend.Path(b.Schema, attr); err == nil {
+			paths = append(paths, path)
+		}
+	}
+
+	return Projection(paths)
+}
+
+// Projection recebe os atributos selecionados e monta o documento de
+// projeção. Caso nenhum atributo seja selecionado será devolvido nil, ou
+// seja, todos os campos serão retornados.
+//
+//	// fields[articles]=title,author.name
+//	map[string]any{"title": 1, "author.name": 1}
+func Projection(attrs []string) map[string]any {
+	if len(attrs) == 0 {
+		return nil
+	}
+
+	document := make(map[string]any, len(attrs))
+	for _, attr := range attrs {
+		document[attr] = 1
+	}
+
+	return document
+}
+
+// Paginate recebe o Page e devolve a quantidade de documentos ignorados e
+// o limite de documentos.
+//
+// Quando page[offset] é enviado ele é utilizado como skip, caso contrário o
+// skip é calculado a partir de page[number].
+func Paginate(page pagination.Page) (skip, limit int64) {
+	return backend.Paginate(page)
+}
+
+// Constructor -----------------
+
+func New(opt ...BuilderOpt) *Builder {
+	return backend.New(opt...)
+}
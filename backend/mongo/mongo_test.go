@@ -0,0 +1,224 @@
+package mongo_test
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/backend/mongo"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/stretchr/testify/require"
+)
+
+type Article struct {
+	_         struct{}   `gosparse:"type:articles"`
+	Title     string     `gosparse:"name:title;select;sort;filter"`
+	Price     float64    `gosparse:"name:price;select;sort;filter"`
+	Stock     int        `gosparse:"name:stock;filter"`
+	Tags      []string   `gosparse:"name:tags;select;filter"`
+	CreatedAt time.Time  `gosparse:"name:created_at;select;sort;filter"`
+	DeletedAt *time.Time `gosparse:"name:deleted_at;filter"`
+}
+
+func TestFilter(t *testing.T) {
+	builder := mongo.New(mongo.Schema(gosparse.MustCompile[Article]()))
+
+	testtable := []struct {
+		desc   string
+		query  url.Values
+		expect map[string]any
+		err    error
+	}{
+		{
+			desc:   "should build equality without predicate",
+			query:  url.Values{"filter[title]": {"gosparse"}},
+			expect: map[string]any{"title": "gosparse"},
+		},
+		{
+			desc:   "should build $in without predicate and many values",
+			query:  url.Values{"filter[stock]": {"1,2"}},
+			expect: map[string]any{"stock": map[string]any{"$in": []any{1, 2}}},
+		},
+		{
+			desc:  "should build comparison operators with typed values",
+			query: url.Values{"filter[price_gte]": {"9.9"}, "filter[stock_neq]": {"0"}, "filter[created_at_lt]": {"2023-01-01"}},
+			expect: map[string]any{
+				"price":      map[string]any{"$gte": 9.9},
+				"stock":      map[string]any{"$ne": 0},
+				"created_at": map[string]any{"$lt": time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)},
+			},
+		},
+		{
+			desc:  "should build $in and $nin",
+			query: url.Values{"filter[tags_in]": {"go,db"}, "filter[stock_nin]": {"0"}},
+			expect: map[string]any{
+				"tags":  map[string]any{"$in": []any{"go", "db"}},
+				"stock": map[string]any{"$nin": []any{0}},
+			},
+		},
+		{
+			desc:  "should build escaped $regex",
+			query: url.Values{"filter[title_start]": {"C++ (intro"}, "filter[tags_end]": {".go"}},
+			expect: map[string]any{
+				"title": map[string]any{"$regex": `^C\+\+ \(intro`},
+				"tags":  map[string]any{"$regex": `\.go$`},
+			},
+		},
+		{
+			desc:  "should build $exists",
+			query: url.Values{"filter[deleted_at_null]": {""}, "filter[created_at_notnull]": {"false"}},
+			expect: map[string]any{
+				"deleted_at": map[string]any{"$exists": false},
+				"created_at": map[string]any{"$exists": false},
+			},
+		},
+		{
+			desc:   "should build blank",
+			query:  url.Values{"filter[title_blank]": {"true"}},
+			expect: map[string]any{"title": map[string]any{"$in": []any{nil, ""}}},
+		},
+		{
+			desc:  "should fail invalid value",
+			query: url.Values{"filter[stock_gt]": {"many"}},
+			err:   fmt.Errorf("invalid filter stock: value many is not a valid int"),
+		},
+		{
+			desc:  "should fail comparison with many values",
+			query: url.Values{"filter[price_gt]": {"1,2"}},
+			err:   fmt.Errorf("filter price with predicate gt requires a single value"),
+		},
+		{
+			desc:  "should fail presence without boolean",
+			query: url.Values{"filter[deleted_at_null]": {"maybe"}},
+			err:   fmt.Errorf("filter deleted_at with predicate null requires a boolean value"),
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			filters, err := filter.Decode(tt.query)
+			require.Nil(t, err)
+
+			document, err := builder.Filter(filters)
+			if tt.err != nil {
+				require.EqualError(t, err, tt.err.Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tt.expect, document)
+		})
+	}
+}
+
+func TestFilterWithoutSchema(t *testing.T) {
+	document, err := mongo.New().Filter(filter.Filters{"stock": {Predicate: filter.GT, Values: []string{"10"}}})
+	require.Nil(t, err)
+	require.Equal(t, map[string]any{"stock": map[string]any{"$gt": "10"}}, document)
+}
+
+func TestBuild(t *testing.T) {
+	schema := gosparse.MustCompile[Article]()
+
+	query, err := schema.Gosparse().Parse(url.Values{
+		"fields":            {"title,price"},
+		"filter[price_gte]": {"10"},
+		"sort":              {"-created_at,title"},
+		"page[number]":      {"3"},
+		"page[size]":        {"20"},
+	})
+	require.Nil(t, err)
+
+	find, err := mongo.New(mongo.Schema(schema)).Build(query)
+	require.Nil(t, err)
+
+	require.Equal(t, &mongo.Find{
+		Filter:     map[string]any{"price": map[string]any{"$gte": 10.0}},
+		Sort:       mongo.D{{Key: "created_at", Value: -1}, {Key: "title", Value: 1}},
+		Projection: map[string]any{"title": 1, "price": 1},
+		Skip:       40,
+		Limit:      20,
+	}, find)
+}
+
+func TestPaginate(t *testing.T) {
+	gs := gosparse.New(gosparse.AcceptPagination(10))
+
+	testtable := []struct {
+		desc  string
+		query url.Values
+		skip  int64
+		limit int64
+	}{
+		{desc: "should use defaults", query: url.Values{}, skip: 0, limit: 10},
+		{desc: "should skip pages", query: url.Values{"page[number]": {"2"}, "page[size]": {"15"}}, skip: 15, limit: 15},
+		{desc: "should prefer offset", query: url.Values{"page[number]": {"2"}, "page[offset]": {"5"}}, skip: 5, limit: 10},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			query, err := gs.Parse(tt.query)
+			require.Nil(t, err)
+
+			skip, limit := mongo.Paginate(query.Page)
+			require.Equal(t, tt.skip, skip)
+			require.Equal(t, tt.limit, limit)
+		})
+	}
+}
+
+func TestBuildWithStorage(t *testing.T) {
+	schema, err := gosparse.Compile(Article{},
+		gosparse.MapColumn("created_at", "meta.created"),
+		gosparse.ComputedField("title", func(item any) (any, error) { return "", nil }),
+	)
+	require.Nil(t, err)
+
+	builder := mongo.New(mongo.Schema(schema))
+
+	query, err := schema.Gosparse().Parse(url.Values{
+		"fields":                {"title,created_at"},
+		"filter[created_at_gt]": {"2023-01-01"},
+		"sort":                  {"created_at"},
+	})
+	require.Nil(t, err)
+
+	find, err := builder.Build(query)
+	require.Nil(t, err)
+	require.Equal(t, map[string]any{"meta.created": map[string]any{"$gt": time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}}, find.Filter)
+	require.Equal(t, mongo.D{{Key: "meta.created", Value: 1}}, find.Sort)
+	require.Equal(t, map[string]any{"meta.created": 1}, find.Projection)
+
+	query, err = schema.Gosparse().Parse(url.Values{"filter[title]": {"gosparse"}})
+	require.Nil(t, err)
+
+	_, err = builder.Build(query)
+	require.EqualError(t, err, "unsupported field title: virtual fields are not stored")
+}
+
+func TestCondition(t *testing.T) {
+	builder := mongo.New(mongo.Schema(gosparse.MustCompile[Article]()))
+
+	// price > 10 AND (stock = 1 OR NOT title = "Go*")
+	document, err := builder.Condition(filter.And(
+		filter.Leaf("price", filter.Field{Predicate: filter.GT, Values: []string{"10"}}),
+		filter.Condition{Operator: filter.OR, Conditions: []filter.Condition{
+			filter.Leaf("stock", filter.Field{Predicate: filter.EQ, Values: []string{"1"}}),
+			filter.Not(filter.Leaf("title", filter.Field{Predicate: filter.START, Values: []string{"Go"}})),
+		}},
+	))
+	require.Nil(t, err)
+
+	require.Equal(t, map[string]any{"$and": []any{
+		map[string]any{"price": map[string]any{"$gt": 10.0}},
+		map[string]any{"$or": []any{
+			map[string]any{"stock": map[string]any{"$eq": 1}},
+			map[string]any{"$nor": []any{map[string]any{"title": map[string]any{"$regex": "^Go"}}}},
+		}},
+	}}, document)
+
+	_, err = builder.Condition(filter.Not(filter.Leaf("stock", filter.Field{Predicate: filter.GT, Values: []string{"a"}})))
+	require.NotNil(t, err)
+}
@@ -0,0 +1,546 @@
+package sqlexec
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/backend"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
+)
+
+// Dialect define as diferenças de sintaxe entre os bancos de dados.
+type Dialect struct {
+	// Placeholder devolve o marcador do n-ésimo argumento (a partir de 1)
+	Placeholder func(n int) string
+	// Quote escapa um identificador (tabela ou coluna)
+	Quote func(identifier string) string
+}
+
+var (
+	// SQLite utiliza "?" e identificadores entre aspas duplas
+	SQLite = Dialect{Placeholder: question, Quote: doubleQuote}
+	// Postgres utiliza "$n" e identificadores entre aspas duplas
+	Postgres = Dialect{Placeholder: dollar, Quote: doubleQuote}
+	// MySQL utiliza "?" e identificadores entre crases
+	MySQL = Dialect{Placeholder: question, Quote: backtick}
+)
+
+func question(int) string {
+	return "?"
+}
+
+func dollar(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+func doubleQuote(identifier string) string {
+	return `"` + strings.ReplaceAll(identifier, `"`, `""`) + `"`
+}
+
+func backtick(identifier string) string {
+	return "`" + strings.ReplaceAll(identifier, "`", "``") + "`"
+}
+
+// Statement é uma consulta SQL com os respectivos argumentos.
+type Statement struct {
+	SQL  string
+	Args []any
+}
+
+// statement acumula os trechos e os argumentos de uma consulta.
+type statement struct {
+	dialect Dialect
+	sql     strings.Builder
+	args    []any
+	// joins são as relações juntadas à tabela consultada (veja plan)
+	joins []hop
+}
+
+// write adiciona os trechos à consulta.
+func (s *statement) write(parts ...string) {
+	for _, part := range parts {
+		s.sql.WriteString(part)
+	}
+}
+
+// arg adiciona o argumento e devolve o seu marcador.
+func (s *statement) arg(value any) string {
+	s.args = append(s.args, value)
+	return s.dialect.Placeholder(len(s.args))
+}
+
+// Statement devolve a consulta montada.
+func (s *statement) Statement() Statement {
+	return Statement{SQL: s.sql.String(), Args: s.args}
+}
+
+// Columns devolve as colunas selecionadas dos dados primários, ou seja, os
+// atributos selecionados que são campos da própria estrutura, na ordem
+// solicitada. Os campos computados (veja gosparse.ComputedField) não são
+// colunas, portanto não são devolvidos, mas as colunas das quais dependem
+// são acrescentadas ao final.
+//
+// Caso nenhum atributo seja selecionado, todos os campos selecionáveis da
+// estrutura são devolvidos em ordem alfabética.
+func (e *Executor) Columns(query *gosparse.Query) []string {
+	columns := make([]string, 0)
+	for _, name := range e.Schema.Requires(e.selected(query, e.selectable)) {
+		if e.column(name) {
+			columns = append(columns, name)
+		}
+	}
+
+	return columns
+}
+
+// Computed devolve os campos computados selecionados dos dados primários,
+// preenchidos após a consulta a partir das colunas selecionadas.
+func (e *Executor) Computed(query *gosparse.Query) []string {
+	return e.selected(query, e.computed)
+}
+
+// selected devolve os atributos selecionados que atendem a accept. Caso
+// nenhum atributo selecionado seja uma coluna, todos os campos
+// selecionáveis são considerados, em ordem alfabética.
+func (e *Executor) selected(query *gosparse.Query, accept func(name string) bool) []string {
+	requested := make([]string, 0)
+	columns := 0
+
+	for _, attr := range query.Select(sparsefieldsets.PRIMARY) {
+		if e.column(attr) {
+			columns++
+		}
+
+		if e.selectable(attr) {
+			requested = append(requested, attr)
+		}
+	}
+
+	if columns == 0 {
+		requested = requested[:0]
+
+		for _, name := range e.Schema.FieldNames() {
+			if e.field(name).Select && e.selectable(name) {
+				requested = append(requested, name)
+			}
+		}
+	}
+
+	selected := make([]string, 0, len(requested))
+	for _, name := range requested {
+		if accept(name) {
+			selected = append(selected, name)
+		}
+	}
+
+	return selected
+}
+
+// selectable indica se o campo pode ser consultado na listagem, ou seja,
+// se é uma coluna ou um campo computado.
+func (e *Executor) selectable(name string) bool {
+	return e.column(name) || e.computed(name)
+}
+
+// field devolve a configuração do campo no Schema, vazia para campos
+// desconhecidos.
+func (e *Executor) field(name string) gosparse.Field {
+	field, _ := e.Schema.Field(name)
+	return field
+}
+
+// column indica se o campo é uma coluna da tabela, ou seja, um campo da
+// própria estrutura que não é uma relação nem um campo computado.
+//
+// Os atributos de relacionamento são colunas somente quando mapeados para
+// uma coluna qualificada ou expressão (veja gosparse.MapColumn) e não
+// percorrem relações para muitos. Para filtrar e ordenar pelas relações
+// utilize as junções (veja gosparse.JoinRelation).
+func (e *Executor) column(name string) bool {
+	field, exists := e.Schema.Field(name)
+	if !exists || field.Relation {
+		return false
+	}
+
+	storage, _ := e.Schema.Storage(name)
+	if storage.Computed() {
+		return false
+	}
+
+	if !strings.Contains(name, sparsefieldsets.PATH_SEPARATOR) {
+		return true
+	}
+
+	if e.toMany(name) {
+		return false
+	}
+
+	return storage.Expr != "" || (storage.Column != name && strings.Contains(storage.Column, "."))
+}
+
+// toMany indica se o atributo de relacionamento percorre alguma relação
+// para muitos, ou seja, uma lista (slice / array) de estruturas.
+func (e *Executor) toMany(name string) bool {
+	segments := strings.Split(name, sparsefieldsets.PATH_SEPARATOR)
+
+	for i := 1; i < len(segments); i++ {
+		relation := e.field(strings.Join(segments[:i], sparsefieldsets.PATH_SEPARATOR))
+		if relation.Type == nil {
+			continue
+		}
+
+		t := relation.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			return true
+		}
+	}
+
+	return false
+}
+
+// computed indica se o campo é calculado por uma função após a consulta.
+func (e *Executor) computed(name string) bool {
+	storage, _ := e.Schema.Storage(name)
+	return storage.Computed()
+}
+
+// expr devolve a expressão SQL do campo: a coluna do atributo de
+// relacionamento na tabela juntada, a expressão do campo virtual entre
+// parênteses ou a coluna mapeada escapada.
+//
+//	p.created_ts // "p"."created_ts"
+//	author.name  // "author"."name"
+//
+// Quando há junções as colunas da tabela consultada são qualificadas com
+// o nome da tabela, ou com o alias quando configurado.
+func (e *Executor) expr(s *statement, name string) string {
+	if hops, column, ok := e.route(name); ok {
+		if strings.Contains(column, ".") {
+			return e.identifier(column)
+		}
+
+		return e.Dialect.Quote(hops[len(hops)-1].path) + "." + e.Dialect.Quote(column)
+	}
+
+	storage, _ := e.Schema.Storage(name)
+	if storage.Expr != "" {
+		return "(" + storage.Expr + ")"
+	}
+
+	column := storage.Column
+	if column == "" {
+		column = name
+	}
+
+	if len(s.joins) > 0 && !strings.Contains(column, ".") {
+		return e.reference() + "." + e.Dialect.Quote(column)
+	}
+
+	return e.identifier(column)
+}
+
+// selector devolve o trecho do SELECT da coluna. Os campos virtuais e as
+// colunas mapeadas recebem o nome do campo como alias.
+func (e *Executor) selector(s *statement, name string) string {
+	expr := e.expr(s, name)
+
+	storage, _ := e.Schema.Storage(name)
+	if storage.Expr == "" && (storage.Column == "" || storage.Column == name) {
+		return expr
+	}
+
+	return expr + " AS " + e.Dialect.Quote(name)
+}
+
+// Build recebe o Query e monta a consulta de listagem com as colunas
+// selecionadas, os filtros, a ordenação e a paginação.
+func (e *Executor) Build(query *gosparse.Query) (Statement, error) {
+	s := &statement{dialect: e.Dialect}
+	if err := e.plan(s, query.Where().Fields(), query.Sort); err != nil {
+		return Statement{}, err
+	}
+
+	columns := e.Columns(query)
+	quoted := make([]string, 0, len(columns))
+	for _, column := range columns {
+		quoted = append(quoted, e.selector(s, column))
+	}
+
+	s.write("SELECT ", strings.Join(quoted, ", "))
+	e.from(s)
+
+	if err := e.where(s, query.Where()); err != nil {
+		return Statement{}, err
+	}
+
+	if err := e.orderBy(s, query.Sort); err != nil {
+		return Statement{}, err
+	}
+
+	// sem paginação configurada a consulta não é limitada
+	if backend.Paginated(query.Page) {
+		offset, limit := Paginate(query.Page)
+		s.write(" LIMIT ", s.arg(limit), " OFFSET ", s.arg(offset))
+	}
+
+	return s.Statement(), nil
+}
+
+// BuildCount recebe o Query e monta a consulta de contagem com os mesmos
+// filtros da listagem.
+func (e *Executor) BuildCount(query *gosparse.Query) (Statement, error) {
+	s := &statement{dialect: e.Dialect}
+	if err := e.plan(s, query.Where().Fields(), nil); err != nil {
+		return Statement{}, err
+	}
+
+	s.write("SELECT COUNT(*)")
+	e.from(s)
+
+	if err := e.where(s, query.Where()); err != nil {
+		return Statement{}, err
+	}
+
+	return s.Statement(), nil
+}
+
+// table devolve a tabela configurada ou o tipo de recurso do Schema.
+func (e *Executor) table() string {
+	if e.Table != "" {
+		return e.Table
+	}
+
+	return e.Schema.Resource
+}
+
+// reference devolve o nome pelo qual a tabela consultada é referenciada
+// na consulta: o alias, quando configurado, ou a própria tabela.
+func (e *Executor) reference() string {
+	if e.Alias != "" {
+		return e.Dialect.Quote(e.Alias)
+	}
+
+	return e.Dialect.Quote(e.table())
+}
+
+// where monta as condições da árvore de condições do Query (veja
+// gosparse.Query.Where). Os filtros da conjunção principal são montados em
+// ordem alfabética dos campos, seguidos das condições das expressões.
+//
+// Os atributos de relacionamento são filtrados pelas tabelas juntadas ou,
+// nas relações para muitos, por uma subconsulta EXISTS.
+func (e *Executor) where(s *statement, c filter.Condition) error {
+	if c.Empty() {
+		return nil
+	}
+
+	s.write(" WHERE ")
+	return e.clause(s, c)
+}
+
+// clause monta a condição do nó. Os nós AND e OR aninhados são delimitados
+// por parênteses:
+//
+//	"price" > ? AND ("status" = ? OR NOT ("archived" = ?))
+func (e *Executor) clause(s *statement, c filter.Condition) error {
+	switch c.Operator {
+	case filter.LEAF:
+		return e.leaf(s, c.Field, c.Filter)
+	case filter.NOT:
+		s.write("NOT (")
+		if err := e.clause(s, c.Conditions[0]); err != nil {
+			return err
+		}
+
+		s.write(")")
+		return nil
+	}
+
+	for i, nested := range c.Conditions {
+		if i > 0 {
+			s.write(" ", c.Operator.String(), " ")
+		}
+
+		grouped := (nested.Operator == filter.AND || nested.Operator == filter.OR) && len(nested.Conditions) > 1
+		if grouped {
+			s.write("(")
+		}
+
+		if err := e.clause(s, nested); err != nil {
+			return err
+		}
+
+		if grouped {
+			s.write(")")
+		}
+	}
+
+	return nil
+}
+
+// leaf monta a condição do filtro de um campo.
+func (e *Executor) leaf(s *statement, field string, f filter.Field) error {
+	hops, _, joined := e.route(field)
+	if !joined && !e.column(field) {
+		return fmt.Errorf("unsupported filter %s: not a column of %s", field, e.table())
+	}
+
+	if joined && many(hops) >= 0 {
+		return e.exists(s, field, hops, f)
+	}
+
+	return e.condition(s, field, f)
+}
+
+// comparisons relaciona os predicados de comparação com os operadores
+var comparisons = map[filter.Predicate]string{
+	filter.GT:  ">",
+	filter.GTE: ">=",
+	filter.LT:  "<",
+	filter.LTE: "<=",
+}
+
+// condition monta a condição do campo a partir do predicado e dos valores.
+func (e *Executor) condition(s *statement, field string, f filter.Field) error {
+	column := e.expr(s, field)
+
+	switch f.Predicate {
+	case filter.NULL, filter.NOT_NULL:
+		null, err := backend.Flag(field, f)
+		if err != nil {
+			return err
+		}
+
+		if null == (f.Predicate == filter.NULL) {
+			s.write(column, " IS NULL")
+			return nil
+		}
+
+		s.write(column, " IS NOT NULL")
+		return nil
+	case filter.BLANK:
+		blank, err := backend.Flag(field, f)
+		if err != nil {
+			return err
+		}
+
+		if blank {
+			s.write("(", column, " IS NULL OR ", column, " = ", s.arg(""), ")")
+			return nil
+		}
+
+		s.write("(", column, " IS NOT NULL AND ", column, " <> ", s.arg(""), ")")
+		return nil
+	case filter.START, filter.END:
+		value, err := backend.Single(field, f.Predicate, f.Values)
+		if err != nil {
+			return err
+		}
+
+		pattern := escapeLike(value)
+		if f.Predicate == filter.START {
+			pattern = pattern + "%"
+		} else {
+			pattern = "%" + pattern
+		}
+
+		s.write(column, " LIKE ", s.arg(pattern), ` ESCAPE '\'`)
+		return nil
+	}
+
+	values, err := backend.Values(e.Schema, field, f)
+	if err != nil {
+		return err
+	}
+
+	switch f.Predicate {
+	case filter.NONE, filter.EQ, filter.IN:
+		if len(values) == 1 {
+			s.write(column, " = ", s.arg(values[0]))
+			return nil
+		}
+
+		s.write(column, " IN (", e.list(s, values), ")")
+		return nil
+	case filter.NEQ, filter.NIN:
+		if len(values) == 1 {
+			s.write(column, " <> ", s.arg(values[0]))
+			return nil
+		}
+
+		s.write(column, " NOT IN (", e.list(s, values), ")")
+		return nil
+	}
+
+	operator, known := comparisons[f.Predicate]
+	if !known {
+		return fmt.Errorf("unsupported predicate %s on filter %s", f.Predicate, field)
+	}
+
+	if len(values) != 1 {
+		return fmt.Errorf("filter %s with predicate %s requires a single value", field, f.Predicate)
+	}
+
+	s.write(column, " ", operator, " ", s.arg(values[0]))
+	return nil
+}
+
+// list adiciona os valores como argumentos e devolve os marcadores
+// separados por vírgula.
+func (e *Executor) list(s *statement, values []any) string {
+	placeholders := make([]string, 0, len(values))
+	for _, value := range values {
+		placeholders = append(placeholders, s.arg(value))
+	}
+
+	return strings.Join(placeholders, ", ")
+}
+
+// escapeLike escapa os caracteres especiais do LIKE.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(value)
+}
+
+// orderBy monta a ordenação, na ordem solicitada.
+func (e *Executor) orderBy(s *statement, keys []sort.Key) error {
+	for i, key := range keys {
+		if _, _, joined := e.route(key.Field); !joined && !e.column(key.Field) {
+			return fmt.Errorf("unsupported sorting by %s: not a column of %s", key.Field, e.table())
+		}
+
+		if i == 0 {
+			s.write(" ORDER BY ")
+		} else {
+			s.write(", ")
+		}
+
+		direction := "ASC"
+		if key.Sorting == sort.DESC {
+			direction = "DESC"
+		}
+
+		s.write(e.expr(s, key.Field), " ", direction)
+	}
+
+	return nil
+}
+
+// Paginate recebe o Page e devolve o deslocamento e o limite de linhas,
+// na mesma ordem dos demais backends.
+//
+// Quando page[offset] é enviado ele é utilizado como deslocamento, caso
+// contrário o deslocamento é calculado a partir de page[number]. Sem
+// paginação configurada Build omite LIMIT e OFFSET.
+func Paginate(page pagination.Page) (offset, limit int64) {
+	return backend.Paginate(page)
+}
@@ -0,0 +1,51 @@
+// Package sqlexec
+//
+// Monta e executa as consultas SQL a partir do resultado da querystring
+// (gosparse.Query) com qualquer driver do database/sql:
+//
+//	GET /articles?fields[articles]=title&filter[price_gte]=10&sort=-created_at&page[number]=2
+//
+//	SELECT "title" FROM "articles" WHERE "price" >= ? ORDER BY "created_at" DESC LIMIT ? OFFSET ?
+//	SELECT COUNT(*) FROM "articles" WHERE "price" >= ?
+//
+// O Executor recebe o *sql.DB e o Schema compilado da estrutura, executa a
+// listagem e, opcionalmente, a contagem, e preenche as estruturas com as
+// colunas selecionadas:
+//
+//	executor := sqlexec.New(db, gosparse.MustCompile[Article](), sqlexec.Count())
+//
+//	result, err := sqlexec.Find[Article](ctx, executor, query)
+//	// result.Items []Article
+//	// result.Total int64
+//
+// Os campos são consultados pela coluna mapeada no Schema (veja
+// gosparse.MapColumn e a configuração "column" da tag), e os campos virtuais
+// pela expressão SQL (gosparse.VirtualField). Os campos computados
+// (gosparse.ComputedField) são calculados após a consulta:
+//
+//	schema, err := gosparse.Compile(Article{}, gosparse.MapColumn("created_at", "a.created_ts"))
+//	sqlexec.New(db, schema, sqlexec.Alias("a"))
+//
+//	SELECT "a"."created_ts" AS "created_at" FROM "articles" AS "a"
+//
+// Os atributos de relacionamento (author.name) podem ser filtrados e
+// ordenados quando as relações do caminho têm junção, definida pela
+// configuração "join" da tag ou pela opção gosparse.JoinRelation. As relações
+// para um são juntadas com LEFT JOIN e as relações para muitos são filtradas
+// com uma subconsulta EXISTS, sem repetir as linhas dos dados primários:
+//
+//	type Article struct {
+//		Author   *Author   `gosparse:"name:author;relation;join:author_id=people.id"`
+//		Comments []Comment `gosparse:"name:comments;relation;join:id=comments.article_id"`
+//	}
+//
+//	GET /articles?filter[author.name]=Jean&filter[comments.body_start]=Go
+//
+//	SELECT ... FROM "articles" LEFT JOIN "people" AS "author" ON "author"."id" = "articles"."author_id"
+//	WHERE "author"."name" = ? AND EXISTS (SELECT 1 FROM "comments" AS "comments"
+//		WHERE "comments"."article_id" = "articles"."id" AND "comments"."body" LIKE ? ESCAPE '\')
+//
+// Somente os campos da própria estrutura, ou os campos de relações mapeados,
+// podem ser selecionados. Os identificadores são sempre escapados e os
+// valores sempre enviados como argumentos.
+package sqlexec
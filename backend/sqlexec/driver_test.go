@@ -0,0 +1,136 @@
+package sqlexec_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// fakeDriver é um driver do database/sql que registra as consultas
+// recebidas e responde com as linhas configuradas.
+type fakeDriver struct {
+	mu       sync.Mutex
+	queries  []recorded
+	rows     map[string]fakeRows
+	failWith error
+}
+
+// recorded é uma consulta recebida pelo fakeDriver
+type recorded struct {
+	SQL  string
+	Args []any
+}
+
+// fakeRows são as colunas e linhas devolvidas para consultas com o prefixo
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+// open registra um novo fakeDriver e devolve o *sql.DB conectado a ele.
+func open(rows map[string]fakeRows) (*sql.DB, *fakeDriver) {
+	fake := &fakeDriver{rows: rows}
+
+	name := fmt.Sprintf("fake-%p", fake)
+	sql.Register(name, fake)
+
+	db, err := sql.Open(name, "")
+	if err != nil {
+		panic(err)
+	}
+
+	return db, fake
+}
+
+func (d *fakeDriver) Open(string) (driver.Conn, error) {
+	return &fakeConn{driver: d}, nil
+}
+
+// recorded devolve as consultas recebidas
+func (d *fakeDriver) recorded() []recorded {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]recorded(nil), d.queries...)
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepare is not supported")
+}
+
+func (c *fakeConn) Close() error {
+	return nil
+}
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, fmt.Errorf("transactions are not supported")
+}
+
+// QueryContext registra a consulta e devolve as linhas do primeiro
+// prefixo que corresponde à consulta.
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	d := c.driver
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	values := make([]any, 0, len(args))
+	for _, arg := range args {
+		values = append(values, arg.Value)
+	}
+
+	d.queries = append(d.queries, recorded{SQL: query, Args: values})
+
+	if d.failWith != nil {
+		return nil, d.failWith
+	}
+
+	// o prefixo mais longo tem precedência, "SELECT COUNT(*)" antes de "SELECT"
+	match := ""
+	for prefix := range d.rows {
+		if strings.HasPrefix(query, prefix) && len(prefix) >= len(match) {
+			match = prefix
+		}
+	}
+
+	rows, found := d.rows[match]
+	if !found || !strings.HasPrefix(query, match) {
+		return nil, fmt.Errorf("unexpected query: %s", query)
+	}
+
+	return &fakeCursor{rows: rows}, nil
+}
+
+type fakeCursor struct {
+	rows fakeRows
+	next int
+}
+
+func (r *fakeCursor) Columns() []string {
+	return r.rows.columns
+}
+
+func (r *fakeCursor) Close() error {
+	return nil
+}
+
+func (r *fakeCursor) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows.values) {
+		return io.EOF
+	}
+
+	copy(dest, r.rows.values[r.next])
+	r.next++
+	return nil
+}
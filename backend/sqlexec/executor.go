@@ -0,0 +1,233 @@
+package sqlexec
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+
+	"github.com/jeanmolossi/gosparse"
+)
+
+// Executor executa as consultas de um Schema em um *sql.DB.
+type Executor struct {
+	// DB é a conexão utilizada nas consultas
+	DB *sql.DB
+	// Schema é o Schema compilado da estrutura consultada
+	Schema *gosparse.Schema
+	// Table é a tabela consultada, por padrão o tipo de recurso do Schema
+	Table string
+	// Alias é o nome da tabela consultada na consulta (FROM table AS
+	// alias), utilizado pelas colunas qualificadas do mapeamento
+	Alias string
+	// Dialect é a sintaxe do banco de dados, por padrão SQLite
+	Dialect Dialect
+	// Count indica se a contagem total deve ser executada junto da listagem
+	Count bool
+}
+
+// ExecutorOpt é uma assinatura para opções de configuração
+// para o construtor de Executor
+type ExecutorOpt func(*Executor)
+
+// Table é uma opção do construtor de *Executor. Essa função recebe o nome
+// da tabela consultada.
+func Table(name string) ExecutorOpt {
+	return func(e *Executor) {
+		e.Table = name
+	}
+}
+
+// Alias é uma opção do construtor de *Executor. Essa função recebe o nome
+// da tabela consultada na consulta, permitindo qualificar as colunas do
+// mapeamento:
+//
+//	gosparse.MapColumn("created_at", "p.created_ts")
+//	sqlexec.New(db, schema, sqlexec.Table("products"), sqlexec.Alias("p"))
+//
+//	SELECT "p"."created_ts" AS "created_at" FROM "products" AS "p"
+func Alias(name string) ExecutorOpt {
+	return func(e *Executor) {
+		e.Alias = name
+	}
+}
+
+// WithDialect é uma opção do construtor de *Executor. Essa função recebe a
+// sintaxe do banco de dados (SQLite, Postgres ou MySQL).
+func WithDialect(dialect Dialect) ExecutorOpt {
+	return func(e *Executor) {
+		e.Dialect = dialect
+	}
+}
+
+// Count é uma opção do construtor de *Executor que habilita a consulta de
+// contagem total junto da listagem.
+func Count() ExecutorOpt {
+	return func(e *Executor) {
+		e.Count = true
+	}
+}
+
+// Result é o resultado de uma consulta.
+type Result[T any] struct {
+	// Items são as linhas da página solicitada
+	Items []T
+	// Total é a quantidade total de linhas que atendem aos filtros, ou -1
+	// quando a contagem não está habilitada (veja Count)
+	Total int64
+}
+
+// Find executa a listagem, e a contagem quando habilitada, e devolve as
+// linhas preenchidas em T.
+//
+// T deve ser a estrutura do Schema do Executor. Somente as colunas
+// selecionadas são preenchidas, os demais campos mantém o valor zero. Os
+// campos computados selecionados são calculados a partir das colunas já
+// preenchidas de cada linha.
+//
+// O contexto é repassado ao driver, portanto o cancelamento interrompe a
+// consulta em andamento.
+func Find[T any](ctx context.Context, e *Executor, query *gosparse.Query) (*Result[T], error) {
+	if t := reflect.TypeOf((*T)(nil)).Elem(); t != e.Schema.Type {
+		return nil, fmt.Errorf("can not scan %s into %s", e.Schema.Type, t)
+	}
+
+	list, err := e.Build(query)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := scan[T](ctx, e, list, e.Columns(query))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := compute(e.Schema, items, e.Computed(query)); err != nil {
+		return nil, err
+	}
+
+	result := &Result[T]{Items: items, Total: -1}
+	if !e.Count {
+		return result, nil
+	}
+
+	count, err := e.BuildCount(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := e.DB.QueryRowContext(ctx, count.SQL, count.Args...).Scan(&result.Total); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// scan executa a listagem e preenche cada linha em um novo T a partir do
+// índice de cada coluna na estrutura.
+func scan[T any](ctx context.Context, e *Executor, list Statement, columns []string) ([]T, error) {
+	rows, err := e.DB.QueryContext(ctx, list.SQL, list.Args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]T, 0)
+	for rows.Next() {
+		var item T
+		value := reflect.ValueOf(&item).Elem()
+
+		dest := make([]any, 0, len(columns))
+		for _, column := range columns {
+			dest = append(dest, fieldByIndex(value, e.field(column).Index).Addr().Interface())
+		}
+
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// compute preenche os campos computados de cada linha com o valor
+// calculado pela função do mapeamento (veja gosparse.ComputedField).
+func compute[T any](schema *gosparse.Schema, items []T, fields []string) error {
+	for i := range items {
+		value := reflect.ValueOf(&items[i]).Elem()
+
+		for _, name := range fields {
+			computed, err := schema.Value(items[i], name)
+			if err != nil {
+				return fmt.Errorf("can not compute %s: %w", name, err)
+			}
+
+			field, _ := schema.Field(name)
+			if err := assign(fieldByIndex(value, field.Index), computed); err != nil {
+				return fmt.Errorf("can not compute %s: %w", name, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// fieldByIndex funciona como reflect.Value.FieldByIndex, mas aloca as
+// referências nulas do caminho, como nas relações para um (*Author).
+func fieldByIndex(value reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && value.Kind() == reflect.Pointer {
+			if value.IsNil() {
+				value.Set(reflect.New(value.Type().Elem()))
+			}
+
+			value = value.Elem()
+		}
+
+		value = value.Field(x)
+	}
+
+	return value
+}
+
+// assign atribui o valor ao campo, convertendo-o quando necessário.
+func assign(field reflect.Value, value any) error {
+	if value == nil {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type().AssignableTo(field.Type()):
+		field.Set(v)
+	case v.Type().ConvertibleTo(field.Type()):
+		field.Set(v.Convert(field.Type()))
+	default:
+		return fmt.Errorf("%s is not assignable to %s", v.Type(), field.Type())
+	}
+
+	return nil
+}
+
+// Constructor -----------------
+
+func New(db *sql.DB, schema *gosparse.Schema, opt ...ExecutorOpt) *Executor {
+	executor := &Executor{DB: db, Schema: schema, Dialect: SQLite}
+
+	for _, o := range opt {
+		if o == nil {
+			continue
+		}
+
+		o(executor)
+	}
+
+	return executor
+}
@@ -0,0 +1,205 @@
+package sqlexec
+
+import (
+	"fmt"
+	stdsort "sort"
+	"strings"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
+)
+
+// hop é uma relação percorrida por um atributo de relacionamento. O caminho
+// da relação é utilizado como alias da tabela relacionada:
+//
+//	// author.name
+//	LEFT JOIN "authors" AS "author" ON "author"."id" = "articles"."author_id"
+type hop struct {
+	path string
+	join gosparse.Join
+}
+
+// route devolve as relações percorridas pelo atributo de relacionamento e a
+// coluna do atributo na tabela da última relação.
+//
+// Caso o campo não seja um atributo de relacionamento, ou alguma relação do
+// caminho não tenha junção (veja gosparse.JoinRelation), ok será false.
+func (e *Executor) route(name string) (hops []hop, column string, ok bool) {
+	segments := strings.Split(name, sparsefieldsets.PATH_SEPARATOR)
+	if len(segments) < 2 {
+		return nil, "", false
+	}
+
+	field, exists := e.Schema.Field(name)
+	if !exists || field.Relation {
+		return nil, "", false
+	}
+
+	storage, _ := e.Schema.Storage(name)
+	if storage.Virtual() {
+		return nil, "", false
+	}
+
+	for i := 1; i < len(segments); i++ {
+		path := strings.Join(segments[:i], sparsefieldsets.PATH_SEPARATOR)
+
+		relation, _ := e.Schema.Storage(path)
+		if relation.Join == nil {
+			return nil, "", false
+		}
+
+		hops = append(hops, hop{path: path, join: *relation.Join})
+	}
+
+	column = storage.Column
+	if column == name {
+		column = segments[len(segments)-1]
+	}
+
+	return hops, column, true
+}
+
+// many devolve a posição da primeira relação para muitos do caminho, ou -1
+// quando todas as relações são para um.
+func many(hops []hop) int {
+	for i, h := range hops {
+		if h.join.Many {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// plan registra no statement as junções das relações para um percorridas
+// pelos filtros e pela ordenação, em ordem alfabética dos caminhos.
+//
+// Os filtros em relações para muitos são montados com EXISTS (veja exists),
+// portanto somente as relações anteriores à primeira relação para muitos são
+// juntadas. A ordenação por relações para muitos não é suportada.
+func (e *Executor) plan(s *statement, filters []string, keys []sort.Key) error {
+	paths := make(map[string]hop)
+
+	for _, field := range filters {
+		hops, _, ok := e.route(field)
+		if !ok {
+			continue
+		}
+
+		if i := many(hops); i >= 0 {
+			hops = hops[:i]
+		}
+
+		for _, h := range hops {
+			paths[h.path] = h
+		}
+	}
+
+	for _, key := range keys {
+		hops, _, ok := e.route(key.Field)
+		if !ok {
+			continue
+		}
+
+		if i := many(hops); i >= 0 {
+			return fmt.Errorf("unsupported sorting by %s: %s is a to-many relation", key.Field, hops[i].path)
+		}
+
+		for _, h := range hops {
+			paths[h.path] = h
+		}
+	}
+
+	s.joins = make([]hop, 0, len(paths))
+	for _, h := range paths {
+		s.joins = append(s.joins, h)
+	}
+
+	stdsort.Slice(s.joins, func(i, j int) bool {
+		return s.joins[i].path < s.joins[j].path
+	})
+
+	return nil
+}
+
+// from escreve a tabela consultada e as junções planejadas:
+//
+//	FROM "articles" LEFT JOIN "authors" AS "author" ON "author"."id" = "articles"."author_id"
+func (e *Executor) from(s *statement) {
+	s.write(" FROM ", e.Dialect.Quote(e.table()))
+	if e.Alias != "" {
+		s.write(" AS ", e.reference())
+	}
+
+	for _, h := range s.joins {
+		s.write(" LEFT JOIN ", e.on(h, e.parent(h.path)))
+	}
+}
+
+// on devolve a tabela da relação com o alias e a condição da junção com a
+// tabela de origem (parent).
+func (e *Executor) on(h hop, parent string) string {
+	alias := e.Dialect.Quote(h.path)
+
+	return e.identifier(h.join.Table) + " AS " + alias +
+		" ON " + alias + "." + e.Dialect.Quote(h.join.Remote) +
+		" = " + parent + "." + e.Dialect.Quote(h.join.Local)
+}
+
+// parent devolve o alias da tabela de origem da relação: a relação anterior
+// do caminho ou a própria tabela consultada.
+func (e *Executor) parent(path string) string {
+	i := strings.LastIndex(path, sparsefieldsets.PATH_SEPARATOR)
+	if i < 0 {
+		return e.reference()
+	}
+
+	return e.Dialect.Quote(path[:i])
+}
+
+// exists monta a condição de um atributo de relacionamento que percorre uma
+// relação para muitos com uma subconsulta EXISTS, evitando a repetição das
+// linhas dos dados primários:
+//
+//	// filter[comments.body_start]=Go
+//	EXISTS (SELECT 1 FROM "comments" AS "comments"
+//		WHERE "comments"."article_id" = "articles"."id" AND "comments"."body" LIKE ?)
+//
+// As relações seguintes do caminho são juntadas dentro da subconsulta.
+func (e *Executor) exists(s *statement, field string, hops []hop, f filter.Field) error {
+	i := many(hops)
+	first := hops[i]
+
+	s.write("EXISTS (SELECT 1 FROM ", e.identifier(first.join.Table), " AS ", e.Dialect.Quote(first.path))
+
+	for _, h := range hops[i+1:] {
+		s.write(" JOIN ", e.on(h, e.parent(h.path)))
+	}
+
+	s.write(
+		" WHERE ", e.Dialect.Quote(first.path), ".", e.Dialect.Quote(first.join.Remote),
+		" = ", e.parent(first.path), ".", e.Dialect.Quote(first.join.Local), " AND ",
+	)
+
+	if err := e.condition(s, field, f); err != nil {
+		return err
+	}
+
+	s.write(")")
+	return nil
+}
+
+// identifier escapa um identificador que pode conter o schema ou a tabela
+// separados por pontos:
+//
+//	public.authors // "public"."authors"
+func (e *Executor) identifier(name string) string {
+	segments := strings.Split(name, ".")
+	for i, segment := range segments {
+		segments[i] = e.Dialect.Quote(segment)
+	}
+
+	return strings.Join(segments, ".")
+}
@@ -0,0 +1,456 @@
+package sqlexec_test
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/backend/sqlexec"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/stretchr/testify/require"
+)
+
+type Article struct {
+	_         struct{}   `gosparse:"type:articles"`
+	ID        int64      `gosparse:"name:id;select;sort;filter"`
+	Title     string     `gosparse:"name:title;select;sort;filter"`
+	Price     float64    `gosparse:"name:price;select;sort;filter"`
+	CreatedAt time.Time  `gosparse:"name:created_at;select;sort;filter"`
+	DeletedAt *time.Time `gosparse:"name:deleted_at;select;filter"`
+	Author    *Author    `gosparse:"name:author;relation"`
+}
+
+type Author struct {
+	Name string `gosparse:"name:name;select;filter"`
+}
+
+func parse(t *testing.T, query url.Values) *gosparse.Query {
+	t.Helper()
+
+	parsed, err := gosparse.MustCompile[Article]().Gosparse().Parse(query)
+	require.Nil(t, err)
+
+	return parsed
+}
+
+func TestBuild(t *testing.T) {
+	schema, err := gosparse.Compile(Article{}, gosparse.AcceptFilters("author"), gosparse.FilterExpressions())
+	require.Nil(t, err)
+
+	executor := sqlexec.New(nil, schema)
+
+	testtable := []struct {
+		desc    string
+		query   url.Values
+		dialect sqlexec.Dialect
+		sql     string
+		args    []any
+		err     error
+	}{
+		{
+			desc:  "should select default columns",
+			query: url.Values{},
+			sql:   `SELECT "created_at", "deleted_at", "id", "price", "title" FROM "articles" LIMIT ? OFFSET ?`,
+			args:  []any{int64(10), int64(0)},
+		},
+		{
+			desc: "should build where, order and pagination",
+			query: url.Values{
+				"fields":            {"title,author,id"},
+				"filter[price_gte]": {"9.5"},
+				"filter[id_in]":     {"1,2"},
+				"sort":              {"-created_at,title"},
+				"page[number]":      {"3"},
+				"page[size]":        {"20"},
+			},
+			sql: `SELECT "title", "id" FROM "articles" WHERE "id" IN (?, ?) AND "price" >= ? ` +
+				`ORDER BY "created_at" DESC, "title" ASC LIMIT ? OFFSET ?`,
+			args: []any{int64(1), int64(2), 9.5, int64(20), int64(40)},
+		},
+		{
+			desc: "should build with postgres placeholders",
+			query: url.Values{
+				"fields":         {"id"},
+				"filter[title]":  {"gosparse"},
+				"filter[id_neq]": {"3"},
+			},
+			dialect: sqlexec.Postgres,
+			sql:     `SELECT "id" FROM "articles" WHERE "id" <> $1 AND "title" = $2 LIMIT $3 OFFSET $4`,
+			args:    []any{int64(3), "gosparse", int64(10), int64(0)},
+		},
+		{
+			desc: "should build with mysql identifiers",
+			query: url.Values{
+				"fields":         {"id"},
+				"filter[id_nin]": {"1,2"},
+			},
+			dialect: sqlexec.MySQL,
+			sql:     "SELECT `id` FROM `articles` WHERE `id` NOT IN (?, ?) LIMIT ? OFFSET ?",
+			args:    []any{int64(1), int64(2), int64(10), int64(0)},
+		},
+		{
+			desc: "should build text and presence conditions",
+			query: url.Values{
+				"fields":                     {"id"},
+				"filter[title_start]":        {"50%_off"},
+				"filter[deleted_at_null]":    {""},
+				"filter[created_at_notnull]": {"false"},
+			},
+			sql: `SELECT "id" FROM "articles" WHERE "created_at" IS NULL AND "deleted_at" IS NULL ` +
+				`AND "title" LIKE ? ESCAPE '\' LIMIT ? OFFSET ?`,
+			args: []any{`50\%\_off%`, int64(10), int64(0)},
+		},
+		{
+			desc: "should build or and not conditions",
+			query: url.Values{
+				"fields": {"id"},
+				"filter": {`price > 10 AND (title = "Go" OR NOT title = "Go*") OR NOT deleted_at:*`},
+			},
+			sql: `SELECT "id" FROM "articles" WHERE "price" > ? AND ("title" = ? OR NOT ("title" LIKE ? ESCAPE '\') ` +
+				`OR "deleted_at" IS NULL) LIMIT ? OFFSET ?`,
+			args: []any{10.0, "Go", `Go%`, int64(10), int64(0)},
+		},
+		{
+			desc:  "should fail invalid value",
+			query: url.Values{"filter[id_gt]": {"one"}},
+			err:   fmt.Errorf("invalid filter id: value one is not a valid int64"),
+		},
+		{
+			desc:  "should fail filter on relation",
+			query: url.Values{"filter[author]": {"john"}},
+			err:   fmt.Errorf("unsupported filter author: not a column of articles"),
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			query, err := schema.Gosparse().Parse(tt.query)
+			require.Nil(t, err)
+
+			executor.Dialect = sqlexec.SQLite
+			if tt.dialect.Quote != nil {
+				executor.Dialect = tt.dialect
+			}
+
+			statement, err := executor.Build(query)
+			if tt.err != nil {
+				require.EqualError(t, err, tt.err.Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tt.sql, statement.SQL)
+			require.Equal(t, tt.args, statement.Args)
+		})
+	}
+}
+
+func TestBuildWithoutPagination(t *testing.T) {
+	gs := gosparse.MustCompile[Article]().Gosparse()
+	gs.Pagination = nil
+
+	query, err := gs.Parse(url.Values{"fields": {"id"}})
+	require.Nil(t, err)
+
+	statement, err := sqlexec.New(nil, gosparse.MustCompile[Article]()).Build(query)
+	require.Nil(t, err)
+	require.Equal(t, `SELECT "id" FROM "articles"`, statement.SQL)
+	require.Empty(t, statement.Args)
+
+	// um tamanho 0 configurado ainda limita a consulta
+	gs.Pagination = pagination.Pagination{pagination.SIZE: 0}
+
+	query, err = gs.Parse(url.Values{"fields": {"id"}})
+	require.Nil(t, err)
+
+	statement, err = sqlexec.New(nil, gosparse.MustCompile[Article]()).Build(query)
+	require.Nil(t, err)
+	require.Equal(t, `SELECT "id" FROM "articles" LIMIT ? OFFSET ?`, statement.SQL)
+	require.Equal(t, []any{int64(0), int64(0)}, statement.Args)
+}
+
+func TestPaginate(t *testing.T) {
+	offset, limit := sqlexec.Paginate(parse(t, url.Values{"page[number]": {"3"}, "page[size]": {"5"}}).Page)
+	require.Equal(t, int64(10), offset)
+	require.Equal(t, int64(5), limit)
+}
+
+func TestFind(t *testing.T) {
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	db, fake := open(map[string]fakeRows{
+		"SELECT COUNT(*)": {columns: []string{"count"}, values: [][]driver.Value{{int64(42)}}},
+		"SELECT": {
+			columns: []string{"id", "title", "created_at", "deleted_at"},
+			values: [][]driver.Value{
+				{int64(1), "first", created, nil},
+				{int64(2), "second", created, created},
+			},
+		},
+	})
+	defer db.Close()
+
+	executor := sqlexec.New(db, gosparse.MustCompile[Article](), sqlexec.Count(), sqlexec.Table("posts"))
+
+	result, err := sqlexec.Find[Article](context.Background(), executor, parse(t, url.Values{
+		"fields":         {"id,title,created_at,deleted_at"},
+		"filter[id_gte]": {"1"},
+	}))
+	require.Nil(t, err)
+
+	require.Equal(t, int64(42), result.Total)
+	require.Equal(t, []Article{
+		{ID: 1, Title: "first", CreatedAt: created},
+		{ID: 2, Title: "second", CreatedAt: created, DeletedAt: &created},
+	}, result.Items)
+
+	require.Equal(t, []recorded{
+		{
+			SQL:  `SELECT "id", "title", "created_at", "deleted_at" FROM "posts" WHERE "id" >= ? LIMIT ? OFFSET ?`,
+			Args: []any{int64(1), int64(10), int64(0)},
+		},
+		{
+			SQL:  `SELECT COUNT(*) FROM "posts" WHERE "id" >= ?`,
+			Args: []any{int64(1)},
+		},
+	}, fake.recorded())
+}
+
+func TestFindWithoutCount(t *testing.T) {
+	db, fake := open(map[string]fakeRows{
+		"SELECT": {columns: []string{"id"}, values: [][]driver.Value{{int64(7)}}},
+	})
+	defer db.Close()
+
+	executor := sqlexec.New(db, gosparse.MustCompile[Article]())
+
+	result, err := sqlexec.Find[Article](context.Background(), executor, parse(t, url.Values{"fields": {"id"}}))
+	require.Nil(t, err)
+	require.Equal(t, int64(-1), result.Total)
+	require.Equal(t, []Article{{ID: 7}}, result.Items)
+	require.Len(t, fake.recorded(), 1)
+}
+
+func TestFindErrors(t *testing.T) {
+	t.Run("should honor context cancellation", func(t *testing.T) {
+		db, fake := open(map[string]fakeRows{})
+		defer db.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := sqlexec.Find[Article](ctx, sqlexec.New(db, gosparse.MustCompile[Article]()), parse(t, url.Values{}))
+		require.ErrorIs(t, err, context.Canceled)
+		require.Empty(t, fake.recorded())
+	})
+
+	t.Run("should return driver errors", func(t *testing.T) {
+		db, fake := open(map[string]fakeRows{})
+		defer db.Close()
+		fake.failWith = fmt.Errorf("connection reset")
+
+		_, err := sqlexec.Find[Article](context.Background(), sqlexec.New(db, gosparse.MustCompile[Article]()), parse(t, url.Values{}))
+		require.EqualError(t, err, "connection reset")
+	})
+
+	t.Run("should reject another struct", func(t *testing.T) {
+		_, err := sqlexec.Find[Author](context.Background(), sqlexec.New(nil, gosparse.MustCompile[Article]()), parse(t, url.Values{}))
+		require.EqualError(t, err, "can not scan sqlexec_test.Article into sqlexec_test.Author")
+	})
+}
+
+type Product struct {
+	_          struct{} `gosparse:"type:products"`
+	ID         int64    `gosparse:"name:id;select;sort;filter"`
+	CreatedAt  string   `gosparse:"name:created_at;select;sort;filter;column:p.created_ts"`
+	FullName   string   `gosparse:"name:full_name;select;sort;filter"`
+	Price      float64  `gosparse:"name:price;select"`
+	Discounted float64  `gosparse:"name:discounted;select;sort"`
+}
+
+func TestStorageMapping(t *testing.T) {
+	schema, err := gosparse.Compile(Product{},
+		gosparse.VirtualField("full_name", "first_name || ' ' || last_name"),
+		gosparse.ComputedField("discounted", func(item any) (any, error) {
+			return item.(Product).Price * 0.9, nil
+		}, "price"),
+	)
+	require.Nil(t, err)
+
+	executor := sqlexec.New(nil, schema, sqlexec.Alias("p"))
+
+	t.Run("should map columns and virtual fields", func(t *testing.T) {
+		query, err := schema.Gosparse().Parse(url.Values{
+			"fields":                {"id,created_at,full_name,discounted"},
+			"filter[full_name]":     {"Jean"},
+			"filter[created_at_gt]": {"2023"},
+			"sort":                  {"-created_at,full_name"},
+		})
+		require.Nil(t, err)
+
+		statement, err := executor.Build(query)
+		require.Nil(t, err)
+		require.Equal(t,
+			`SELECT "id", "p"."created_ts" AS "created_at", (first_name || ' ' || last_name) AS "full_name", "price" `+
+				`FROM "products" AS "p" WHERE "p"."created_ts" > ? AND (first_name || ' ' || last_name) = ? `+
+				`ORDER BY "p"."created_ts" DESC, (first_name || ' ' || last_name) ASC LIMIT ? OFFSET ?`,
+			statement.SQL,
+		)
+		require.Equal(t, []string{"discounted"}, executor.Computed(query))
+	})
+
+	t.Run("should reject sorting by computed field", func(t *testing.T) {
+		query, err := schema.Gosparse().Parse(url.Values{"sort": {"discounted"}})
+		require.Nil(t, err)
+
+		_, err = executor.Build(query)
+		require.EqualError(t, err, "unsupported sorting by discounted: not a column of products")
+	})
+
+	t.Run("should compute selected fields after scan", func(t *testing.T) {
+		db, _ := open(map[string]fakeRows{
+			"SELECT": {columns: []string{"id", "price"}, values: [][]driver.Value{{int64(1), 10.0}}},
+		})
+		defer db.Close()
+
+		query, err := schema.Gosparse().Parse(url.Values{"fields": {"id,price,discounted"}})
+		require.Nil(t, err)
+
+		result, err := sqlexec.Find[Product](context.Background(), sqlexec.New(db, schema), query)
+		require.Nil(t, err)
+		require.Equal(t, []Product{{ID: 1, Price: 10, Discounted: 9}}, result.Items)
+	})
+
+	t.Run("should select dependencies of computed fields", func(t *testing.T) {
+		db, fake := open(map[string]fakeRows{
+			"SELECT": {columns: []string{"id", "price"}, values: [][]driver.Value{{int64(1), 10.0}}},
+		})
+		defer db.Close()
+
+		query, err := schema.Gosparse().Parse(url.Values{"fields": {"id,discounted"}})
+		require.Nil(t, err)
+
+		result, err := sqlexec.Find[Product](context.Background(), sqlexec.New(db, schema), query)
+		require.Nil(t, err)
+		require.Equal(t, []Product{{ID: 1, Price: 10, Discounted: 9}}, result.Items)
+		require.Equal(t, `SELECT "id", "price" FROM "products" LIMIT ? OFFSET ?`, fake.recorded()[0].SQL)
+	})
+}
+
+type Post struct {
+	_        struct{} `gosparse:"type:posts"`
+	ID       int64    `gosparse:"name:id;select;sort;filter"`
+	Title    string   `gosparse:"name:title;select;sort;filter"`
+	Author   *Writer  `gosparse:"name:author;relation;join:author_id=people.id"`
+	Comments []Reply  `gosparse:"name:comments;relation;join:id=comments.post_id"`
+}
+
+type Writer struct {
+	Name string `gosparse:"name:name;select;sort;filter;column:display_name"`
+}
+
+type Reply struct {
+	Body   string  `gosparse:"name:body;select;sort;filter"`
+	Author *Writer `gosparse:"name:author;relation;join:author_id=people.id"`
+}
+
+func TestBuildJoins(t *testing.T) {
+	schema, err := gosparse.Compile(Post{})
+	require.Nil(t, err)
+
+	executor := sqlexec.New(nil, schema)
+
+	testtable := []struct {
+		desc  string
+		query url.Values
+		sql   string
+		err   error
+	}{
+		{
+			desc:  "should join to-one relation for filter and sort",
+			query: url.Values{"filter[author.name_start]": {"J"}, "sort": {"-author.name,title"}},
+			sql: `SELECT "posts"."id", "posts"."title" FROM "posts" ` +
+				`LEFT JOIN "people" AS "author" ON "author"."id" = "posts"."author_id" ` +
+				`WHERE "author"."display_name" LIKE ? ESCAPE '\' ` +
+				`ORDER BY "author"."display_name" DESC, "posts"."title" ASC LIMIT ? OFFSET ?`,
+		},
+		{
+			desc:  "should filter to-many relation with exists",
+			query: url.Values{"filter[comments.body]": {"hi"}, "filter[id_gt]": {"1"}},
+			sql: `SELECT "id", "title" FROM "posts" WHERE ` +
+				`EXISTS (SELECT 1 FROM "comments" AS "comments" ` +
+				`WHERE "comments"."post_id" = "posts"."id" AND "comments"."body" = ?) ` +
+				`AND "id" > ? LIMIT ? OFFSET ?`,
+		},
+		{
+			desc:  "should join relations after to-many inside exists",
+			query: url.Values{"filter[comments.author.name]": {"Jean"}},
+			sql: `SELECT "id", "title" FROM "posts" WHERE ` +
+				`EXISTS (SELECT 1 FROM "comments" AS "comments" ` +
+				`JOIN "people" AS "comments.author" ON "comments.author"."id" = "comments"."author_id" ` +
+				`WHERE "comments"."post_id" = "posts"."id" AND "comments.author"."display_name" = ?) ` +
+				`LIMIT ? OFFSET ?`,
+		},
+		{
+			desc:  "should reject sorting by to-many relation",
+			query: url.Values{"sort": {"comments.body"}},
+			err:   fmt.Errorf("unsupported sorting by comments.body: comments is a to-many relation"),
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			query, err := schema.Gosparse().Parse(tt.query)
+			require.Nil(t, err)
+
+			statement, err := executor.Build(query)
+			if tt.err != nil {
+				require.EqualError(t, err, tt.err.Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tt.sql, statement.SQL)
+		})
+	}
+
+	t.Run("should join on count", func(t *testing.T) {
+		query, err := schema.Gosparse().Parse(url.Values{"filter[author.name]": {"Jean"}, "sort": {"title"}})
+		require.Nil(t, err)
+
+		statement, err := executor.BuildCount(query)
+		require.Nil(t, err)
+		require.Equal(t,
+			`SELECT COUNT(*) FROM "posts" LEFT JOIN "people" AS "author" ON "author"."id" = "posts"."author_id" `+
+				`WHERE "author"."display_name" = ?`,
+			statement.SQL,
+		)
+		require.Equal(t, []any{"Jean"}, statement.Args)
+	})
+
+	t.Run("should join from table alias", func(t *testing.T) {
+		query, err := schema.Gosparse().Parse(url.Values{"filter[author.name]": {"Jean"}, "sort": {"title"}})
+		require.Nil(t, err)
+
+		statement, err := sqlexec.New(nil, schema, sqlexec.Alias("p")).Build(query)
+		require.Nil(t, err)
+		require.Equal(t,
+			`SELECT "p"."id", "p"."title" FROM "posts" AS "p" `+
+				`LEFT JOIN "people" AS "author" ON "author"."id" = "p"."author_id" `+
+				`WHERE "author"."display_name" = ? ORDER BY "p"."title" ASC LIMIT ? OFFSET ?`,
+			statement.SQL,
+		)
+	})
+
+	t.Run("should reject relation without join", func(t *testing.T) {
+		query, err := gosparse.MustCompile[Article]().Gosparse().Parse(url.Values{"filter[author.name]": {"Jean"}})
+		require.Nil(t, err)
+
+		_, err = sqlexec.New(nil, gosparse.MustCompile[Article]()).Build(query)
+		require.EqualError(t, err, "unsupported filter author.name: not a column of articles")
+	})
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/include"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
+)
+
+// Builder monta a querystring de uma request para uma API construída
+// com gosparse.
+//
+// O valor zero de Builder é uma querystring vazia pronta para uso. Os
+// métodos alteram o próprio Builder e o devolvem para encadear as chamadas.
+//
+// O Builder não valida os campos, a validação é feita pelo servidor.
+type Builder struct {
+	include []include.Relation
+	fields  sparsefieldsets.Fields
+	filters filter.Filters
+	sort    []sort.Key
+	page    map[string]int
+}
+
+// New devolve um novo Builder vazio.
+func New() *Builder {
+	return &Builder{}
+}
+
+// Include inicia um novo Builder com os caminhos de relacionamento
+// (veja Builder.Include).
+func Include(paths ...string) *Builder {
+	return New().Include(paths...)
+}
+
+// Fields inicia um novo Builder com os atributos do tipo de recurso
+// (veja Builder.Fields).
+func Fields(typ string, attrs ...string) *Builder {
+	return New().Fields(typ, attrs...)
+}
+
+// Filter inicia um novo Builder com o filtro do campo
+// (veja Builder.Filter).
+func Filter(field string, predicate gosparse.Predicate, values ...any) *Builder {
+	return New().Filter(field, predicate, values...)
+}
+
+// Sort inicia um novo Builder com os campos ordenados
+// (veja Builder.Sort).
+func Sort(fields ...string) *Builder {
+	return New().Sort(fields...)
+}
+
+// Page inicia um novo Builder com a página e o tamanho da página
+// (veja Builder.Page).
+func Page(number, size int) *Builder {
+	return New().Page(number, size)
+}
+
+// Include adiciona os caminhos de relacionamento ao parâmetro "include".
+//
+//	b.Include("author", "comments.author") // include=author,comments.author
+func (b *Builder) Include(paths ...string) *Builder {
+	for _, path := range paths {
+		b.include = append(b.include, include.Relation{Path: path})
+	}
+
+	return b
+}
+
+// Fields adiciona os atributos do tipo de recurso ao parâmetro
+// "fields[TYPE]". O tipo vazio é codificado como o parâmetro "fields"
+// sem tipo, que se refere aos dados primários.
+//
+//	b.Fields("articles", "title", "body") // fields[articles]=title,body
+//	b.Fields("people")                    // fields[people]=
+func (b *Builder) Fields(typ string, attrs ...string) *Builder {
+	if b.fields == nil {
+		b.fields = make(sparsefieldsets.Fields)
+	}
+
+	b.fields[typ] = append(b.fields[typ], attrs...)
+	return b
+}
+
+// Filter define o filtro do campo com o predicado e os valores. Caso o
+// campo já tenha um filtro ele será substituído.
+//
+//	b.Filter("price", gosparse.GTE, 10) // filter[price_gte]=10
+//	b.Filter("status", gosparse.IN, "draft", "review") // filter[status_in]=draft,review
+//
+// Valores time.Time são formatados com time.RFC3339, os demais com fmt.
+// Vírgulas nos valores são escapadas, portanto "a,b" é enviado como um
+// único valor.
+func (b *Builder) Filter(field string, predicate gosparse.Predicate, values ...any) *Builder {
+	if b.filters == nil {
+		b.filters = make(filter.Filters)
+	}
+
+	formatted := make([]string, 0, len(values))
+	for _, value := range values {
+		formatted = append(formatted, format(value))
+	}
+
+	b.filters[field] = filter.Field{Predicate: predicate, Values: formatted}
+	return b
+}
+
+// format formata o valor de um filtro para a querystring.
+func format(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339)
+	case int:
+		return strconv.Itoa(v)
+	}
+
+	return fmt.Sprint(value)
+}
+
+// Sort adiciona os campos ao parâmetro "sort", na ordem recebida. Campos
+// com o prefixo "-" são ordenados de forma descendente.
+//
+//	b.Sort("-created_at", "title") // sort=-created_at,title
+func (b *Builder) Sort(fields ...string) *Builder {
+	for _, field := range fields {
+		key := sort.Key{Field: field, Sorting: sort.ASC}
+
+		if name, desc := strings.CutPrefix(field, "-"); desc {
+			key = sort.Key{Field: name, Sorting: sort.DESC}
+		}
+
+		b.sort = append(b.sort, key)
+	}
+
+	return b
+}
+
+// Page define a página e o tamanho da página do parâmetro "page".
+//
+//	b.Page(2, 50) // page[number]=2&page[size]=50
+func (b *Builder) Page(number, size int) *Builder {
+	return b.paginate(string(pagination.NUMBER), number).paginate(string(pagination.SIZE), size)
+}
+
+// Offset define o deslocamento e o tamanho da página do parâmetro "page".
+//
+//	b.Offset(100, 50) // page[offset]=100&page[size]=50
+func (b *Builder) Offset(offset, size int) *Builder {
+	return b.paginate(string(pagination.OFFSET), offset).paginate(string(pagination.SIZE), size)
+}
+
+// paginate define o valor da propriedade do parâmetro "page".
+func (b *Builder) paginate(param string, value int) *Builder {
+	if b.page == nil {
+		b.page = make(map[string]int)
+	}
+
+	b.page[param] = value
+	return b
+}
+
+// Values devolve a querystring montada, compatível com os decoders do
+// servidor.
+func (b *Builder) Values() url.Values {
+	query := url.Values{}
+
+	for _, encoded := range []url.Values{
+		include.Encode(b.include),
+		sparsefieldsets.Encode(b.fields),
+		filter.Encode(b.filters),
+		sort.Encode(b.sort),
+	} {
+		for key, values := range encoded {
+			query[key] = values
+		}
+	}
+
+	for param, value := range b.page {
+		query.Set(pagination.PAGE_PARAM+"["+param+"]", strconv.Itoa(value))
+	}
+
+	return query
+}
+
+// Encode devolve a querystring montada, com os parâmetros em ordem
+// alfabética.
+func (b *Builder) Encode() string {
+	return b.Values().Encode()
+}
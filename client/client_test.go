@@ -0,0 +1,130 @@
+package client_test
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/client"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/stretchr/testify/require"
+)
+
+type Article struct {
+	_         struct{}  `gosparse:"type:articles"`
+	Title     string    `gosparse:"name:title;select;sort"`
+	Price     int       `gosparse:"name:price;select;filter"`
+	CreatedAt time.Time `gosparse:"name:created_at;select;sort;filter"`
+	Comments  []Comment `gosparse:"name:comments;relation"`
+}
+
+type Comment struct {
+	Body   string `gosparse:"name:body;select"`
+	Author Author `gosparse:"name:author;relation"`
+}
+
+type Author struct {
+	Name string `gosparse:"name:name;select"`
+}
+
+func TestBuilder(t *testing.T) {
+	testtable := []struct {
+		desc    string
+		builder *client.Builder
+		expect  url.Values
+	}{
+		{
+			desc:    "should build empty query",
+			builder: client.New(),
+			expect:  url.Values{},
+		},
+		{
+			desc:    "should build include",
+			builder: client.Include("comments.author").Include("comments"),
+			expect:  url.Values{"include": {"comments,comments.author"}},
+		},
+		{
+			desc:    "should build fields",
+			builder: client.Fields("articles", "title").Fields("articles", "price").Fields("", "body"),
+			expect:  url.Values{"fields[articles]": {"price,title"}, "fields": {"body"}},
+		},
+		{
+			desc:    "should build empty fields",
+			builder: client.Fields("articles", "title").Fields("comments"),
+			expect:  url.Values{"fields[articles]": {"title"}, "fields[comments]": {""}},
+		},
+		{
+			desc: "should build filters",
+			builder: client.Filter("price", gosparse.GTE, 10).
+				Filter("status", gosparse.IN, "draft", "review").
+				Filter("title", gosparse.NONE, "gosparse").
+				Filter("created_at", gosparse.START, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)),
+			expect: url.Values{
+				"filter[price_gte]":        {"10"},
+				"filter[status_in]":        {"draft,review"},
+				"filter[title]":            {"gosparse"},
+				"filter[created_at_start]": {"2023-01-01T00:00:00Z"},
+			},
+		},
+		{
+			desc:    "should build sort",
+			builder: client.Sort("-created_at").Sort("title"),
+			expect:  url.Values{"sort": {"-created_at,title"}},
+		},
+		{
+			desc:    "should build page",
+			builder: client.Page(2, 50),
+			expect:  url.Values{"page[number]": {"2"}, "page[size]": {"50"}},
+		},
+		{
+			desc:    "should build offset",
+			builder: client.New().Offset(100, 25),
+			expect:  url.Values{"page[offset]": {"100"}, "page[size]": {"25"}},
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			require.Equal(t, tt.expect, tt.builder.Values())
+		})
+	}
+}
+
+func TestBuilderDecode(t *testing.T) {
+	gs, err := gosparse.Extract(Article{})
+	require.Nil(t, err)
+
+	query := client.Include("comments.author").
+		Fields("articles", "title").
+		Filter("price", gosparse.GTE, 10).
+		Sort("-created_at").
+		Page(2, 50)
+
+	values, err := url.ParseQuery(query.Encode())
+	require.Nil(t, err)
+
+	parsed, err := gs.Parse(values)
+	require.Nil(t, err)
+
+	require.Equal(t, []string{"comments", "comments.author"}, parsed.Includes())
+	require.Equal(t, []string{"title"}, parsed.Select("articles"))
+	require.Equal(t, filter.Field{Predicate: filter.GTE, Values: []string{"10"}}, parsed.Filter["price"])
+	require.Equal(t, []sort.Key{{Field: "created_at", Sorting: sort.DESC}}, parsed.Sort)
+	require.Equal(t, 2, parsed.Page.Get(pagination.NUMBER))
+	require.Equal(t, 50, parsed.Page.Get(pagination.SIZE))
+}
+
+func TestBuilderFilterWithCommas(t *testing.T) {
+	gs := gosparse.New(gosparse.AcceptFilters("title"))
+
+	query := client.Filter("title", gosparse.IN, "go, rust", `c\`)
+	require.Equal(t, url.Values{"filter[title_in]": {`c\\,go\, rust`}}, query.Values())
+
+	// vírgulas escapadas não separam os valores
+	parsed, err := gs.Parse(query.Values())
+	require.Nil(t, err)
+	require.Equal(t, []string{`c\`, "go, rust"}, parsed.Filter["title"].Values)
+}
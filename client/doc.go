@@ -0,0 +1,21 @@
+// Package client
+//
+// Serviços que consomem APIs construídas com gosparse precisam montar a
+// querystring com os mesmos parâmetros decodificados pelo servidor:
+// "include", "fields[TYPE]", "filter[FIELD_PREDICATE]", "sort" e "page".
+//
+// O Builder monta esses parâmetros de forma fluente, utilizando as mesmas
+// constantes de predicado do servidor:
+//
+//	query := client.Include("comments.author").
+//		Fields("articles", "title").
+//		Filter("price", gosparse.GTE, 10).
+//		Sort("-created_at").
+//		Page(2, 50)
+//
+//	req, err := http.NewRequest(http.MethodGet, "/articles?"+query.Encode(), nil)
+//
+// # References
+//
+//   - https://jsonapi.org/format/#query-parameters
+package client
@@ -0,0 +1,28 @@
+// Command gosparse-gen
+//
+// Gera, a partir das tags "gosparse" das estruturas de um pacote, o código
+// que configura o Gosparse sem reflexão em tempo de execução, com acesso
+// tipado aos filtros e a projeção dos atributos selecionados:
+//
+//	//go:generate go run github.com/jeanmolossi/gosparse/cmd/gosparse-gen -type Article -page-size 25
+//
+// Para cada estrutura são gerados, no arquivo gosparse_gen.go do pacote:
+//
+//   - constantes com o tipo de recurso e os caminhos dos campos
+//     (ArticleResource, ArticleFieldAuthorName)
+//   - NewArticleGosparse e ArticleGosparse, com as mesmas opções montadas
+//     por gosparse.Compile
+//   - ParseArticleQuery, que valida os valores dos filtros com o tipo dos
+//     campos, e ArticleFilters com um método por filtro
+//   - Article.Project, que devolve os atributos selecionados da estrutura,
+//     projetando as relações com as estruturas geradas
+//
+// Os filtros são acessados com o tipo do campo da estrutura:
+//
+//	q, err := ParseArticleQuery(r.URL.Query())
+//	price, err := q.Filters.Price() // typed.Filter[float64]
+//
+// As estruturas são carregadas com go/types a partir do código fonte,
+// ignorando o arquivo gerado anteriormente. Sem -type, todas as estruturas
+// com ao menos um campo com a tag são geradas.
+package main
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	pathpkg "path"
+	stdsort "sort"
+	"strconv"
+	"strings"
+
+	"github.com/jeanmolossi/gosparse/internal/source"
+)
+
+// model são os dados de uma estrutura utilizados pelo template.
+type model struct {
+	// Name é o nome da estrutura
+	Name string
+	// Resource é o tipo de recurso dos dados primários
+	Resource string
+	// Constants são as constantes dos nomes dos campos
+	Constants []constant
+	// Options são as chamadas das opções que configuram o Gosparse
+	Options []string
+	// Filters são os métodos de acesso tipado aos filtros
+	Filters []accessor
+	// Projections são os atributos projetados da estrutura
+	Projections []projection
+}
+
+// constant é a constante do nome de um campo na querystring
+//
+//	ArticleFieldAuthorName = "author.name"
+type constant struct {
+	Name string
+	Path string
+}
+
+// accessor é o método de acesso tipado ao filtro de um campo
+//
+//	func (f ArticleFilters) Price() (typed.Filter[float64], error)
+type accessor struct {
+	Method   string
+	Constant string
+	Path     string
+	Type     string
+	Parser   string
+}
+
+// projection é a atribuição de um atributo no projetor
+type projection struct {
+	Constant string
+	Code     string
+}
+
+// generator gera o código das estruturas de um pacote.
+type generator struct {
+	pkg *source.Package
+	// generated são as estruturas geradas, as relações para elas são
+	// projetadas com o projetor gerado
+	generated map[*types.Struct]string
+	// imports são os pacotes importados pelo código gerado
+	imports map[string]string
+	// pageSize é o tamanho padrão da página
+	pageSize uint32
+}
+
+// generate devolve o código gerado para as estruturas, formatado.
+func (g *generator) generate(names []string) ([]byte, error) {
+	g.generated = make(map[*types.Struct]string, len(names))
+	g.imports = map[string]string{
+		"net/url":                         "url",
+		"github.com/jeanmolossi/gosparse": "gosparse",
+	}
+
+	structs := make([]*types.Struct, 0, len(names))
+	for _, name := range names {
+		_, st, err := g.pkg.Lookup(name)
+		if err != nil {
+			return nil, err
+		}
+
+		g.generated[st] = name
+		structs = append(structs, st)
+	}
+
+	models := make([]model, 0, len(names))
+	for i, st := range structs {
+		m, err := g.model(names[i], st)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(m.Filters) > 0 {
+			g.imports["github.com/jeanmolossi/gosparse/typed"] = "typed"
+		}
+
+		models = append(models, m)
+	}
+
+	// os pacotes da biblioteca padrão são agrupados antes dos demais
+	var std, others []string
+	for _, path := range sortedKeys(g.imports) {
+		spec := strconv.Quote(path)
+		if name := g.imports[path]; name != pathpkg.Base(path) {
+			spec = name + " " + spec
+		}
+
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			others = append(others, spec)
+			continue
+		}
+
+		std = append(std, spec)
+	}
+
+	imports := std
+	if len(std) > 0 && len(others) > 0 {
+		imports = append(imports, "")
+	}
+
+	imports = append(imports, others...)
+
+	var buf bytes.Buffer
+	err := tmpl.Execute(&buf, map[string]any{
+		"Package": g.pkg.Name(),
+		"Imports": imports,
+		"Models":  models,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("can not format generated code: %w", err)
+	}
+
+	return code, nil
+}
+
+// model monta os dados da estrutura para o template.
+func (g *generator) model(name string, st *types.Struct) (model, error) {
+	schema, err := source.Compile(st)
+	if err != nil {
+		return model{}, fmt.Errorf("can not generate %s: %w", name, err)
+	}
+
+	m := model{Name: name, Resource: schema.Resource}
+
+	for _, path := range schema.Paths {
+		field := schema.Fields[path]
+		m.Constants = append(m.Constants, constant{Name: constName(name, field), Path: path})
+
+		if !field.Filter {
+			continue
+		}
+
+		typ, parser := g.parser(source.Elem(field.Var.Type()))
+		m.Filters = append(m.Filters, accessor{
+			Method:   strings.Join(field.Names, ""),
+			Constant: constName(name, field),
+			Path:     path,
+			Type:     typ,
+			Parser:   parser,
+		})
+	}
+
+	m.Options = options(schema, g.pageSize)
+
+	for i := 0; i < st.NumFields(); i++ {
+		conf := source.Tag(st, i)
+		if conf == nil || !conf.Select {
+			continue
+		}
+
+		field := schema.Fields[conf.Name]
+		code, err := g.projection(field)
+		if err != nil {
+			return model{}, err
+		}
+
+		m.Projections = append(m.Projections, projection{Constant: constName(name, field), Code: code})
+	}
+
+	return m, nil
+}
+
+// constName devolve o nome da constante do campo, com os nomes dos campos
+// Go percorridos:
+//
+//	// comments.author.name
+//	ArticleFieldCommentsAuthorName
+func constName(name string, field source.Field) string {
+	return name + "Field" + strings.Join(field.Names, "")
+}
+
+// options devolve as chamadas das opções que configuram o Gosparse da
+// mesma forma que gosparse.Compile.
+func options(schema *source.Schema, pageSize uint32) []string {
+	opts := []string{fmt.Sprintf("gosparse.TypeName(%q)", schema.Resource)}
+
+	// accept adiciona a opção com os caminhos dos campos que atendem ao
+	// filtro, omitindo as opções sem caminhos
+	accept := func(option string, match func(source.Field) bool) {
+		if paths := schema.Select(match); len(paths) > 0 {
+			opts = append(opts, fmt.Sprintf("gosparse.%s(%s)", option, quote(paths)))
+		}
+	}
+
+	accept("AcceptRelations", func(f source.Field) bool { return f.Relation })
+	accept("AcceptPaths", func(f source.Field) bool { return f.Select })
+
+	for _, typ := range sortedKeys(schema.Fieldsets) {
+		opts = append(opts, fmt.Sprintf("gosparse.AcceptFieldset(%s)", quote(append([]string{typ}, schema.Fieldsets[typ]...))))
+	}
+
+	for _, typ := range sortedKeys(schema.Always) {
+		opts = append(opts, fmt.Sprintf("gosparse.AlwaysFields(%s)", quote(append([]string{typ}, schema.Always[typ]...))))
+	}
+
+	for _, typ := range sortedKeys(schema.Defaults) {
+		opts = append(opts, fmt.Sprintf("gosparse.DefaultFields(%s)", quote(append([]string{typ}, schema.Defaults[typ]...))))
+	}
+
+	for _, alias := range sortedKeys(schema.Aliases) {
+		opts = append(opts, fmt.Sprintf("gosparse.AliasRelation(%q, %q)", alias, schema.Aliases[alias]))
+	}
+
+	accept("AcceptFilters", func(f source.Field) bool { return f.Filter })
+	accept("AcceptSortBy", func(f source.Field) bool { return f.Sort })
+
+	return append(opts, fmt.Sprintf("gosparse.AcceptPagination(%d)", pageSize))
+}
+
+// parser devolve o tipo dos valores do filtro e o conversor do pacote
+// typed correspondente ao tipo do campo. Tipos não suportados são
+// filtrados como texto.
+func (g *generator) parser(t types.Type) (string, string) {
+	typ := types.TypeString(t, g.qualifier)
+
+	if named, ok := t.(*types.Named); ok {
+		if obj := named.Obj(); obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+			return typ, "typed.Time"
+		}
+	}
+
+	if method, _, _ := types.LookupFieldOrMethod(types.NewPointer(t), false, nil, "UnmarshalText"); method != nil {
+		if _, ok := method.(*types.Func); ok {
+			return typ, "typed.Text[" + typ + "]"
+		}
+	}
+
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return "string", "typed.String[string]"
+	}
+
+	switch basic.Kind() {
+	case types.Bool:
+		return typ, "typed.Bool[" + typ + "]"
+	case types.Int, types.Int8, types.Int16, types.Int32, types.Int64:
+		return typ, "typed.Int[" + typ + "]"
+	case types.Uint, types.Uint8, types.Uint16, types.Uint32, types.Uint64:
+		return typ, "typed.Uint[" + typ + "]"
+	case types.Float32, types.Float64:
+		return typ, "typed.Float[" + typ + "]"
+	case types.String:
+		return typ, "typed.String[" + typ + "]"
+	}
+
+	return "string", "typed.String[string]"
+}
+
+// projection devolve o código que atribui o valor do campo no projetor.
+//
+// As relações para as estruturas geradas (valor, referência ou lista) são
+// projetadas com a seleção do tipo de recurso da relação, as demais são
+// atribuídas sem alteração.
+func (g *generator) projection(field source.Field) (string, error) {
+	value := "item." + field.Var.Name()
+	raw := "projected[attr] = " + value
+
+	if !field.Relation {
+		return raw, nil
+	}
+
+	rel, err := source.Relation(field.Var)
+	if err != nil {
+		return "", err
+	}
+
+	if _, generated := g.generated[rel]; !generated {
+		return raw, nil
+	}
+
+	project := ".project(q, " + strconv.Quote(source.RelationResource(field.Field, rel)) + ")"
+
+	t := field.Var.Type()
+	if ptr, ok := t.(*types.Pointer); ok {
+		if _, ok := ptr.Elem().Underlying().(*types.Struct); !ok {
+			return raw, nil
+		}
+
+		return "projected[attr] = nil\n" +
+			"if " + value + " != nil {\n" +
+			"projected[attr] = " + value + project + "\n" +
+			"}", nil
+	}
+
+	var elem types.Type
+	switch list := t.Underlying().(type) {
+	case *types.Struct:
+		return "projected[attr] = " + value + project, nil
+	case *types.Slice:
+		elem = list.Elem()
+	case *types.Array:
+		elem = list.Elem()
+	default:
+		return raw, nil
+	}
+
+	item := "related = append(related, rel" + project + ")\n"
+	if _, ptr := elem.(*types.Pointer); ptr {
+		item = "if rel == nil {\n" +
+			"related = append(related, nil)\n" +
+			"continue\n" +
+			"}\n\n" + item
+	}
+
+	return "related := make([]map[string]any, 0, len(" + value + "))\n" +
+		"for _, rel := range " + value + " {\n" + item + "}\n\n" +
+		"projected[attr] = related", nil
+}
+
+// qualifier devolve o nome do pacote dos tipos de outros pacotes e
+// registra o import.
+func (g *generator) qualifier(pkg *types.Package) string {
+	if pkg == g.pkg.Package {
+		return ""
+	}
+
+	g.imports[pkg.Path()] = pkg.Name()
+	return pkg.Name()
+}
+
+// quote devolve os valores entre aspas separados por vírgula.
+func quote(values []string) string {
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, strconv.Quote(v))
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// sortedKeys devolve as chaves do map em ordem alfabética.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	stdsort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jeanmolossi/gosparse/internal/source"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update the generated example")
+
+const example = "./internal/example"
+
+// TestGenerate compara o código gerado com o código do pacote de exemplo,
+// que é compilado e testado. Atualizado com "go test ./cmd/gosparse-gen -update".
+func TestGenerate(t *testing.T) {
+	path := filepath.Join("internal", "example", OUTPUT)
+
+	if *update {
+		require.Equal(t, 0, run([]string{"-page-size", "25", example}, os.Stderr))
+	}
+
+	pkg, err := source.Load(".", example, OUTPUT)
+	require.Nil(t, err)
+
+	g := &generator{pkg: pkg, pageSize: 25}
+	code, err := g.generate(pkg.Tagged())
+	require.Nil(t, err)
+
+	want, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, string(want), string(code))
+}
+
+func TestRun(t *testing.T) {
+	testtable := []struct {
+		desc string
+		args []string
+		code int
+		err  string
+	}{
+		{
+			desc: "should fail with unknown types",
+			args: []string{"-type", "Missing", example},
+			code: 1,
+			err:  "gosparse-gen: type Missing not found in github.com/jeanmolossi/gosparse/cmd/gosparse-gen/internal/example\n",
+		},
+		{
+			desc: "should fail with non struct types",
+			args: []string{"-type", "Status", example},
+			code: 1,
+			err:  "gosparse-gen: type Status should be a struct\n",
+		},
+		{
+			desc: "should fail with unknown packages",
+			args: []string{"./internal/missing"},
+			code: 1,
+		},
+		{
+			desc: "should fail with more than one package",
+			args: []string{example, example},
+			code: 2,
+		},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			var stderr bytes.Buffer
+
+			require.Equal(t, tc.code, run(tc.args, &stderr))
+			if tc.err != "" {
+				require.Equal(t, tc.err, stderr.String())
+			}
+		})
+	}
+}
+
+func TestRunOutput(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "articles_gen.go")
+
+	require.Equal(t, 0, run([]string{"-type", "Author", "-output", output, example}, os.Stderr))
+
+	code, err := os.ReadFile(output)
+	require.Nil(t, err)
+	require.Contains(t, string(code), "func NewAuthorGosparse(")
+	require.NotContains(t, string(code), "Article")
+}
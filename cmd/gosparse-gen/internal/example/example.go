@@ -0,0 +1,52 @@
+// Package example contém estruturas utilizadas para validar o código gerado
+// pelo comando gosparse-gen.
+package example
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+//go:generate go run github.com/jeanmolossi/gosparse/cmd/gosparse-gen -page-size 25
+
+type Status string
+
+// Slug é um texto normalizado na conversão
+type Slug string
+
+func (s *Slug) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return fmt.Errorf("empty slug")
+	}
+
+	*s = Slug(strings.ToLower(string(text)))
+	return nil
+}
+
+type Article struct {
+	_         struct{}   `gosparse:"type:articles"`
+	ID        uint64     `gosparse:"name:id;always;filter"`
+	Title     string     `gosparse:"name:title;select;sort;filter;default"`
+	Slug      Slug       `gosparse:"name:slug;select;filter"`
+	Status    Status     `gosparse:"name:status;select;filter"`
+	Price     float64    `gosparse:"name:price;select;sort;filter"`
+	Stock     int32      `gosparse:"name:stock;filter"`
+	Featured  bool       `gosparse:"name:featured;select;filter"`
+	CreatedAt *time.Time `gosparse:"name:created_at;select;sort;filter"`
+	Author    *Author    `gosparse:"name:author;relation;alias:writer"`
+	Editor    Author     `gosparse:"name:editor;relation;type:people"`
+	Comments  []*Comment `gosparse:"name:comments;relation"`
+	Internal  string
+}
+
+type Author struct {
+	_    struct{} `gosparse:"type:people"`
+	Name string   `gosparse:"name:name;select;filter"`
+}
+
+type Comment struct {
+	_       struct{} `gosparse:"type:comments"`
+	Body    string   `gosparse:"name:body;select"`
+	Authors []Author `gosparse:"name:authors;relation"`
+}
@@ -0,0 +1,165 @@
+package example
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/typed"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedGosparse(t *testing.T) {
+	compiled, err := gosparse.Compile(Article{}, gosparse.AcceptPagination(25))
+	require.Nil(t, err)
+
+	queries := []url.Values{
+		{},
+		{"include": {"writer,comments.authors"}, "fields[articles]": {"title,author"}},
+		{"fields[people]": {"name"}, "fields[comments]": {"body"}},
+		{"filter[price_gte]": {"10"}, "filter[author.name]": {"Ana"}, "sort": {"-created_at,title"}},
+		{"page[number]": {"2"}, "page[size]": {"5"}},
+		{"filter[internal]": {"x"}},
+		{"sort": {"stock"}},
+		{"include": {"bogus"}},
+	}
+
+	for _, query := range queries {
+		expect, expectErr := compiled.Gosparse().Parse(query)
+		got, err := ArticleGosparse.Parse(query)
+
+		if expectErr != nil {
+			require.EqualError(t, err, expectErr.Error(), query.Encode())
+			continue
+		}
+
+		require.Nil(t, err, query.Encode())
+		require.Equal(t, expect.String(), got.String(), query.Encode())
+		require.Equal(t, expect.Fields, got.Fields, query.Encode())
+	}
+}
+
+func TestArticleFilters(t *testing.T) {
+	q, err := ParseArticleQuery(url.Values{
+		"filter[price_gte]":     {"10.5"},
+		"filter[stock_in]":      {"1,2"},
+		"filter[status]":        {"open"},
+		"filter[slug]":          {"Go-Generics"},
+		"filter[created_at_lt]": {"2024-01-02"},
+		"filter[featured_null]": {"false"},
+	})
+	require.Nil(t, err)
+
+	price, err := q.Filters.Price()
+	require.Nil(t, err)
+	require.Equal(t, typed.Filter[float64]{Present: true, Predicate: gosparse.GTE, Values: []float64{10.5}}, price)
+
+	stock, err := q.Filters.Stock()
+	require.Nil(t, err)
+	require.Equal(t, []int32{1, 2}, stock.Values)
+
+	status, err := q.Filters.Status()
+	require.Nil(t, err)
+	require.Equal(t, []Status{"open"}, status.Values)
+
+	slug, err := q.Filters.Slug()
+	require.Nil(t, err)
+	require.Equal(t, []Slug{"go-generics"}, slug.Values)
+
+	createdAt, err := q.Filters.CreatedAt()
+	require.Nil(t, err)
+	require.Equal(t, []time.Time{time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}, createdAt.Values)
+
+	featured, err := q.Filters.Featured()
+	require.Nil(t, err)
+	require.Equal(t, typed.Filter[bool]{Present: true, Predicate: gosparse.NULL, Flag: false}, featured)
+
+	title, err := q.Filters.Title()
+	require.Nil(t, err)
+	require.False(t, title.Present)
+}
+
+func TestParseArticleQueryInvalid(t *testing.T) {
+	testtable := []struct {
+		desc  string
+		query url.Values
+		err   string
+	}{
+		{
+			desc:  "should reject values of another type",
+			query: url.Values{"filter[price_gte]": {"cheap"}},
+			err:   "invalid filter price: value cheap is not a valid float64",
+		},
+		{
+			desc:  "should reject values out of range",
+			query: url.Values{"filter[stock]": {"3000000000"}},
+			err:   "invalid filter stock: value 3000000000 is not a valid int32",
+		},
+		{
+			desc:  "should reject invalid text values",
+			query: url.Values{"filter[slug]": {""}},
+			err:   "invalid filter slug: value  is not a valid example.Slug",
+		},
+		{
+			desc:  "should reject unknown fields",
+			query: url.Values{"filter[internal]": {"x"}},
+			err:   "unsupported filter resource: internal",
+		},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := ParseArticleQuery(tc.query)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestArticleProject(t *testing.T) {
+	article := Article{
+		ID:       7,
+		Title:    "Generics",
+		Price:    10,
+		Author:   &Author{Name: "Ana"},
+		Editor:   Author{Name: "Bia"},
+		Comments: []*Comment{{Body: "Nice", Authors: []Author{{Name: "Caio"}}}, nil},
+		Internal: "secret",
+	}
+
+	t.Run("should project the selected fields", func(t *testing.T) {
+		q, err := ParseArticleQuery(url.Values{
+			"fields[articles]": {"title,author,comments"},
+			"fields[comments]": {"authors"},
+		})
+		require.Nil(t, err)
+
+		require.Equal(t, map[string]any{
+			"id":     uint64(7),
+			"title":  "Generics",
+			"author": map[string]any{"name": "Ana"},
+			"comments": []map[string]any{
+				{"authors": []map[string]any{{"name": "Caio"}}},
+				nil,
+			},
+		}, article.Project(q.Query))
+	})
+
+	t.Run("should project the default fields", func(t *testing.T) {
+		q, err := ParseArticleQuery(url.Values{})
+		require.Nil(t, err)
+
+		require.Equal(t, map[string]any{"id": uint64(7), "title": "Generics"}, article.Project(q.Query))
+	})
+
+	t.Run("should project nil relations", func(t *testing.T) {
+		q, err := ParseArticleQuery(url.Values{"fields[articles]": {"author,editor"}})
+		require.Nil(t, err)
+
+		require.Equal(t, map[string]any{
+			"id":     uint64(7),
+			"author": nil,
+			"editor": map[string]any{"name": "Bia"},
+		}, Article{ID: 7, Editor: Author{Name: "Bia"}}.Project(q.Query))
+	})
+}
@@ -0,0 +1,462 @@
+// Code generated by gosparse-gen. DO NOT EDIT.
+
+package example
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/typed"
+)
+
+// ArticleResource é o tipo de recurso de Article
+const ArticleResource = "articles"
+
+// Campos de Article aceitos na querystring
+const (
+	ArticleFieldAuthor              = "author"
+	ArticleFieldAuthorName          = "author.name"
+	ArticleFieldComments            = "comments"
+	ArticleFieldCommentsAuthors     = "comments.authors"
+	ArticleFieldCommentsAuthorsName = "comments.authors.name"
+	ArticleFieldCommentsBody        = "comments.body"
+	ArticleFieldCreatedAt           = "created_at"
+	ArticleFieldEditor              = "editor"
+	ArticleFieldEditorName          = "editor.name"
+	ArticleFieldFeatured            = "featured"
+	ArticleFieldID                  = "id"
+	ArticleFieldPrice               = "price"
+	ArticleFieldSlug                = "slug"
+	ArticleFieldStatus              = "status"
+	ArticleFieldStock               = "stock"
+	ArticleFieldTitle               = "title"
+)
+
+// NewArticleGosparse devolve a configuração de parâmetros aceitos de
+// Article, equivalente a gosparse.Compile, porém montada sem reflexão. As
+// opções recebidas são aplicadas após a configuração gerada.
+func NewArticleGosparse(options ...gosparse.GosparseOpt) gosparse.Gosparse {
+	return gosparse.New(append([]gosparse.GosparseOpt{
+		gosparse.TypeName("articles"),
+		gosparse.AcceptRelations("author", "comments", "comments.authors", "editor"),
+		gosparse.AcceptPaths("author", "author.name", "comments", "comments.authors", "comments.authors.name", "comments.body", "created_at", "editor", "editor.name", "featured", "id", "price", "slug", "status", "title"),
+		gosparse.AcceptFieldset("articles", "id", "title", "slug", "status", "price", "featured", "created_at", "author", "editor", "comments"),
+		gosparse.AcceptFieldset("comments", "body", "authors"),
+		gosparse.AcceptFieldset("people", "name"),
+		gosparse.AlwaysFields("articles", "id"),
+		gosparse.DefaultFields("articles", "title"),
+		gosparse.AliasRelation("writer", "author"),
+		gosparse.AcceptFilters("author.name", "comments.authors.name", "created_at", "editor.name", "featured", "id", "price", "slug", "status", "stock", "title"),
+		gosparse.AcceptSortBy("created_at", "price", "title"),
+		gosparse.AcceptPagination(25),
+	}, options...)...)
+}
+
+// ArticleGosparse é a configuração gerada de Article, utilizada por
+// ParseArticleQuery.
+var ArticleGosparse = NewArticleGosparse()
+
+// ArticleQuery é o gosparse.Query de Article com os filtros tipados.
+type ArticleQuery struct {
+	*gosparse.Query
+	// Filters são os filtros com os valores convertidos para o tipo dos
+	// campos
+	Filters ArticleFilters
+}
+
+// ParseArticleQuery extraí a querystring com ArticleGosparse e valida
+// os valores de todos os filtros contra o tipo dos campos.
+func ParseArticleQuery(query url.Values) (*ArticleQuery, error) {
+	q, err := ArticleGosparse.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewArticleQuery(q)
+}
+
+// NewArticleQuery recebe o Query extraído por uma configuração de
+// Article e valida os valores de todos os filtros.
+func NewArticleQuery(q *gosparse.Query) (*ArticleQuery, error) {
+	result := &ArticleQuery{Query: q, Filters: ArticleFilters{query: q}}
+	if err := result.Filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ArticleFilters dá acesso aos filtros de Article com os valores
+// convertidos para o tipo de cada campo.
+type ArticleFilters struct {
+	query *gosparse.Query
+}
+
+// AuthorName devolve o filtro de "author.name" com os valores convertidos.
+func (f ArticleFilters) AuthorName() (typed.Filter[string], error) {
+	return typed.Get(f.query, ArticleFieldAuthorName, typed.String[string])
+}
+
+// CommentsAuthorsName devolve o filtro de "comments.authors.name" com os valores convertidos.
+func (f ArticleFilters) CommentsAuthorsName() (typed.Filter[string], error) {
+	return typed.Get(f.query, ArticleFieldCommentsAuthorsName, typed.String[string])
+}
+
+// CreatedAt devolve o filtro de "created_at" com os valores convertidos.
+func (f ArticleFilters) CreatedAt() (typed.Filter[time.Time], error) {
+	return typed.Get(f.query, ArticleFieldCreatedAt, typed.Time)
+}
+
+// EditorName devolve o filtro de "editor.name" com os valores convertidos.
+func (f ArticleFilters) EditorName() (typed.Filter[string], error) {
+	return typed.Get(f.query, ArticleFieldEditorName, typed.String[string])
+}
+
+// Featured devolve o filtro de "featured" com os valores convertidos.
+func (f ArticleFilters) Featured() (typed.Filter[bool], error) {
+	return typed.Get(f.query, ArticleFieldFeatured, typed.Bool[bool])
+}
+
+// ID devolve o filtro de "id" com os valores convertidos.
+func (f ArticleFilters) ID() (typed.Filter[uint64], error) {
+	return typed.Get(f.query, ArticleFieldID, typed.Uint[uint64])
+}
+
+// Price devolve o filtro de "price" com os valores convertidos.
+func (f ArticleFilters) Price() (typed.Filter[float64], error) {
+	return typed.Get(f.query, ArticleFieldPrice, typed.Float[float64])
+}
+
+// Slug devolve o filtro de "slug" com os valores convertidos.
+func (f ArticleFilters) Slug() (typed.Filter[Slug], error) {
+	return typed.Get(f.query, ArticleFieldSlug, typed.Text[Slug])
+}
+
+// Status devolve o filtro de "status" com os valores convertidos.
+func (f ArticleFilters) Status() (typed.Filter[Status], error) {
+	return typed.Get(f.query, ArticleFieldStatus, typed.String[Status])
+}
+
+// Stock devolve o filtro de "stock" com os valores convertidos.
+func (f ArticleFilters) Stock() (typed.Filter[int32], error) {
+	return typed.Get(f.query, ArticleFieldStock, typed.Int[int32])
+}
+
+// Title devolve o filtro de "title" com os valores convertidos.
+func (f ArticleFilters) Title() (typed.Filter[string], error) {
+	return typed.Get(f.query, ArticleFieldTitle, typed.String[string])
+}
+
+// Validate converte os valores de todos os filtros e devolve o primeiro
+// erro encontrado.
+func (f ArticleFilters) Validate() error {
+	if _, err := f.AuthorName(); err != nil {
+		return err
+	}
+
+	if _, err := f.CommentsAuthorsName(); err != nil {
+		return err
+	}
+
+	if _, err := f.CreatedAt(); err != nil {
+		return err
+	}
+
+	if _, err := f.EditorName(); err != nil {
+		return err
+	}
+
+	if _, err := f.Featured(); err != nil {
+		return err
+	}
+
+	if _, err := f.ID(); err != nil {
+		return err
+	}
+
+	if _, err := f.Price(); err != nil {
+		return err
+	}
+
+	if _, err := f.Slug(); err != nil {
+		return err
+	}
+
+	if _, err := f.Status(); err != nil {
+		return err
+	}
+
+	if _, err := f.Stock(); err != nil {
+		return err
+	}
+
+	if _, err := f.Title(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Project devolve os atributos de Article selecionados no Query para o
+// tipo de recurso ArticleResource, sem reflexão. As relações geradas são
+// projetadas com a seleção do tipo de recurso de cada relação.
+func (item Article) Project(q *gosparse.Query) map[string]any {
+	return item.project(q, ArticleResource)
+}
+
+// project devolve os atributos de Article selecionados para o tipo de
+// recurso.
+func (item Article) project(q *gosparse.Query, typ string) map[string]any {
+	attrs := q.Select(typ)
+	projected := make(map[string]any, len(attrs))
+
+	for _, attr := range attrs {
+		switch attr {
+		case ArticleFieldID:
+			projected[attr] = item.ID
+		case ArticleFieldTitle:
+			projected[attr] = item.Title
+		case ArticleFieldSlug:
+			projected[attr] = item.Slug
+		case ArticleFieldStatus:
+			projected[attr] = item.Status
+		case ArticleFieldPrice:
+			projected[attr] = item.Price
+		case ArticleFieldFeatured:
+			projected[attr] = item.Featured
+		case ArticleFieldCreatedAt:
+			projected[attr] = item.CreatedAt
+		case ArticleFieldAuthor:
+			projected[attr] = nil
+			if item.Author != nil {
+				projected[attr] = item.Author.project(q, "people")
+			}
+		case ArticleFieldEditor:
+			projected[attr] = item.Editor.project(q, "people")
+		case ArticleFieldComments:
+			related := make([]map[string]any, 0, len(item.Comments))
+			for _, rel := range item.Comments {
+				if rel == nil {
+					related = append(related, nil)
+					continue
+				}
+
+				related = append(related, rel.project(q, "comments"))
+			}
+
+			projected[attr] = related
+		}
+	}
+
+	return projected
+}
+
+// AuthorResource é o tipo de recurso de Author
+const AuthorResource = "people"
+
+// Campos de Author aceitos na querystring
+const (
+	AuthorFieldName = "name"
+)
+
+// NewAuthorGosparse devolve a configuração de parâmetros aceitos de
+// Author, equivalente a gosparse.Compile, porém montada sem reflexão. As
+// opções recebidas são aplicadas após a configuração gerada.
+func NewAuthorGosparse(options ...gosparse.GosparseOpt) gosparse.Gosparse {
+	return gosparse.New(append([]gosparse.GosparseOpt{
+		gosparse.TypeName("people"),
+		gosparse.AcceptPaths("name"),
+		gosparse.AcceptFieldset("people", "name"),
+		gosparse.AcceptFilters("name"),
+		gosparse.AcceptPagination(25),
+	}, options...)...)
+}
+
+// AuthorGosparse é a configuração gerada de Author, utilizada por
+// ParseAuthorQuery.
+var AuthorGosparse = NewAuthorGosparse()
+
+// AuthorQuery é o gosparse.Query de Author com os filtros tipados.
+type AuthorQuery struct {
+	*gosparse.Query
+	// Filters são os filtros com os valores convertidos para o tipo dos
+	// campos
+	Filters AuthorFilters
+}
+
+// ParseAuthorQuery extraí a querystring com AuthorGosparse e valida
+// os valores de todos os filtros contra o tipo dos campos.
+func ParseAuthorQuery(query url.Values) (*AuthorQuery, error) {
+	q, err := AuthorGosparse.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewAuthorQuery(q)
+}
+
+// NewAuthorQuery recebe o Query extraído por uma configuração de
+// Author e valida os valores de todos os filtros.
+func NewAuthorQuery(q *gosparse.Query) (*AuthorQuery, error) {
+	result := &AuthorQuery{Query: q, Filters: AuthorFilters{query: q}}
+	if err := result.Filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// AuthorFilters dá acesso aos filtros de Author com os valores
+// convertidos para o tipo de cada campo.
+type AuthorFilters struct {
+	query *gosparse.Query
+}
+
+// Name devolve o filtro de "name" com os valores convertidos.
+func (f AuthorFilters) Name() (typed.Filter[string], error) {
+	return typed.Get(f.query, AuthorFieldName, typed.String[string])
+}
+
+// Validate converte os valores de todos os filtros e devolve o primeiro
+// erro encontrado.
+func (f AuthorFilters) Validate() error {
+	if _, err := f.Name(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Project devolve os atributos de Author selecionados no Query para o
+// tipo de recurso AuthorResource, sem reflexão. As relações geradas são
+// projetadas com a seleção do tipo de recurso de cada relação.
+func (item Author) Project(q *gosparse.Query) map[string]any {
+	return item.project(q, AuthorResource)
+}
+
+// project devolve os atributos de Author selecionados para o tipo de
+// recurso.
+func (item Author) project(q *gosparse.Query, typ string) map[string]any {
+	attrs := q.Select(typ)
+	projected := make(map[string]any, len(attrs))
+
+	for _, attr := range attrs {
+		switch attr {
+		case AuthorFieldName:
+			projected[attr] = item.Name
+		}
+	}
+
+	return projected
+}
+
+// CommentResource é o tipo de recurso de Comment
+const CommentResource = "comments"
+
+// Campos de Comment aceitos na querystring
+const (
+	CommentFieldAuthors     = "authors"
+	CommentFieldAuthorsName = "authors.name"
+	CommentFieldBody        = "body"
+)
+
+// NewCommentGosparse devolve a configuração de parâmetros aceitos de
+// Comment, equivalente a gosparse.Compile, porém montada sem reflexão. As
+// opções recebidas são aplicadas após a configuração gerada.
+func NewCommentGosparse(options ...gosparse.GosparseOpt) gosparse.Gosparse {
+	return gosparse.New(append([]gosparse.GosparseOpt{
+		gosparse.TypeName("comments"),
+		gosparse.AcceptRelations("authors"),
+		gosparse.AcceptPaths("authors", "authors.name", "body"),
+		gosparse.AcceptFieldset("comments", "body", "authors"),
+		gosparse.AcceptFieldset("people", "name"),
+		gosparse.AcceptFilters("authors.name"),
+		gosparse.AcceptPagination(25),
+	}, options...)...)
+}
+
+// CommentGosparse é a configuração gerada de Comment, utilizada por
+// ParseCommentQuery.
+var CommentGosparse = NewCommentGosparse()
+
+// CommentQuery é o gosparse.Query de Comment com os filtros tipados.
+type CommentQuery struct {
+	*gosparse.Query
+	// Filters são os filtros com os valores convertidos para o tipo dos
+	// campos
+	Filters CommentFilters
+}
+
+// ParseCommentQuery extraí a querystring com CommentGosparse e valida
+// os valores de todos os filtros contra o tipo dos campos.
+func ParseCommentQuery(query url.Values) (*CommentQuery, error) {
+	q, err := CommentGosparse.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCommentQuery(q)
+}
+
+// NewCommentQuery recebe o Query extraído por uma configuração de
+// Comment e valida os valores de todos os filtros.
+func NewCommentQuery(q *gosparse.Query) (*CommentQuery, error) {
+	result := &CommentQuery{Query: q, Filters: CommentFilters{query: q}}
+	if err := result.Filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// CommentFilters dá acesso aos filtros de Comment com os valores
+// convertidos para o tipo de cada campo.
+type CommentFilters struct {
+	query *gosparse.Query
+}
+
+// AuthorsName devolve o filtro de "authors.name" com os valores convertidos.
+func (f CommentFilters) AuthorsName() (typed.Filter[string], error) {
+	return typed.Get(f.query, CommentFieldAuthorsName, typed.String[string])
+}
+
+// Validate converte os valores de todos os filtros e devolve o primeiro
+// erro encontrado.
+func (f CommentFilters) Validate() error {
+	if _, err := f.AuthorsName(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Project devolve os atributos de Comment selecionados no Query para o
+// tipo de recurso CommentResource, sem reflexão. As relações geradas são
+// projetadas com a seleção do tipo de recurso de cada relação.
+func (item Comment) Project(q *gosparse.Query) map[string]any {
+	return item.project(q, CommentResource)
+}
+
+// project devolve os atributos de Comment selecionados para o tipo de
+// recurso.
+func (item Comment) project(q *gosparse.Query, typ string) map[string]any {
+	attrs := q.Select(typ)
+	projected := make(map[string]any, len(attrs))
+
+	for _, attr := range attrs {
+		switch attr {
+		case CommentFieldBody:
+			projected[attr] = item.Body
+		case CommentFieldAuthors:
+			related := make([]map[string]any, 0, len(item.Authors))
+			for _, rel := range item.Authors {
+				related = append(related, rel.project(q, "people"))
+			}
+
+			projected[attr] = related
+		}
+	}
+
+	return projected
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jeanmolossi/gosparse/internal/source"
+)
+
+// OUTPUT é o nome padrão do arquivo gerado no diretório do pacote
+const OUTPUT = "gosparse_gen.go"
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
+
+// run executa o comando com os argumentos recebidos e devolve o código de
+// saída.
+func run(args []string, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gosparse-gen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gosparse-gen [-type NAMES] [-output FILE] [-page-size N] [PACKAGE]")
+		flags.PrintDefaults()
+	}
+
+	typeNames := flags.String("type", "", "comma separated struct names, defaults to every struct with gosparse tags")
+	output := flags.String("output", "", "generated file, defaults to "+OUTPUT+" in the package directory")
+	pageSize := flags.Uint("page-size", 10, "default page size")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() > 1 {
+		flags.Usage()
+		return 2
+	}
+
+	pattern := "."
+	if flags.NArg() == 1 {
+		pattern = flags.Arg(0)
+	}
+
+	if err := generateFile(pattern, *typeNames, *output, uint32(*pageSize)); err != nil {
+		fmt.Fprintf(stderr, "gosparse-gen: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// generateFile gera o código das estruturas do pacote e escreve no arquivo
+// de saída.
+func generateFile(pattern, typeNames, output string, pageSize uint32) error {
+	name := OUTPUT
+	if output != "" {
+		name = filepath.Base(output)
+	}
+
+	// o código gerado anteriormente é ignorado, pois pode estar
+	// desatualizado em relação às estruturas
+	pkg, err := source.Load(".", pattern, name)
+	if err != nil {
+		return err
+	}
+
+	names := pkg.Tagged()
+	if typeNames != "" {
+		names = strings.Split(typeNames, ",")
+	}
+
+	if len(names) == 0 {
+		return fmt.Errorf("no struct with gosparse tags in %s", pkg.Path())
+	}
+
+	g := &generator{pkg: pkg, pageSize: pageSize}
+
+	code, err := g.generate(names)
+	if err != nil {
+		return err
+	}
+
+	if output == "" {
+		output = filepath.Join(pkg.Dir, OUTPUT)
+	}
+
+	return os.WriteFile(output, code, 0o644)
+}
@@ -0,0 +1,117 @@
+package main
+
+import "text/template"
+
+// tmpl é o template do código gerado para as estruturas de um pacote.
+var tmpl = template.Must(template.New("gosparse").Parse(`// Code generated by gosparse-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+)
+{{range .Models}}{{$m := .}}
+// {{.Name}}Resource é o tipo de recurso de {{.Name}}
+const {{.Name}}Resource = "{{.Resource}}"
+
+// Campos de {{.Name}} aceitos na querystring
+const (
+{{- range .Constants}}
+	{{.Name}} = "{{.Path}}"
+{{- end}}
+)
+
+// New{{.Name}}Gosparse devolve a configuração de parâmetros aceitos de
+// {{.Name}}, equivalente a gosparse.Compile, porém montada sem reflexão. As
+// opções recebidas são aplicadas após a configuração gerada.
+func New{{.Name}}Gosparse(options ...gosparse.GosparseOpt) gosparse.Gosparse {
+	return gosparse.New(append([]gosparse.GosparseOpt{
+	{{- range .Options}}
+		{{.}},
+	{{- end}}
+	}, options...)...)
+}
+
+// {{.Name}}Gosparse é a configuração gerada de {{.Name}}, utilizada por
+// Parse{{.Name}}Query.
+var {{.Name}}Gosparse = New{{.Name}}Gosparse()
+
+// {{.Name}}Query é o gosparse.Query de {{.Name}} com os filtros tipados.
+type {{.Name}}Query struct {
+	*gosparse.Query
+	// Filters são os filtros com os valores convertidos para o tipo dos
+	// campos
+	Filters {{.Name}}Filters
+}
+
+// Parse{{.Name}}Query extraí a querystring com {{.Name}}Gosparse e valida
+// os valores de todos os filtros contra o tipo dos campos.
+func Parse{{.Name}}Query(query url.Values) (*{{.Name}}Query, error) {
+	q, err := {{.Name}}Gosparse.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+
+	return New{{.Name}}Query(q)
+}
+
+// New{{.Name}}Query recebe o Query extraído por uma configuração de
+// {{.Name}} e valida os valores de todos os filtros.
+func New{{.Name}}Query(q *gosparse.Query) (*{{.Name}}Query, error) {
+	result := &{{.Name}}Query{Query: q, Filters: {{.Name}}Filters{query: q}}
+	if err := result.Filters.Validate(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// {{.Name}}Filters dá acesso aos filtros de {{.Name}} com os valores
+// convertidos para o tipo de cada campo.
+type {{.Name}}Filters struct {
+	query *gosparse.Query
+}
+{{range .Filters}}
+// {{.Method}} devolve o filtro de "{{.Path}}" com os valores convertidos.
+func (f {{$m.Name}}Filters) {{.Method}}() (typed.Filter[{{.Type}}], error) {
+	return typed.Get(f.query, {{.Constant}}, {{.Parser}})
+}
+{{end}}
+// Validate converte os valores de todos os filtros e devolve o primeiro
+// erro encontrado.
+func (f {{.Name}}Filters) Validate() error {
+{{- range .Filters}}
+	if _, err := f.{{.Method}}(); err != nil {
+		return err
+	}
+{{end}}
+	return nil
+}
+
+// Project devolve os atributos de {{.Name}} selecionados no Query para o
+// tipo de recurso {{.Name}}Resource, sem reflexão. As relações geradas são
+// projetadas com a seleção do tipo de recurso de cada relação.
+func (item {{.Name}}) Project(q *gosparse.Query) map[string]any {
+	return item.project(q, {{.Name}}Resource)
+}
+
+// project devolve os atributos de {{.Name}} selecionados para o tipo de
+// recurso.
+func (item {{.Name}}) project(q *gosparse.Query, typ string) map[string]any {
+	attrs := q.Select(typ)
+	projected := make(map[string]any, len(attrs))
+
+	for _, attr := range attrs {
+		switch attr {
+		{{- range .Projections}}
+		case {{.Constant}}:
+			{{.Code}}
+		{{- end}}
+		}
+	}
+
+	return projected
+}
+{{end}}`))
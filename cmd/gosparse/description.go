@@ -0,0 +1,192 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
+	"gopkg.in/yaml.v3"
+)
+
+// description é a descrição dos parâmetros aceitos por um schema, lida de
+// um arquivo JSON ou YAML (veja readDescription) ou montada a partir das
+// tags de uma estrutura Go (veja loadPackage):
+//
+//	type: articles
+//	relations: [author, comments, comments.author]
+//	aliases:
+//	  commentAuthors: comments.author
+//	fields:
+//	  articles: [title, body, author, comments]
+//	  people: [name]
+//	filters: [title, price]
+//	filter_types:
+//	  price: number
+//	filter_expressions: true
+//	sort: [title, created_at]
+//	page_size: 25
+//	max_page_size: 100
+type description struct {
+	// Type é o tipo de recurso dos dados primários
+	Type string `yaml:"type"`
+	// Relations são os caminhos aceitos no parâmetro "include"
+	Relations []string `yaml:"relations"`
+	// Aliases são os nomes alternativos dos caminhos de relacionamento
+	Aliases map[string]string `yaml:"aliases"`
+	// Fields são os atributos aceitos para cada tipo de recurso no
+	// parâmetro "fields[TYPE]"
+	Fields map[string][]string `yaml:"fields"`
+	// Always são os atributos sempre retornados de cada tipo de recurso
+	Always map[string][]string `yaml:"always"`
+	// Defaults são os atributos da seleção padrão de cada tipo de recurso
+	Defaults map[string][]string `yaml:"defaults"`
+	// NestedFields habilita a seleção aninhada com os caminhos de Paths
+	// (veja gosparse.NestedFields)
+	NestedFields bool `yaml:"nested_fields"`
+	// Paths são os caminhos aceitos na seleção aninhada
+	//
+	//	author.name
+	Paths []string `yaml:"paths"`
+	// Filters são os campos aceitos no parâmetro "filter"
+	Filters []string `yaml:"filters"`
+	// FilterTypes são os tipos dos campos de Filters, que restringem os
+	// predicados aceitos: "string" (padrão), "integer", "number",
+	// "boolean" ou "date-time"
+	FilterTypes map[string]string `yaml:"filter_types"`
+	// FilterExpressions habilita as expressões AIP-160 no parâmetro
+	// "filter" (veja gosparse.FilterExpressions)
+	FilterExpressions bool `yaml:"filter_expressions"`
+	// Sort são os campos aceitos no parâmetro "sort"
+	Sort []string `yaml:"sort"`
+	// PageSize é o tamanho padrão da página. Zero utiliza o padrão do
+	// parâmetro "page".
+	PageSize uint32 `yaml:"page_size"`
+	// MaxPageSize limita o tamanho solicitado em page[size]. Zero indica
+	// que não há limite.
+	MaxPageSize uint32 `yaml:"max_page_size"`
+	// MaxIncludeDepth limita a profundidade dos caminhos do "include"
+	MaxIncludeDepth int `yaml:"max_include_depth"`
+	// MaxIncludePaths limita a quantidade de caminhos do "include"
+	MaxIncludePaths int `yaml:"max_include_paths"`
+}
+
+// readDescription lê a descrição do arquivo. Como JSON é um subconjunto de
+// YAML, os dois formatos são lidos da mesma forma.
+//
+// Chaves desconhecidas são rejeitadas, portanto um erro de digitação não
+// é ignorado silenciosamente. Um arquivo vazio é uma descrição vazia.
+func readDescription(path string) (*description, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	d := &description{}
+	if err := decoder.Decode(d); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid schema %s: %w", path, err)
+	}
+
+	for field, kind := range d.FilterTypes {
+		if _, ok := kinds[filter.Kind(kind)]; !ok {
+			return nil, fmt.Errorf("invalid schema %s: unknown type %s of filter %s", path, kind, field)
+		}
+	}
+
+	return d, nil
+}
+
+// kinds são os tipos aceitos em filter_types, com um tipo Go
+// representativo de cada um (veja filter.KindOf).
+var kinds = map[filter.Kind]reflect.Type{
+	filter.STRING:    reflect.TypeOf(""),
+	filter.INTEGER:   reflect.TypeOf(int64(0)),
+	filter.NUMBER:    reflect.TypeOf(float64(0)),
+	filter.BOOLEAN:   reflect.TypeOf(false),
+	filter.DATE_TIME: reflect.TypeOf(time.Time{}),
+}
+
+// options devolve as opções que configuram o Gosparse descrito.
+func (d *description) options() []gosparse.GosparseOpt {
+	options := []gosparse.GosparseOpt{
+		func(g *gosparse.Gosparse) {
+			g.Pagination = *pagination.New()
+		},
+	}
+
+	if d.Type != "" {
+		options = append(options, gosparse.TypeName(d.Type))
+	}
+
+	options = append(options, gosparse.AcceptRelations(d.Relations...))
+	for alias, path := range d.Aliases {
+		options = append(options, gosparse.AliasRelation(alias, path))
+	}
+
+	for typ, attrs := range d.Fields {
+		options = append(options, gosparse.AcceptFieldset(typ, attrs...))
+	}
+
+	for typ, attrs := range d.Always {
+		options = append(options, gosparse.AlwaysFields(typ, attrs...))
+	}
+
+	for typ, attrs := range d.Defaults {
+		options = append(options, gosparse.DefaultFields(typ, attrs...))
+	}
+
+	if d.NestedFields {
+		options = append(options, func(g *gosparse.Gosparse) {
+			sparsefieldsets.NestedPaths(d.Paths...)(&g.Fieldset)
+		})
+	}
+
+	options = append(options,
+		gosparse.AcceptFilters(d.Filters...),
+		gosparse.AcceptSortBy(d.Sort...),
+	)
+
+	for field, kind := range d.FilterTypes {
+		field, t := field, kinds[filter.Kind(kind)]
+		options = append(options, func(g *gosparse.Gosparse) {
+			g.Filter.AddType(field, t)
+		})
+	}
+
+	if d.FilterExpressions {
+		options = append(options, gosparse.FilterExpressions())
+	}
+
+	if d.PageSize > 0 {
+		options = append(options, gosparse.AcceptPagination(d.PageSize))
+	}
+
+	if d.MaxPageSize > 0 {
+		options = append(options, gosparse.MaxPageSize(d.MaxPageSize))
+	}
+
+	if d.MaxIncludeDepth > 0 {
+		options = append(options, gosparse.MaxIncludeDepth(d.MaxIncludeDepth))
+	}
+
+	if d.MaxIncludePaths > 0 {
+		options = append(options, gosparse.MaxIncludePaths(d.MaxIncludePaths))
+	}
+
+	return options
+}
+
+// build devolve o Gosparse descrito, com a configuração congelada.
+func (d *description) build() gosparse.Gosparse {
+	return gosparse.New(d.options()...)
+}
@@ -0,0 +1,46 @@
+// Command gosparse
+//
+// Interpreta uma querystring com a configuração de parâmetros aceitos de um
+// schema e exibe os relacionamentos incluídos, os atributos selecionados, os
+// filtros, a ordenação e a paginação extraídos, ou o erro devolvido,
+// facilitando a investigação de requests de clientes:
+//
+//	gosparse -schema articles.yaml 'https://api.example.com/articles?include=author&sort=-created_at'
+//	resource  articles
+//	include   author
+//	fields    articles: title, body, author
+//	          people: name
+//	filter    -
+//	sort      -created_at
+//	page      number=1 size=25 offset=0
+//	query     include=author&sort=-created_at
+//
+// O schema pode ser descrito em um arquivo JSON ou YAML:
+//
+//	type: articles
+//	relations: [author, comments, comments.author]
+//	aliases:
+//	  commentAuthors: comments.author
+//	fields:
+//	  articles: [title, body, author, comments]
+//	  people: [name]
+//	filters: [title, price]
+//	filter_types:
+//	  price: number
+//	filter_expressions: true
+//	sort: [title, created_at]
+//	page_size: 25
+//	max_page_size: 100
+//
+// ou montado a partir das tags "gosparse" de uma estrutura Go, carregada com
+// go/packages a partir do código fonte, sem executar o código do pacote. Os
+// tipos dos filtros seguem os tipos dos campos:
+//
+//	gosparse -pkg ./models -type Article 'filter[price_gte]=10'
+//
+// Com "-format json" o resultado e os erros são exibidos em JSON. Os erros
+// incluem o status HTTP correspondente (400, ou 403 para campos negados).
+//
+// O comando termina com o código 1 quando a querystring é rejeitada e com o
+// código 2 quando os argumentos ou o schema são inválidos.
+package main
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	// TEXT é o formato de saída em texto, um parâmetro por linha
+	TEXT = "text"
+	// JSON é o formato de saída em JSON
+	JSON = "json"
+)
+
+// Os códigos de saída do comando
+const (
+	exitOK = iota
+	// exitInvalid indica que a querystring foi rejeitada pelo schema
+	exitInvalid
+	// exitUsage indica argumentos ou schema inválidos
+	exitUsage
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executa o comando com os argumentos recebidos e devolve o código de
+// saída.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("gosparse", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: gosparse (-schema FILE | -pkg PATTERN -type NAME) [-format text|json] QUERY")
+		flags.PrintDefaults()
+	}
+
+	schema := flags.String("schema", "", "JSON or YAML file describing the accepted parameters")
+	pkg := flags.String("pkg", "", "Go package pattern with the tagged struct")
+	typ := flags.String("type", "", "tagged struct name within -pkg")
+	format := flags.String("format", TEXT, "output format: text or json")
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if flags.NArg() != 1 || (*schema == "") == (*pkg == "") || (*pkg != "" && *typ == "") {
+		flags.Usage()
+		return exitUsage
+	}
+
+	if *format != TEXT && *format != JSON {
+		fmt.Fprintf(stderr, "gosparse: unknown format %s\n", *format)
+		return exitUsage
+	}
+
+	d, err := describe(*schema, *pkg, *typ)
+	if err != nil {
+		fmt.Fprintf(stderr, "gosparse: %v\n", err)
+		return exitUsage
+	}
+
+	query, err := parseInput(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "gosparse: invalid query: %v\n", err)
+		return exitUsage
+	}
+
+	code := exitOK
+
+	q, err := d.build().Parse(query)
+	r := &report{}
+	if err != nil {
+		r = errorReport(err)
+		code = exitInvalid
+	} else {
+		r = newReport(q)
+	}
+
+	write := r.writeText
+	if *format == JSON {
+		write = r.writeJSON
+	}
+
+	if err := write(stdout); err != nil {
+		fmt.Fprintf(stderr, "gosparse: %v\n", err)
+		return exitUsage
+	}
+
+	return code
+}
+
+// describe devolve a descrição do arquivo (schema) ou da estrutura typ do
+// pacote Go (pkg).
+func describe(schema, pkg, typ string) (*description, error) {
+	if schema != "" {
+		return readDescription(schema)
+	}
+
+	return loadPackage("", pkg, typ)
+}
+
+// parseInput recebe a URL completa, a querystring com ou sem "?" ou a URL
+// sem querystring e devolve os parâmetros:
+//
+//	https://api.example.com/articles?include=author#top
+//	?include=author
+//	include=author
+func parseInput(input string) (url.Values, error) {
+	input, _, _ = strings.Cut(input, "#")
+
+	if _, query, found := strings.Cut(input, "?"); found {
+		return url.ParseQuery(query)
+	}
+
+	if strings.Contains(input, "://") {
+		return url.Values{}, nil
+	}
+
+	return url.ParseQuery(input)
+}
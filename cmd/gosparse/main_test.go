@@ -0,0 +1,200 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+const query = "https://api.example.com/articles?include=writer,comments.author&fields[articles]=title" +
+	"&filter[price_gte]=10&sort=-created_at,title&page[number]=2#top"
+
+func TestRun(t *testing.T) {
+	schema := []string{"-schema", "testdata/articles.yaml"}
+	pkg := []string{"-pkg", "./testdata/models", "-type", "Article"}
+
+	testtable := []struct {
+		desc   string
+		args   []string
+		code   int
+		golden string
+	}{
+		{
+			desc:   "should print the parsed query",
+			args:   append(schema, query),
+			code:   exitOK,
+			golden: "query.txt",
+		},
+		{
+			desc:   "should print the parsed query as json",
+			args:   append(schema, "-format", "json", query),
+			code:   exitOK,
+			golden: "query.json",
+		},
+		{
+			desc:   "should load the schema from a go package",
+			args:   append(pkg, query),
+			code:   exitOK,
+			golden: "query.txt",
+		},
+		{
+			desc:   "should print the default values",
+			args:   append(schema, "https://api.example.com/articles"),
+			code:   exitOK,
+			golden: "defaults.txt",
+		},
+		{
+			desc:   "should print the error",
+			args:   append(schema, "filter[body]=Go"),
+			code:   exitInvalid,
+			golden: "error.txt",
+		},
+		{
+			desc:   "should print the filter expression",
+			args:   append(schema, `filter=price > 10 AND (title = "Go" OR author.name = "Jean")`),
+			code:   exitOK,
+			golden: "expression.txt",
+		},
+		{
+			desc:   "should print the error of the filter type",
+			args:   append(pkg, "filter[price_start]=1"),
+			code:   exitInvalid,
+			golden: "type.txt",
+		},
+		{
+			desc:   "should print the error of the max page size",
+			args:   append(schema, "page[size]=51"),
+			code:   exitInvalid,
+			golden: "max.txt",
+		},
+		{
+			desc:   "should print the error as json",
+			args:   append(schema, "-format", "json", "?filter[body]=Go"),
+			code:   exitInvalid,
+			golden: "error.json",
+		},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			code := run(tc.args, &stdout, &stderr)
+			require.Equal(t, tc.code, code, stderr.String())
+
+			path := filepath.Join("testdata", tc.golden+".golden")
+			if *update {
+				require.Nil(t, os.WriteFile(path, stdout.Bytes(), 0o644))
+			}
+
+			want, err := os.ReadFile(path)
+			require.Nil(t, err)
+			require.Equal(t, string(want), stdout.String())
+		})
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	testtable := []struct {
+		desc string
+		args []string
+	}{
+		{desc: "should require the query", args: []string{"-schema", "testdata/articles.yaml"}},
+		{desc: "should require a schema", args: []string{"include=author"}},
+		{desc: "should require only one schema", args: []string{"-schema", "testdata/articles.yaml", "-pkg", "./testdata/models", "-type", "Article", "include=author"}},
+		{desc: "should require the type of the package", args: []string{"-pkg", "./testdata/models", "include=author"}},
+		{desc: "should reject unknown formats", args: []string{"-schema", "testdata/articles.yaml", "-format", "xml", "include=author"}},
+		{desc: "should reject missing schemas", args: []string{"-schema", "testdata/missing.yaml", "include=author"}},
+		{desc: "should reject unknown types", args: []string{"-pkg", "./testdata/models", "-type", "Missing", "include=author"}},
+		{desc: "should reject invalid queries", args: []string{"-schema", "testdata/articles.yaml", "include=%zz"}},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			require.Equal(t, exitUsage, run(tc.args, &stdout, &stderr))
+			require.Empty(t, stdout.String())
+			require.NotEmpty(t, stderr.String())
+		})
+	}
+}
+
+func TestLoadPackage(t *testing.T) {
+	loaded, err := loadPackage("", "./testdata/models", "Article")
+	require.Nil(t, err)
+
+	described, err := readDescription("testdata/articles.yaml")
+	require.Nil(t, err)
+
+	// as opções que não são definidas pelas tags não são carregadas
+	described.FilterExpressions, described.MaxPageSize = false, 0
+	require.Equal(t, described, loaded)
+}
+
+func TestReadDescription(t *testing.T) {
+	dir := t.TempDir()
+
+	unknown := filepath.Join(dir, "unknown.yaml")
+	require.Nil(t, os.WriteFile(unknown, []byte("type: articles\npage_sise: 25\n"), 0o644))
+
+	_, err := readDescription(unknown)
+	require.ErrorContains(t, err, "field page_sise not found in type main.description")
+
+	kind := filepath.Join(dir, "kind.yaml")
+	require.Nil(t, os.WriteFile(kind, []byte("filters: [price]\nfilter_types:\n  price: float\n"), 0o644))
+
+	_, err = readDescription(kind)
+	require.ErrorContains(t, err, "unknown type float of filter price")
+
+	empty := filepath.Join(dir, "empty.yaml")
+	require.Nil(t, os.WriteFile(empty, nil, 0o644))
+
+	described, err := readDescription(empty)
+	require.Nil(t, err)
+	require.Equal(t, &description{}, described)
+}
+
+func TestParseInput(t *testing.T) {
+	testtable := []struct {
+		desc   string
+		input  string
+		expect url.Values
+	}{
+		{
+			desc:   "should parse urls",
+			input:  "https://api.example.com/articles?include=author#top",
+			expect: url.Values{"include": {"author"}},
+		},
+		{
+			desc:   "should parse urls without query",
+			input:  "https://api.example.com/articles",
+			expect: url.Values{},
+		},
+		{
+			desc:   "should parse query strings with question mark",
+			input:  "?sort=-title",
+			expect: url.Values{"sort": {"-title"}},
+		},
+		{
+			desc:   "should parse query strings",
+			input:  "fields[articles]=title&page[size]=5",
+			expect: url.Values{"fields[articles]": {"title"}, "page[size]": {"5"}},
+		},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			query, err := parseInput(tc.input)
+			require.Nil(t, err)
+			require.Equal(t, tc.expect, query)
+		})
+	}
+}
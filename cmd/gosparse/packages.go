@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/jeanmolossi/gosparse/internal/source"
+)
+
+// loadPackage carrega o pacote Go (pattern) e monta a descrição a partir
+// das tags "gosparse" da estrutura typeName, sem executar o código do
+// pacote (veja source.Compile).
+//
+// As opções que não são definidas pelas tags, como filter_expressions e
+// max_page_size, não fazem parte da descrição.
+func loadPackage(dir, pattern, typeName string) (*description, error) {
+	pkg, err := source.Load(dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pkg.Errors) > 0 {
+		return nil, fmt.Errorf("can not load %s: %w", pattern, pkg.Errors[0])
+	}
+
+	_, st, err := pkg.Lookup(typeName)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, err := source.Compile(st)
+	if err != nil {
+		return nil, err
+	}
+
+	filterTypes := make(map[string]string)
+	for path, field := range schema.Fields {
+		if field.Filter {
+			filterTypes[path] = string(source.Kind(field.Var.Type()))
+		}
+	}
+
+	return &description{
+		Type:        schema.Resource,
+		Relations:   schema.Select(func(f source.Field) bool { return f.Relation }),
+		Aliases:     schema.Aliases,
+		Fields:      schema.Fieldsets,
+		Always:      schema.Always,
+		Defaults:    schema.Defaults,
+		Paths:       schema.Select(func(f source.Field) bool { return f.Select }),
+		Filters:     schema.Select(func(f source.Field) bool { return f.Filter }),
+		FilterTypes: filterTypes,
+		Sort:        schema.Select(func(f source.Field) bool { return f.Sort }),
+	}, nil
+}
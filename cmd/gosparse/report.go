@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	stdsort "sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+)
+
+// report é a interpretação de uma querystring pelo schema: o Query
+// extraído ou o erro devolvido.
+type report struct {
+	Query    string       `json:"query,omitempty"`
+	Resource string       `json:"resource,omitempty"`
+	Include  []include    `json:"include,omitempty"`
+	Fields   fields       `json:"fields,omitempty"`
+	Filter   []condition  `json:"filter,omitempty"`
+	Where    string       `json:"where,omitempty"`
+	Sort     []key        `json:"sort,omitempty"`
+	Page     *page        `json:"page,omitempty"`
+	Error    *reportError `json:"error,omitempty"`
+}
+
+// include é um relacionamento solicitado e o nome alternativo utilizado.
+type include struct {
+	Path  string `json:"path"`
+	Alias string `json:"alias,omitempty"`
+}
+
+// fields são os atributos retornados de cada tipo de recurso.
+type fields map[string][]string
+
+// condition é o filtro de um campo com o predicado e os valores.
+type condition struct {
+	Field     string   `json:"field"`
+	Predicate string   `json:"predicate"`
+	Values    []string `json:"values"`
+}
+
+// key é um campo ordenado com a direção "asc" ou "desc".
+type key struct {
+	Field string `json:"field"`
+	Order string `json:"order"`
+}
+
+// page são os valores de paginação e os que foram enviados pelo cliente.
+type page struct {
+	Number int      `json:"number"`
+	Size   int      `json:"size"`
+	Offset int      `json:"offset"`
+	Sent   []string `json:"sent,omitempty"`
+}
+
+// reportError é o erro da extração com o status HTTP correspondente e,
+// quando o acesso ao campo é negado, a operação, o recurso e o campo.
+type reportError struct {
+	Status    int    `json:"status"`
+	Message   string `json:"message"`
+	Operation string `json:"operation,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Field     string `json:"field,omitempty"`
+}
+
+// newReport monta o report do Query extraído.
+func newReport(q *gosparse.Query) *report {
+	r := &report{
+		Query:    q.String(),
+		Resource: q.Resource,
+		Fields:   fields(q.Fields),
+	}
+
+	for _, rel := range q.Include {
+		r.Include = append(r.Include, include{Path: rel.Path, Alias: rel.Alias})
+	}
+
+	for _, field := range sortedFilters(q) {
+		f := q.Filter[field]
+		r.Filter = append(r.Filter, condition{Field: field, Predicate: predicate(f.Predicate.String()), Values: f.Values})
+	}
+
+	// as condições com OR e NOT entre campos não cabem em Filter
+	if !q.Condition.Empty() {
+		r.Where = q.Condition.String()
+	}
+
+	for _, k := range q.Sort {
+		order := "asc"
+		if k.Sorting == sort.DESC {
+			order = "desc"
+		}
+
+		r.Sort = append(r.Sort, key{Field: k.Field, Order: order})
+	}
+
+	r.Page = &page{
+		Number: q.Page.Get(pagination.NUMBER),
+		Size:   q.Page.Get(pagination.SIZE),
+		Offset: q.Page.Get(pagination.OFFSET),
+	}
+
+	if q.Page.IsSet(pagination.NUMBER) {
+		r.Page.Sent = append(r.Page.Sent, string(pagination.NUMBER))
+	}
+
+	if q.Page.IsSet(pagination.SIZE) {
+		r.Page.Sent = append(r.Page.Sent, string(pagination.SIZE))
+	}
+
+	if q.Page.IsSet(pagination.OFFSET) {
+		r.Page.Sent = append(r.Page.Sent, string(pagination.OFFSET))
+	}
+
+	return r
+}
+
+// errorReport monta o report do erro da extração. O acesso negado a um
+// campo (gosparse.ForbiddenError) é reportado com o status 403, os demais
+// erros com o status 400.
+func errorReport(err error) *report {
+	e := &reportError{Status: http.StatusBadRequest, Message: err.Error()}
+
+	var forbidden *gosparse.ForbiddenError
+	if errors.As(err, &forbidden) {
+		e.Status = forbidden.StatusCode()
+		e.Operation = string(forbidden.Op)
+		e.Resource = forbidden.Resource
+		e.Field = forbidden.Field
+	}
+
+	return &report{Error: e}
+}
+
+// sortedFilters devolve os campos filtrados em ordem alfabética.
+func sortedFilters(q *gosparse.Query) []string {
+	names := make([]string, 0, len(q.Filter))
+	for field := range q.Filter {
+		names = append(names, field)
+	}
+
+	stdsort.Strings(names)
+	return names
+}
+
+// predicate devolve o predicado para exibição. O filtro sem predicado é
+// uma igualdade.
+func predicate(p string) string {
+	if p == "" {
+		return "eq"
+	}
+
+	return p
+}
+
+// writeJSON escreve o report em JSON.
+func (r *report) writeJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+
+	return encoder.Encode(r)
+}
+
+// NONE é exibido no lugar dos parâmetros vazios na saída em texto
+const NONE = "-"
+
+// list devolve os valores separados por vírgula ou NONE quando vazio.
+func list(values []string) string {
+	if len(values) == 0 {
+		return NONE
+	}
+
+	return strings.Join(values, ", ")
+}
+
+// writeText escreve o report em texto, com um parâmetro por linha:
+//
+//	include   comments.author (commentAuthors)
+//	fields    articles: title, author
+//	filter    price gte 10
+//	sort      -created_at
+//	page      number=2 size=25 offset=0
+func (r *report) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	if r.Error != nil {
+		fmt.Fprintf(tw, "error\t%s\n", r.Error.Message)
+		fmt.Fprintf(tw, "status\t%d %s\n", r.Error.Status, http.StatusText(r.Error.Status))
+
+		if r.Error.Field != "" {
+			fmt.Fprintf(tw, "denied\t%s %s of %s\n", r.Error.Operation, r.Error.Field, r.Error.Resource)
+		}
+
+		return tw.Flush()
+	}
+
+	fmt.Fprintf(tw, "resource\t%s\n", r.Resource)
+
+	includes := make([]string, 0, len(r.Include))
+	for _, rel := range r.Include {
+		if rel.Alias != "" {
+			includes = append(includes, fmt.Sprintf("%s (%s)", rel.Path, rel.Alias))
+			continue
+		}
+
+		includes = append(includes, rel.Path)
+	}
+
+	fmt.Fprintf(tw, "include\t%s\n", list(includes))
+
+	types := make([]string, 0, len(r.Fields))
+	for typ := range r.Fields {
+		types = append(types, typ)
+	}
+
+	stdsort.Strings(types)
+
+	label := "fields"
+	for _, typ := range types {
+		fmt.Fprintf(tw, "%s\t%s: %s\n", label, typ, strings.Join(r.Fields[typ], ", "))
+		label = ""
+	}
+
+	label = "filter"
+	for _, c := range r.Filter {
+		fmt.Fprintf(tw, "%s\t%s %s %s\n", label, c.Field, c.Predicate, strings.Join(c.Values, ", "))
+		label = ""
+	}
+
+	if label != "" {
+		fmt.Fprintf(tw, "%s\t%s\n", label, NONE)
+	}
+
+	if r.Where != "" {
+		fmt.Fprintf(tw, "where\t%s\n", r.Where)
+	}
+
+	keys := make([]string, 0, len(r.Sort))
+	for _, k := range r.Sort {
+		if k.Order == "desc" {
+			keys = append(keys, "-"+k.Field)
+			continue
+		}
+
+		keys = append(keys, k.Field)
+	}
+
+	fmt.Fprintf(tw, "sort\t%s\n", list(keys))
+	fmt.Fprintf(tw, "page\tnumber=%d size=%d offset=%d\n", r.Page.Number, r.Page.Size, r.Page.Offset)
+	query := r.Query
+	if query == "" {
+		query = NONE
+	}
+
+	fmt.Fprintf(tw, "query\t%s\n", query)
+
+	return tw.Flush()
+}
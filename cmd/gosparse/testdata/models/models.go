@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+type Article struct {
+	_         struct{}  `gosparse:"type:articles"`
+	ID        string    `gosparse:"name:id;always"`
+	Title     string    `gosparse:"name:title;select;sort;filter;default"`
+	Price     float64   `gosparse:"name:price;select;sort;filter"`
+	CreatedAt time.Time `gosparse:"name:created_at;select;sort"`
+	Author    *Author   `gosparse:"name:author;relation;alias:writer"`
+	Comments  []Comment `gosparse:"name:comments;relation"`
+	Internal  string
+}
+
+type Author struct {
+	_    struct{} `gosparse:"type:people"`
+	Name string   `gosparse:"name:name;select;filter"`
+}
+
+type Comment struct {
+	_      struct{} `gosparse:"type:comments"`
+	Body   string   `gosparse:"name:body;select"`
+	Author *Author  `gosparse:"name:author;relation;alias:commentAuthors"`
+}
@@ -3,12 +3,9 @@ package gosparse
 import (
 	"fmt"
 	"reflect"
+	stdsort "sort"
 	"strings"
 
-	"github.com/jeanmolossi/gosparse/internal/filter"
-	"github.com/jeanmolossi/gosparse/internal/include"
-	"github.com/jeanmolossi/gosparse/internal/pagination"
-	"github.com/jeanmolossi/gosparse/internal/sort"
 	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
 )
 
@@ -36,9 +33,12 @@ func getValueAndValidate(s any) (reflect.Value, error) {
 
 // extractTag recebe um StructField e extrai as configurações de
 // querystring aceitas a partir da Tagname (gosparse).
-func extractTag(typ reflect.StructField) *config {
+//
+// Campos em branco (_) são ignorados, pois são utilizados somente para
+// definir o tipo de recurso da estrutura (veja typeName).
+func extractTag(typ reflect.StructField) *Field {
 	tag := typ.Tag.Get(Tagname)
-	if tag == "" || tag == "-" {
+	if tag == "" || tag == "-" || typ.Name == "_" {
 		return nil
 	}
 
@@ -48,44 +48,245 @@ func extractTag(typ reflect.StructField) *config {
 
 // handleTags recebe um reflect.Value de uma estrutura e trata para
 // ter um objeto de configuração válido para montar um GoSparse
-func handleTags(v reflect.Value) (map[string]config, error) {
-	fields := map[string]config{}
+func handleTags(v reflect.Value) (map[string]Field, error) {
+	visiting := map[reflect.Type]struct{}{v.Type(): {}}
+	return walkTags(v.Type(), visiting)
+}
 
-	for i := 0; i < v.NumField(); i++ {
-		typ := v.Type().Field(i)
+// walkTags percorre os campos do tipo da estrutura e extrai as
+// configurações de cada campo com a tag (gosparse).
+//
+// Campos marcados como "relation" são percorridos recursivamente e
+// seus campos recebem o nome da relação como prefixo:
+//
+//	nested.dummy
+//
+// visiting guarda os tipos que estão sendo percorridos no caminho atual.
+// Quando uma relação aponta para um tipo que já está no caminho
+// (Post -> Author -> Posts) a relação é aceita, mas não é percorrida
+// novamente, evitando uma recursão infinita.
+func walkTags(t reflect.Type, visiting map[reflect.Type]struct{}) (map[string]Field, error) {
+	fields := map[string]Field{}
+
+	for i := 0; i < t.NumField(); i++ {
+		typ := t.Field(i)
+
+		conf := extractTag(typ)
+		if conf == nil {
+			continue
+		}
 
-		conf := *extractTag(typ)
-		fields[conf.Name] = conf
+		conf.Type = typ.Type
+		conf.Index = typ.Index
+		fields[conf.Name] = *conf
 
-		kind := typ.Type.Kind()
-		if conf.Relation && (kind == reflect.Ptr || kind == reflect.Struct) {
-			field, err := getValueAndValidate(v.Field(i).Interface())
-			if err != nil {
-				return nil, err
-			}
+		if !conf.Relation {
+			continue
+		}
+
+		relType, err := relationType(typ)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, cycle := visiting[relType]; cycle {
+			continue
+		}
+
+		visiting[relType] = struct{}{}
+		res, err := walkTags(relType, visiting)
+		delete(visiting, relType)
+
+		if err != nil {
+			return nil, err
+		}
 
-			res, err := handleTags(field)
-			if err != nil {
-				return nil, err
-			}
+		for name, rel := range res {
+			rel.Index = append([]int{i}, rel.Index...)
 
-			for _, rel := range res {
-				k := []string{conf.Name, rel.Name}
-				fields[strings.Join(k, ".")] = rel
-			}
+			k := []string{conf.Name, name}
+			fields[strings.Join(k, ".")] = rel
 		}
 	}
 
 	return fields, nil
 }
 
+// ROOT_TYPE é o tipo de recurso dos dados primários no parâmetro "fields"
+// quando a estrutura não define o próprio tipo de recurso.
+const ROOT_TYPE = "root"
+
+// TypeNamer é implementado pelas estruturas que definem o próprio
+// tipo de recurso utilizado no parâmetro "fields[TYPE]".
+//
+//	func (Article) TypeName() string { return "articles" }
+type TypeNamer interface {
+	TypeName() string
+}
+
+// typeName devolve o tipo de recurso da estrutura a partir do método
+// TypeName (TypeNamer) ou, caso não exista, da configuração "type" da
+// tag de um campo em branco:
+//
+//	type Article struct {
+//		_ struct{} `gosparse:"type:articles"`
+//	}
+//
+// Caso a estrutura não defina o tipo de recurso, fallback é devolvido.
+func typeName(t reflect.Type, fallback string) string {
+	if namer, ok := reflect.New(t).Interface().(TypeNamer); ok {
+		if name := namer.TypeName(); name != "" {
+			return name
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Name != "_" {
+			continue
+		}
+
+		if conf := extractor(field.Tag.Get(Tagname)); conf.Resource != "" {
+			return conf.Resource
+		}
+	}
+
+	return fallback
+}
+
+// walkFieldsets percorre a estrutura e registra no Fieldset os atributos
+// aceitos no parâmetro "fields[TYPE]" para o tipo de recurso typ e para
+// cada uma das relações.
+//
+// O tipo de recurso de uma relação é a configuração "type" da tag da
+// relação, o tipo definido pela estrutura relacionada (typeName) ou, por
+// fim, o nome da relação:
+//
+//	fields[articles]=title,created_at
+//	fields[nested]=dummy
+//
+// visited guarda as estruturas já percorridas, as relações para uma
+// estrutura já percorrida têm os atributos registrados, mas não são
+// percorridas novamente.
+func walkFieldsets(t reflect.Type, typ string, fieldset *sparsefieldsets.Fieldset, visited map[reflect.Type]struct{}) error {
+	visited[t] = struct{}{}
+	addAttributes(t, typ, fieldset)
+
+	for i := 0; i < t.NumField(); i++ {
+		conf := extractTag(t.Field(i))
+		if conf == nil || !conf.Relation {
+			continue
+		}
+
+		relType, err := relationType(t.Field(i))
+		if err != nil {
+			return err
+		}
+
+		relTypename := conf.Resource
+		if relTypename == "" {
+			relTypename = typeName(relType, conf.Name)
+		}
+
+		if _, seen := visited[relType]; seen {
+			addAttributes(relType, relTypename, fieldset)
+			continue
+		}
+
+		if err := walkFieldsets(relType, relTypename, fieldset, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addAttributes registra no Fieldset os campos da estrutura que podem ser
+// utilizados no parâmetro "fields", incluindo as relações, e os campos
+// obrigatórios (always) e da seleção padrão (default) do tipo de recurso.
+func addAttributes(t reflect.Type, typ string, fieldset *sparsefieldsets.Fieldset) {
+	fieldset.AddAttributes(typ)
+
+	for i := 0; i < t.NumField(); i++ {
+		conf := extractTag(t.Field(i))
+		if conf == nil || !conf.Select {
+			continue
+		}
+
+		fieldset.AddAttributes(typ, conf.Name)
+
+		if conf.Always {
+			fieldset.AddAlways(typ, conf.Name)
+		}
+
+		if conf.Default {
+			fieldset.AddDefaults(typ, conf.Name)
+		}
+	}
+}
+
+// relationType recebe o StructField de uma relação e devolve o tipo
+// da estrutura relacionada.
+//
+// A relação pode ser uma estrutura, uma referência para uma estrutura
+// ou uma lista (slice / array) delas:
+//
+//	Author  Author
+//	Author  *Author
+//	Posts   []Post
+//	Posts   []*Post
+func relationType(f reflect.StructField) (reflect.Type, error) {
+	t := f.Type
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("relation %s should be a struct", f.Name)
+	}
+
+	return t, nil
+}
+
+// sortedKeys devolve as chaves das configurações extraídas ordenadas.
+//
+// Uma mesma estrutura pode aparecer em mais de um caminho de relação,
+// portanto a ordenação garante que o nome alternativo seja registrado
+// sempre para o mesmo caminho (o primeiro em ordem alfabética).
+func sortedKeys(extracted map[string]Field) []string {
+	keys := make([]string, 0, len(extracted))
+	for k := range extracted {
+		keys = append(keys, k)
+	}
+
+	stdsort.Strings(keys)
+	return keys
+}
+
 // Tag extractor -----------------------------------
 
 var Tagname = "gosparse"
 
-type config struct {
+// Field é a configuração de querystring de um campo da estrutura,
+// extraída a partir da Tagname (gosparse).
+type Field struct {
 	// Name corresponde ao nome do campo que será aceito na querystring
 	Name string
+	// Type é o tipo do campo na estrutura
+	Type reflect.Type
+	// Index é a sequência de índices para acessar o campo a partir da
+	// estrutura compilada (reflect.Value.FieldByIndex). Campos de relações
+	// em listas (slices / arrays) são indexados a partir do item da lista.
+	Index []int
 	// Select indica se o campo pode ser utilizado no parâmetro "fields"
 	//
 	// 	fields[name]
@@ -102,14 +303,50 @@ type config struct {
 	//
 	//	include=name
 	Relation bool
+	// Alias é um nome alternativo aceito no parâmetro "include" para a relação
+	//
+	//	include=alias
+	Alias string
+	// Resource é o tipo de recurso utilizado no parâmetro "fields[TYPE]",
+	// definido pela configuração "type" da tag
+	//
+	//	fields[type]
+	Resource string
+	// Always indica que o campo sempre será retornado no parâmetro "fields",
+	// mesmo que não seja solicitado
+	Always bool
+	// Default indica que o campo faz parte da seleção padrão quando o
+	// parâmetro "fields" não é informado
+	Default bool
+	// Column é a coluna do campo no armazenamento, definida pela
+	// configuração "column" da tag (veja Storage)
+	//
+	//	column:created_ts
+	Column string
+	// Join é a junção da relação com a tabela relacionada no formato
+	// "local=table.remote", definida pela configuração "join" da tag
+	// (veja Join)
+	//
+	//	join:author_id=authors.id
+	Join string
+}
+
+// ParseTag recebe o valor da tag "gosparse" de um campo e devolve a sua
+// configuração, sem Type e Index. Permite que ferramentas que analisam o
+// código fonte (go/types) interpretem as tags da mesma forma que Compile:
+//
+//	gosparse.ParseTag("name:title;select;sort")
+//	// Field{Name: "title", Select: true, Sort: true}
+func ParseTag(tag string) Field {
+	return extractor(tag)
 }
 
 // extractor recebe a tag do campo e trata para que seja retornado
 // um objeto de configuração válido.
-func extractor(tag string) config {
+func extractor(tag string) Field {
 	configs := strings.Split(tag, ";")
 
-	c := config{}
+	c := Field{}
 
 	for _, conf := range configs {
 		name, done := strings.CutPrefix(conf, "name:")
@@ -118,6 +355,30 @@ func extractor(tag string) config {
 			continue
 		}
 
+		typ, done := strings.CutPrefix(conf, "type:")
+		if done {
+			c.Resource = typ
+			continue
+		}
+
+		column, done := strings.CutPrefix(conf, "column:")
+		if done {
+			c.Column = column
+			continue
+		}
+
+		join, done := strings.CutPrefix(conf, "join:")
+		if done {
+			c.Join = join
+			continue
+		}
+
+		alias, done := strings.CutPrefix(conf, "alias:")
+		if done {
+			c.Alias = alias
+			continue
+		}
+
 		if strings.HasPrefix(conf, "select") {
 			c.Select = true
 			continue
@@ -137,9 +398,19 @@ func extractor(tag string) config {
 			c.Relation = true
 			continue
 		}
+
+		if strings.HasPrefix(conf, "always") {
+			c.Always = true
+			continue
+		}
+
+		if strings.HasPrefix(conf, "default") {
+			c.Default = true
+			continue
+		}
 	}
 
-	if c.Relation {
+	if c.Relation || c.Always || c.Default {
 		c.Select = true
 	}
 
@@ -149,53 +420,25 @@ func extractor(tag string) config {
 // Real extract
 
 // Extract recebe interface e trata para que seja montado um Gosparse
-// baseado na tag "gosparse" da estrutura
-func Extract(s any) (Gosparse, error) {
-	value, err := getValueAndValidate(s)
-	if err != nil {
-		return Gosparse{}, nil
-	}
-
-	extracted, err := handleTags(value)
+// baseado na tag "gosparse" da estrutura.
+//
+// As opções recebidas são aplicadas antes da extração, permitindo por
+// exemplo limitar a profundidade do "include" ou definir o tipo de recurso
+// dos dados primários:
+//
+//	gosparse.Extract(Post{}, gosparse.MaxIncludeDepth(3), gosparse.TypeName("posts"))
+//
+// O tipo de recurso dos dados primários é definido pela opção TypeName,
+// pela própria estrutura (veja TypeNamer) ou, por fim, ROOT_TYPE. Tanto
+// "fields[TYPE]" quanto "fields" sem tipo se referem aos dados primários.
+//
+// Extract percorre a estrutura a cada chamada, para reutilizar a extração
+// entre requests utilize ExtractCached ou MustCompile.
+func Extract(s any, options ...GosparseOpt) (Gosparse, error) {
+	schema, err := Compile(s, options...)
 	if err != nil {
-		return Gosparse{}, nil
-	}
-
-	gs := Gosparse{
-		Include:    *include.New(),
-		Fieldset:   *sparsefieldsets.New(),
-		Filter:     *filter.New(),
-		Pagination: *pagination.New(),
-		Sort:       *sort.New(),
-	}
-
-	relations := make([]string, 0, len(extracted))
-	fields := make([]string, 0, len(extracted))
-	filters := make([]string, 0, len(extracted))
-	sorter := make([]string, 0, len(extracted))
-
-	for field, conf := range extracted {
-		if conf.Relation {
-			relations = append(relations, field)
-		}
-
-		if conf.Select {
-			fields = append(fields, field)
-		}
-
-		if conf.Filter {
-			filters = append(filters, field)
-		}
-
-		if conf.Sort {
-			sorter = append(sorter, field)
-		}
+		return Gosparse{}, err
 	}
 
-	AcceptRelations(relations...)(&gs)
-	AcceptFields(fields...)(&gs)
-	AcceptFilters(filters...)(&gs)
-	AcceptSortBy(sorter...)(&gs)
-
-	return gs, nil
+	return schema.Gosparse(), nil
 }
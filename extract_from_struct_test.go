@@ -1,9 +1,13 @@
 package gosparse
 
 import (
+	"context"
+	"fmt"
+	"net/url"
 	"testing"
 	"time"
 
+	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
 	"github.com/stretchr/testify/require"
 )
 
@@ -17,38 +21,276 @@ type Nested struct {
 	Dummy bool `gosparse:"name:dummy;select;"`
 }
 
+type Post struct {
+	_        struct{}  `gosparse:"type:posts"`
+	ID       int       `gosparse:"name:id;select;sort;filter"`
+	Title    string    `gosparse:"name:title;select"`
+	Internal string    // sem tag, deve ser ignorado
+	Author   *Author   `gosparse:"name:author;relation"`
+	Comments []Comment `gosparse:"name:comments;relation"`
+}
+
+type Author struct {
+	Name  string `gosparse:"name:name;select;sort"`
+	Posts []Post `gosparse:"name:posts;relation"`
+}
+
+func (*Author) TypeName() string { return "people" }
+
+type Comment struct {
+	Body   string  `gosparse:"name:body;select"`
+	Author *Author `gosparse:"name:author;relation;alias:commentAuthors"`
+}
+
 func TestExtract(t *testing.T) {
 	gosparse, err := Extract(Dummy{})
 
 	require.Nil(t, err)
 	require.NotEmpty(t, gosparse)
 	require.NotNil(t, gosparse.Include)
-	require.Len(t, gosparse.Include, 1) // only nested as relation
+	require.Len(t, gosparse.Include.Relations, 1) // only nested as relation
 	require.NotNil(t, gosparse.Fieldset)
-	require.Len(t, gosparse.Fieldset, 4) // contains all fields and root
+	require.Len(t, gosparse.Fieldset.Resources, 2) // root and nested resource types
 	require.NotNil(t, gosparse.Filter)
-	require.Len(t, gosparse.Filter, 2) // only fields with "filter" tag
+	require.Len(t, gosparse.Filter.Accepted, 2) // only fields with "filter" tag
 	require.NotNil(t, gosparse.Pagination)
-	require.Len(t, gosparse.Pagination, 2) // page number and page size
+	require.Len(t, gosparse.Pagination, 3) // page number, page size and offset
 	require.NotNil(t, gosparse.Sort)
-	require.Len(t, gosparse.Sort, 2) // only fields with "sort" tag
+	require.Len(t, gosparse.Sort.Accepted, 2) // only fields with "sort" tag
+}
+
+func TestExtractCycles(t *testing.T) {
+	gosparse, err := Extract(Post{})
+	require.Nil(t, err)
+
+	require.Equal(t, []string{
+		"author",
+		"author.posts",
+		"comments",
+		"comments.author",
+		"comments.author.posts",
+	}, gosparse.Include.Paths())
+	require.False(t, gosparse.Include.Has("author.posts.author"))
+}
+
+func TestExtractFieldsets(t *testing.T) {
+	gosparse, err := Extract(Post{})
+	require.Nil(t, err)
+
+	require.Equal(t, "posts", gosparse.Fieldset.Primary)
+	require.Equal(t, sparsefieldsets.Resources{
+		"posts":    {"id": {}, "title": {}, "author": {}, "comments": {}},
+		"people":   {"name": {}, "posts": {}},
+		"comments": {"body": {}, "author": {}},
+	}, gosparse.Fieldset.Resources)
+
+	testtable := []struct {
+		desc  string
+		query url.Values
+		err   error
+	}{
+		{
+			desc:  "should accept fields of each resource type",
+			query: url.Values{"fields": {"title"}, "fields[people]": {"name"}, "fields[comments]": {"body,author"}},
+		},
+		{
+			desc:  "should accept empty fields",
+			query: url.Values{"fields[people]": {""}},
+		},
+		{
+			desc:  "should fail with unknown field",
+			query: url.Values{"fields[comments]": {"body,bogus"}},
+			err:   fmt.Errorf("unsupported field bogus on resource comments"),
+		},
+		{
+			desc:  "should fail with unknown resource type",
+			query: url.Values{"fields[author]": {"name"}},
+			err:   fmt.Errorf("unsupported field resource: author"),
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := gosparse.Handle(context.Background(), tt.query)
+			require.EqualValues(t, tt.err, err)
+		})
+	}
+}
+
+func TestExtractTypeName(t *testing.T) {
+	testtable := []struct {
+		desc    string
+		model   any
+		options []GosparseOpt
+		primary string
+	}{
+		{
+			desc:    "should fallback to root type",
+			model:   Dummy{},
+			primary: ROOT_TYPE,
+		},
+		{
+			desc:    "should get type from blank field tag",
+			model:   Post{},
+			primary: "posts",
+		},
+		{
+			desc:    "should get type from TypeName method",
+			model:   &Author{},
+			primary: "people",
+		},
+		{
+			desc:    "should get type from option",
+			model:   Post{},
+			options: []GosparseOpt{TypeName("articles")},
+			primary: "articles",
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			gosparse, err := Extract(tt.model, tt.options...)
+			require.Nil(t, err)
+			require.Equal(t, tt.primary, gosparse.Fieldset.Primary)
+			require.Contains(t, gosparse.Fieldset.Resources, tt.primary)
+			require.NotContains(t, gosparse.Fieldset.Resources, "")
+		})
+	}
+
+	t.Run("should treat bare fields as primary type", func(t *testing.T) {
+		gosparse, err := Extract(Post{}, TypeName("articles"))
+		require.Nil(t, err)
+
+		ctx, err := gosparse.Handle(context.Background(), url.Values{
+			"fields":           {"title"},
+			"fields[articles]": {"id"},
+		})
+		require.Nil(t, err)
+		require.ElementsMatch(t, []string{"title", "id"}, gosparse.Fieldset.Get(ctx, "articles"))
+		require.ElementsMatch(t, []string{"title", "id"}, gosparse.Fieldset.Get(ctx, ""))
+	})
+}
+
+func TestExtractAlwaysAndDefault(t *testing.T) {
+	type Article struct {
+		_         struct{}  `gosparse:"type:articles"`
+		ID        int       `gosparse:"name:id;always"`
+		Title     string    `gosparse:"name:title;select;default"`
+		Body      string    `gosparse:"name:body;select"`
+		UpdatedAt time.Time `gosparse:"name:updated_at;always"`
+	}
+
+	gosparse, err := Extract(Article{})
+	require.Nil(t, err)
+
+	ctx, err := gosparse.Handle(context.Background(), url.Values{"fields[articles]": {"body"}})
+	require.Nil(t, err)
+	require.Equal(t, []string{"body", "id", "updated_at"}, gosparse.Fieldset.Get(ctx, "articles"))
+
+	ctx, err = gosparse.Handle(context.Background(), url.Values{})
+	require.Nil(t, err)
+	require.Equal(t, []string{"title", "id", "updated_at"}, gosparse.Fieldset.Get(ctx, "articles"))
+}
+
+func TestExtractNestedFields(t *testing.T) {
+	gosparse, err := Extract(Post{}, NestedFields())
+	require.Nil(t, err)
+
+	t.Run("should select nested fields and imply includes", func(t *testing.T) {
+		ctx, err := gosparse.Handle(context.Background(), url.Values{
+			"fields":  {"title,author.name,comments.author.name"},
+			"include": {"comments"},
+		})
+		require.Nil(t, err)
+
+		require.Equal(t, sparsefieldsets.Selection{
+			"title":  {},
+			"author": {"name": {}},
+			"comments": {
+				"author": {"name": {}},
+			},
+		}, gosparse.Fieldset.GetSelection(ctx))
+		require.Equal(t, []string{"author", "comments", "comments.author"}, gosparse.Include.Get(ctx))
+	})
+
+	t.Run("should fail naming invalid segment", func(t *testing.T) {
+		_, err := gosparse.Handle(context.Background(), url.Values{"fields": {"author.bogus"}})
+		require.EqualError(t, err, "unsupported field bogus on path author.bogus")
+	})
+
+	t.Run("should fail selecting through non relation", func(t *testing.T) {
+		_, err := gosparse.Handle(context.Background(), url.Values{"fields": {"title.name"}})
+		require.EqualError(t, err, "unsupported field name on path title.name")
+	})
+}
+
+func TestExtractAlias(t *testing.T) {
+	gosparse, err := Extract(Post{})
+	require.Nil(t, err)
+	require.Equal(t, map[string]string{"commentAuthors": "comments.author"}, gosparse.Include.Aliases)
+
+	ctx, err := gosparse.Handle(context.Background(), url.Values{"include": {"commentAuthors"}})
+	require.Nil(t, err)
+	require.Equal(t, []string{"comments", "comments.author"}, gosparse.Include.Get(ctx))
+	require.Equal(t, "commentAuthors", gosparse.Include.GetRelations(ctx)[1].Name())
+}
+
+func TestExtractWithOptions(t *testing.T) {
+	gosparse, err := Extract(&Post{}, MaxIncludeDepth(2), MaxIncludePaths(2))
+	require.Nil(t, err)
+
+	_, err = gosparse.Handle(context.Background(), url.Values{"include": {"comments.author.posts"}})
+	require.EqualError(t, err, "include relation comments.author.posts exceeds max depth of 2")
+
+	_, err = gosparse.Handle(context.Background(), url.Values{"include": {"author,comments,author.posts"}})
+	require.EqualError(t, err, "include exceeds max of 2 relation paths")
+
+	_, err = gosparse.Handle(context.Background(), url.Values{"include": {"comments.author"}})
+	require.Nil(t, err)
+}
+
+func TestExtractInvalid(t *testing.T) {
+	_, err := Extract("not a struct")
+	require.EqualError(t, err, "can extract from structs only")
+
+	type invalidRelation struct {
+		Name string `gosparse:"name:name;relation"`
+	}
+
+	_, err = Extract(invalidRelation{})
+	require.EqualError(t, err, "relation Name should be a struct")
 }
 
 func TestExtractor(t *testing.T) {
 	testtable := []struct {
 		desc   string
 		tag    string
-		expect config
+		expect Field
 	}{
 		{
 			desc:   "should extract tag",
 			tag:    `name:title`,
-			expect: config{Name: "title"},
+			expect: Field{Name: "title"},
+		},
+		{
+			desc:   "should extract alias",
+			tag:    `name:author;relation;alias:commentAuthors`,
+			expect: Field{Name: "author", Select: true, Relation: true, Alias: "commentAuthors"},
+		},
+		{
+			desc:   "should extract column",
+			tag:    `name:created_at;select;column:created_ts`,
+			expect: Field{Name: "created_at", Select: true, Column: "created_ts"},
+		},
+		{
+			desc:   "should extract always and default as selectable",
+			tag:    `name:id;always;default`,
+			expect: Field{Name: "id", Select: true, Always: true, Default: true},
 		},
 		{
 			desc: "should extract tag from anywhere",
 			tag:  `select;sort;name:title;relation`,
-			expect: config{
+			expect: Field{
 				Name:     "title",
 				Select:   true,
 				Sort:     true,
@@ -12,23 +12,47 @@ import (
 )
 
 // Gosparse contém a configuração base de parâmetros aceitos
+//
+// O valor zero de Gosparse pode receber as opções diretamente:
+//
+//	var g gosparse.Gosparse
+//	gosparse.AcceptFields("articles")(&g)
 type Gosparse struct {
 	Include    include.Includes
 	Fieldset   sparsefieldsets.Fieldset
-	Filter     filter.Filters
+	Filter     filter.Filter
 	Pagination pagination.Pagination
-	Sort       sort.Sort
+	Sort       sort.Sorter
+	// Storage é o mapeamento de armazenamento dos campos configurado pelas
+	// opções MapColumn, VirtualField e ComputedField (veja Compile)
+	Storage Mapping
 }
 
 // Handle recebe o contexto e a querystring da request e
 // extraí todos os parâmetros de filtro, seleção e ordenação.
 //
+// Com a seleção aninhada habilitada (NestedFields) os relacionamentos
+// implícitos nos campos selecionados também são incluídos.
+//
 //   - Include
 //   - Fieldset
 //   - Filter
 //   - Pagination
 //   - Sort
+//
+// O resultado também é armazenado no contexto como um Query, recuperado
+// com FromContext sem a necessidade do Gosparse.
 func (g Gosparse) Handle(ctx context.Context, query url.Values) (context.Context, error) {
+	ctx, err := g.handle(ctx, query)
+	if err != nil {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, CtxKey{}, g.query(ctx)), nil
+}
+
+// handle extraí cada um dos parâmetros da querystring para o contexto.
+func (g Gosparse) handle(ctx context.Context, query url.Values) (context.Context, error) {
 	ctx, err := g.Include.Handle(ctx, query)
 	if err != nil {
 		return ctx, err
@@ -39,6 +63,13 @@ func (g Gosparse) Handle(ctx context.Context, query url.Values) (context.Context
 		return ctx, err
 	}
 
+	if implied := g.Fieldset.Implied(ctx); len(implied) > 0 {
+		ctx, err = g.Include.Merge(ctx, implied)
+		if err != nil {
+			return ctx, err
+		}
+	}
+
 	ctx, err = g.Filter.Handle(ctx, query)
 	if err != nil {
 		return ctx, err
@@ -63,43 +94,160 @@ type GosparseOpt func(*Gosparse)
 
 func AcceptRelations(rels ...string) GosparseOpt {
 	return func(g *Gosparse) {
-		if g.Include == nil {
-			g.Include = *include.New(include.AcceptRel(rels...))
-			return
-		}
-
 		for _, rel := range rels {
 			g.Include.AddRel(rel)
 		}
 	}
 }
 
-func AcceptFields(fields ...string) GosparseOpt {
+// AliasRelation expõe um caminho de relacionamento com um nome alternativo
+// no parâmetro "include".
+//
+//	gosparse.AliasRelation("commentAuthors", "comments.author")
+//	// include=commentAuthors
+func AliasRelation(alias, path string) GosparseOpt {
 	return func(g *Gosparse) {
-		if g.Fieldset == nil {
-			g.Fieldset = *sparsefieldsets.New(sparsefieldsets.AcceptField(fields...))
-			return
-		}
+		include.Alias(alias, path)(&g.Include)
+	}
+}
+
+// MaxIncludeDepth limita a quantidade de relacionamentos em um mesmo
+// caminho do parâmetro "include".
+//
+//	include=comments.author // profundidade 2
+func MaxIncludeDepth(depth int) GosparseOpt {
+	return func(g *Gosparse) {
+		include.MaxDepth(depth)(&g.Include)
+	}
+}
 
+// MaxIncludePaths limita a quantidade de caminhos distintos solicitados
+// no parâmetro "include".
+func MaxIncludePaths(paths int) GosparseOpt {
+	return func(g *Gosparse) {
+		include.MaxPaths(paths)(&g.Include)
+	}
+}
+
+func AcceptFields(fields ...string) GosparseOpt {
+	return func(g *Gosparse) {
 		for _, field := range fields {
 			g.Fieldset.AddField(field)
 		}
 	}
 }
 
-func AcceptFilters(filters ...string) GosparseOpt {
+// AcceptFieldset recebe o tipo de recurso e os atributos aceitos para ele
+// no parâmetro "fields[TYPE]".
+//
+//	gosparse.AcceptFieldset("articles", "title", "body")
+//	// fields[articles]=title,body
+func AcceptFieldset(typ string, attrs ...string) GosparseOpt {
 	return func(g *Gosparse) {
-		if g.Filter == nil {
-			g.Filter = *filter.New(filter.AcceptField(filters...))
-			return
+		g.Fieldset.AddAttributes(typ, attrs...)
+	}
+}
+
+// AlwaysFields recebe o tipo de recurso e os atributos que sempre serão
+// retornados no parâmetro "fields", mesmo que não sejam solicitados.
+//
+//	gosparse.AlwaysFields("articles", "id", "updated_at")
+func AlwaysFields(typ string, attrs ...string) GosparseOpt {
+	return func(g *Gosparse) {
+		sparsefieldsets.Always(typ, attrs...)(&g.Fieldset)
+	}
+}
+
+// DefaultFields recebe o tipo de recurso e os atributos retornados quando o
+// tipo não é solicitado no parâmetro "fields".
+func DefaultFields(typ string, attrs ...string) GosparseOpt {
+	return func(g *Gosparse) {
+		sparsefieldsets.Defaults(typ, attrs...)(&g.Fieldset)
+	}
+}
+
+// TypeName define o tipo de recurso dos dados primários. O parâmetro
+// "fields" sem tipo é tratado como "fields[TYPE]" do tipo definido.
+//
+//	gosparse.TypeName("articles")
+//	// fields=title é o mesmo que fields[articles]=title
+func TypeName(name string) GosparseOpt {
+	return func(g *Gosparse) {
+		sparsefieldsets.PrimaryType(name)(&g.Fieldset)
+		include.Resource(name)(&g.Include)
+		filter.Resource(name)(&g.Filter)
+		sort.Resource(name)(&g.Sort)
+	}
+}
+
+// Authorize recebe o Authorizer consultado para cada campo selecionado,
+// filtrado, ordenado ou incluído e a política aplicada quando o campo é
+// negado:
+//
+//   - REJECT: a request é rejeitada com um ForbiddenError (403)
+//   - STRIP: o campo é removido silenciosamente
+func Authorize(authorizer Authorizer, policy Policy) GosparseOpt {
+	return func(g *Gosparse) {
+		include.Authorize(authorizer, policy)(&g.Include)
+		sparsefieldsets.Authorize(authorizer, policy)(&g.Fieldset)
+		filter.Authorize(authorizer, policy)(&g.Filter)
+		sort.Authorize(authorizer, policy)(&g.Sort)
+	}
+}
+
+// NestedFields habilita a seleção aninhada com caminhos separados por
+// pontos no parâmetro "fields" sem tipo:
+//
+//	fields=title,author.name,author.email
+//
+// Os relacionamentos dos caminhos selecionados são incluídos
+// implicitamente, como se fossem solicitados no parâmetro "include".
+func NestedFields() GosparseOpt {
+	return func(g *Gosparse) {
+		sparsefieldsets.NestedPaths()(&g.Fieldset)
+	}
+}
+
+// AcceptPaths recebe os caminhos aceitos na seleção aninhada a partir dos
+// dados primários (veja NestedFields). Compile registra os caminhos de
+// todos os campos selecionáveis da estrutura.
+//
+//	gosparse.AcceptPaths("title", "author", "author.name")
+func AcceptPaths(paths ...string) GosparseOpt {
+	return func(g *Gosparse) {
+		for _, path := range paths {
+			g.Fieldset.AddPath(path)
 		}
+	}
+}
 
+func AcceptFilters(filters ...string) GosparseOpt {
+	return func(g *Gosparse) {
 		for _, filter := range filters {
 			g.Filter.AddFilter(filter)
 		}
 	}
 }
 
+// FilterExpressions habilita o parâmetro "filter" sem colchetes com uma
+// expressão no formato AIP-160, como alternativa aos parâmetros
+// "filter[FIELD_PREDICATE]":
+//
+//	filter=price > 10 AND (status = "open" OR status = "draft")
+//	// filter[price_gt]=10&filter[status_in]=open,draft
+//
+// Os campos aceitos, a autorização e os predicados são os mesmos dos
+// parâmetros com colchetes. As condições sem equivalente, como OR entre
+// campos diferentes, ficam em Query.Condition e os backends consomem a
+// árvore completa de Query.Where. Campos negados pelo Guard com STRIP
+// dentro de OR ou NOT são rejeitados, já que removê-los mudaria o
+// resultado.
+func FilterExpressions() GosparseOpt {
+	return func(g *Gosparse) {
+		filter.Expressions()(&g.Filter)
+	}
+}
+
 func AcceptPagination(size uint32) GosparseOpt {
 	return func(g *Gosparse) {
 		if g.Pagination == nil {
@@ -107,17 +255,35 @@ func AcceptPagination(size uint32) GosparseOpt {
 			return
 		}
 
-		g.Pagination[pagination.SIZE] = int(size)
+		// a configuração pode estar compartilhada com outro Gosparse,
+		// portanto o tamanho é alterado em uma cópia
+		paginate := g.Pagination.Copy()
+		paginate[pagination.SIZE] = int(size)
+		g.Pagination = paginate
 	}
 }
 
-func AcceptSortBy(fields ...string) GosparseOpt {
+// MaxPageSize limita o tamanho solicitado no parâmetro "page[size]".
+// Requests com um tamanho maior são rejeitadas.
+//
+//	page[size]=500 // pagination param size exceeds max of 100
+func MaxPageSize(size uint32) GosparseOpt {
 	return func(g *Gosparse) {
-		if g.Sort == nil {
-			g.Sort = *sort.New(sort.AcceptField(fields...))
+		if g.Pagination == nil {
+			g.Pagination = *pagination.New(pagination.MaxPageSize(size))
 			return
 		}
 
+		// a configuração pode estar compartilhada com outro Gosparse,
+		// portanto o limite é alterado em uma cópia
+		paginate := g.Pagination.Copy()
+		pagination.MaxPageSize(size)(&paginate)
+		g.Pagination = paginate
+	}
+}
+
+func AcceptSortBy(fields ...string) GosparseOpt {
+	return func(g *Gosparse) {
 		for _, field := range fields {
 			g.Sort.AddField(field)
 		}
@@ -126,16 +292,72 @@ func AcceptSortBy(fields ...string) GosparseOpt {
 
 // Constructor --------------------------
 
+// New recebe as opções e devolve o Gosparse com a configuração congelada
+// (veja Builder).
 func New(options ...GosparseOpt) Gosparse {
-	gosparse := Gosparse{}
+	return NewBuilder(options...).Build()
+}
+
+// freeze congela a configuração de todos os parâmetros, após congelado
+// o Gosparse pode ser compartilhado entre goroutines.
+//
+// Os maps da configuração são copiados, portanto o Gosparse congelado não
+// compartilha nenhum map com as opções ou com outros Gosparse.
+func (g *Gosparse) freeze() {
+	g.Include.Freeze()
+	g.Fieldset.Freeze()
+	g.Filter.Freeze()
+	g.Sort.Freeze()
+	g.Pagination = g.Pagination.Copy()
+	g.Storage = g.Storage.clone()
+}
 
+// Builder acumula as opções de configuração de um Gosparse.
+//
+// Cada chamada de Build monta um novo Gosparse com a configuração
+// congelada, ou seja, os métodos Add* entram em pânico, os maps são
+// copiados e o Gosparse pode ser compartilhado entre goroutines:
+//
+//	builder := gosparse.NewBuilder(gosparse.AcceptFields("title"))
+//	gs := builder.With(gosparse.AcceptSortBy("created_at")).Build()
+//
+// Alterações no Builder após o Build não afetam os Gosparse já montados.
+// O Builder não deve ser utilizado por mais de uma goroutine ao mesmo tempo.
+//
+// Os campos exportados do Gosparse congelado, como Include.Relations ou
+// Pagination, continuam acessíveis somente para leitura: escritas diretas
+// nesses maps não são verificadas e não devem ser feitas após o Build.
+type Builder struct {
+	options []GosparseOpt
+}
+
+// NewBuilder recebe as opções iniciais e devolve um novo Builder.
+func NewBuilder(options ...GosparseOpt) *Builder {
+	return (&Builder{}).With(options...)
+}
+
+// With adiciona as opções ao Builder.
+func (b *Builder) With(options ...GosparseOpt) *Builder {
 	for _, opt := range options {
 		if opt == nil {
 			continue
 		}
 
+		b.options = append(b.options, opt)
+	}
+
+	return b
+}
+
+// Build monta um novo Gosparse a partir das opções acumuladas e congela
+// sua configuração.
+func (b *Builder) Build() Gosparse {
+	gosparse := Gosparse{}
+
+	for _, opt := range b.options {
 		opt(&gosparse)
 	}
 
+	gosparse.freeze()
 	return gosparse
 }
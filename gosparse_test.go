@@ -2,24 +2,30 @@ package gosparse
 
 import (
 	"context"
+	"fmt"
 	"net/url"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/include"
 	"github.com/jeanmolossi/gosparse/internal/pagination"
 	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestHandle(t *testing.T) {
 	query := url.Values{
-		"include":                  {"nested"},
-		"fields":                   {"title"},
-		"fields[nested]":           {"dummy"},
-		"filter[created_at_start]": {"2023-01-01"},
-		"page[number]":             {"1"},
-		"page[size]":               {"15"},
-		"sort":                     {"-created_at"},
+		"include":             {"nested"},
+		"fields":              {"title"},
+		"fields[nested]":      {"dummy"},
+		"filter[title_start]": {"gosparse"},
+		"page[number]":        {"1"},
+		"page[size]":          {"15"},
+		"sort":                {"-created_at"},
 	}
 
 	gosparse, err := Extract(Dummy{})
@@ -37,10 +43,10 @@ func TestHandle(t *testing.T) {
 	require.Contains(t, gosparse.Fieldset.GetAll(ctx), "root")
 
 	// Filter assertions
-	require.Contains(t, gosparse.Filter.GetAll(ctx), "created_at")
+	require.Contains(t, gosparse.Filter.GetAll(ctx), "title")
 	require.EqualValues(t,
-		filter.Field{Predicate: filter.START, Values: []string{"2023-01-01"}},
-		gosparse.Filter.Get(ctx, "created_at"),
+		filter.Field{Predicate: filter.START, Values: []string{"gosparse"}},
+		gosparse.Filter.Get(ctx, "title"),
 	)
 
 	// Pagination assertions
@@ -50,3 +56,372 @@ func TestHandle(t *testing.T) {
 	// Sort assertions
 	require.Equal(t, sort.DESC, gosparse.Sort.Get(ctx, "created_at"))
 }
+
+func TestAuthorize(t *testing.T) {
+	type Employee struct {
+		Name    string  `gosparse:"name:name;select;sort;filter"`
+		Salary  int     `gosparse:"name:salary;select;sort;filter"`
+		Manager *Nested `gosparse:"name:manager;relation"`
+	}
+
+	onlyPublic := AuthorizerFunc(func(ctx context.Context, op Operation, typ, field string) error {
+		if field == "salary" || field == "manager" {
+			return fmt.Errorf("%s %s is restricted", op, field)
+		}
+
+		return nil
+	})
+
+	testtable := []struct {
+		desc  string
+		query url.Values
+		err   error
+	}{
+		{
+			desc:  "should reject forbidden select",
+			query: url.Values{"fields[employees]": {"name,salary"}},
+			err:   fmt.Errorf("forbidden select on field salary of resource employees"),
+		},
+		{
+			desc:  "should reject forbidden filter",
+			query: url.Values{"filter[salary_gte]": {"1000"}},
+			err:   fmt.Errorf("forbidden filter on field salary of resource employees"),
+		},
+		{
+			desc:  "should reject forbidden sort",
+			query: url.Values{"sort": {"-salary"}},
+			err:   fmt.Errorf("forbidden sort on field salary of resource employees"),
+		},
+		{
+			desc:  "should reject forbidden include",
+			query: url.Values{"include": {"manager"}},
+			err:   fmt.Errorf("forbidden include on field manager of resource employees"),
+		},
+		{
+			desc:  "should accept allowed fields",
+			query: url.Values{"fields[employees]": {"name"}, "sort": {"name"}, "filter[name]": {"john"}},
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			gs, err := Extract(Employee{}, TypeName("employees"), Authorize(onlyPublic, REJECT))
+			require.Nil(t, err)
+
+			_, err = gs.Handle(context.Background(), tt.query)
+			if tt.err == nil {
+				require.Nil(t, err)
+				return
+			}
+
+			require.EqualError(t, err, tt.err.Error())
+			require.ErrorIs(t, err, ErrForbidden)
+		})
+	}
+
+	t.Run("should strip forbidden fields", func(t *testing.T) {
+		gs, err := Extract(Employee{}, TypeName("employees"), Authorize(onlyPublic, STRIP))
+		require.Nil(t, err)
+
+		ctx, err := gs.Handle(context.Background(), url.Values{
+			"fields[employees]":  {"name,salary"},
+			"filter[salary_gte]": {"1000"},
+			"filter[name]":       {"john"},
+			"sort":               {"-salary,name"},
+			"include":            {"manager"},
+		})
+		require.Nil(t, err)
+
+		require.Equal(t, []string{"name"}, gs.Fieldset.Get(ctx, "employees"))
+		require.NotContains(t, gs.Filter.GetAll(ctx), "salary")
+		require.Contains(t, gs.Filter.GetAll(ctx), "name")
+		require.NotContains(t, gs.Sort.GetAll(ctx), "salary")
+		require.Empty(t, gs.Include.Get(ctx))
+	})
+
+	t.Run("should reject forbidden fields inside OR", func(t *testing.T) {
+		gs, err := Extract(Employee{}, TypeName("employees"), Authorize(onlyPublic, STRIP), FilterExpressions())
+		require.Nil(t, err)
+
+		// remover o campo alteraria o significado da expressão
+		_, err = gs.Handle(context.Background(), url.Values{"filter": {`name = "john" OR salary >= 1000`}})
+		require.EqualError(t, err, "forbidden filter on field salary of resource employees")
+		require.ErrorIs(t, err, ErrForbidden)
+
+		ctx, err := gs.Handle(context.Background(), url.Values{"filter": {`salary >= 1000 AND name = "john"`}})
+		require.Nil(t, err)
+		require.Equal(t, filter.Filters{"name": {Predicate: EQ, Values: []string{"john"}}}, gs.Filter.GetAll(ctx))
+	})
+
+	t.Run("should strip forbidden fields from default selection", func(t *testing.T) {
+		gs, err := Extract(Employee{}, TypeName("employees"), Authorize(onlyPublic, REJECT))
+		require.Nil(t, err)
+
+		ctx, err := gs.Handle(context.Background(), url.Values{})
+		require.Nil(t, err)
+		require.Equal(t, []string{"name"}, gs.Fieldset.Get(ctx, "employees"))
+	})
+}
+
+func TestBuilder(t *testing.T) {
+	builder := NewBuilder(AcceptFields("articles"), AcceptSortBy("title"))
+	gs := builder.Build()
+
+	require.True(t, gs.Fieldset.Frozen())
+	require.Panics(t, func() { gs.Sort.AddField("created_at") })
+	require.Panics(t, func() { AcceptFilters("title")(&gs) })
+
+	// alterações no builder não afetam os Gosparse já montados
+	other := builder.With(AcceptSortBy("created_at"), nil).Build()
+	require.Contains(t, other.Sort.Accepted, "created_at")
+	require.NotContains(t, gs.Sort.Accepted, "created_at")
+
+	// o tamanho da página é alterado em uma cópia
+	resized := gs
+	AcceptPagination(50)(&resized)
+	require.Equal(t, 50, resized.Pagination[pagination.SIZE])
+	require.NotEqual(t, 50, gs.Pagination[pagination.SIZE])
+
+	// o limite da página também é alterado em uma cópia
+	limited := gs
+	MaxPageSize(20)(&limited)
+	_, err := limited.Parse(url.Values{"page[size]": {"21"}})
+	require.EqualError(t, err, "pagination param size exceeds max of 20")
+	_, err = gs.Parse(url.Values{"page[size]": {"21"}})
+	require.Nil(t, err)
+
+	// os maps das opções são copiados ao congelar
+	shared := *pagination.New()
+	frozen := New(func(g *Gosparse) { g.Pagination = shared })
+	shared[pagination.SIZE] = 99
+	require.Equal(t, 10, frozen.Pagination[pagination.SIZE])
+}
+
+func TestHandleConcurrent(t *testing.T) {
+	gs, err := Extract(Post{}, NestedFields(), MaxIncludeDepth(3))
+	require.Nil(t, err)
+	require.Panics(t, func() { gs.Include.AddRel("likes") })
+
+	query := url.Values{
+		"include":       {"commentAuthors"},
+		"fields":        {"title,author.name"},
+		"filter[id_in]": {"1,2"},
+		"page[size]":    {"5"},
+		"sort":          {"-id"},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			// require não pode ser utilizado fora da goroutine do teste
+			ctx, err := gs.Handle(context.Background(), query)
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			assert.Equal(t, []string{"author", "comments", "comments.author"}, gs.Include.Get(ctx))
+			assert.Equal(t, 5, gs.Pagination.Get(ctx, pagination.SIZE))
+		}()
+	}
+
+	wg.Wait()
+}
+
+func TestZeroValue(t *testing.T) {
+	var g Gosparse
+	AcceptFields("articles")(&g)
+	AcceptFieldset("people", "name")(&g)
+	AcceptRelations("author")(&g)
+	AcceptFilters("title")(&g)
+	AcceptSortBy("title")(&g)
+	AcceptPagination(10)(&g)
+
+	ctx, err := g.Handle(context.Background(), url.Values{
+		"include":        {"author"},
+		"fields[people]": {"name"},
+		"filter[title]":  {"gosparse"},
+		"page[number]":   {"2"},
+		"sort":           {"-title"},
+	})
+	require.Nil(t, err)
+
+	require.Equal(t, []string{"author"}, g.Include.Get(ctx))
+	require.Equal(t, []string{"name"}, g.Fieldset.Get(ctx, "people"))
+	require.Equal(t, []string{"gosparse"}, g.Filter.Get(ctx, "title").Values)
+	require.Equal(t, 2, g.Pagination.Get(ctx, pagination.NUMBER))
+	require.Equal(t, 10, g.Pagination.Get(ctx, pagination.SIZE))
+	require.Equal(t, sort.DESC, g.Sort.Get(ctx, "title"))
+}
+
+func TestAcceptPaths(t *testing.T) {
+	gs := New(
+		TypeName("articles"),
+		NestedFields(),
+		AcceptRelations("author"),
+		AcceptFieldset("articles", "title", "author"),
+		AcceptFieldset("author", "name"),
+		AcceptPaths("title", "author", "author.name"),
+	)
+
+	q, err := gs.Parse(url.Values{"fields": {"title,author.name"}})
+	require.Nil(t, err)
+	require.Equal(t, []include.Relation{{Path: "author"}}, q.Include)
+
+	_, err = gs.Parse(url.Values{"fields": {"author.email"}})
+	require.NotNil(t, err)
+}
+
+func TestFilterExpressions(t *testing.T) {
+	gs := New(AcceptFilters("price", "status"), FilterExpressions())
+
+	expression, err := gs.Parse(url.Values{"filter": {`price >= 10 AND (status = "open" OR status = "draft")`}})
+	require.Nil(t, err)
+
+	brackets, err := gs.Parse(url.Values{"filter[price_gte]": {"10"}, "filter[status_in]": {"open,draft"}})
+	require.Nil(t, err)
+
+	require.Equal(t, brackets.Filter, expression.Filter)
+	require.Equal(t, brackets.String(), expression.String())
+
+	_, err = New(AcceptFilters("price")).Parse(url.Values{"filter": {`price > 10`}})
+	require.NotNil(t, err)
+
+	t.Run("should round trip commas in quoted values", func(t *testing.T) {
+		query, err := gs.Parse(url.Values{"filter": {`status = "open,draft" AND (price = 1 OR status = "a,b")`}})
+		require.Nil(t, err)
+		require.Equal(t, filter.Filters{"status": {Predicate: EQ, Values: []string{"open,draft"}}}, query.Filter)
+
+		encoded, err := gs.Parse(query.Encode())
+		require.Nil(t, err)
+		require.Equal(t, query.Where(), encoded.Where())
+		require.Equal(t, query.String(), encoded.String())
+	})
+}
+
+func TestFilterExpressionTree(t *testing.T) {
+	type Issue struct {
+		Price    float64 `gosparse:"name:price;filter"`
+		Status   string  `gosparse:"name:status;filter"`
+		Archived bool    `gosparse:"name:archived;filter"`
+	}
+
+	gs, err := Extract(Issue{}, FilterExpressions())
+	require.Nil(t, err)
+
+	query, err := gs.Parse(url.Values{"filter": {`price > 10 AND status = "open" OR NOT archived`}})
+	require.Nil(t, err)
+
+	// OR tem precedência sobre AND
+	require.Equal(t, filter.Filters{"price": {Predicate: GT, Values: []string{"10"}}}, query.Filter)
+	require.Equal(t, filter.And(
+		filter.Leaf("price", filter.Field{Predicate: GT, Values: []string{"10"}}),
+		Condition{Operator: OR, Conditions: []Condition{
+			filter.Leaf("status", filter.Field{Predicate: EQ, Values: []string{"open"}}),
+			filter.Leaf("archived", filter.Field{Predicate: EQ, Values: []string{"false"}}),
+		}},
+	), query.Where())
+
+	// a árvore é codificada como expressão
+	encoded, err := gs.Parse(query.Encode())
+	require.Nil(t, err)
+	require.Equal(t, query.Where(), encoded.Where())
+	require.Equal(t, query.String(), encoded.String())
+
+	// os predicados de cada folha seguem o tipo do campo
+	_, err = gs.Parse(url.Values{"filter": {`price > 10 OR archived > false`}})
+	require.EqualError(t, err, "unsupported predicate gt on filter archived")
+}
+
+func TestFilterPredicates(t *testing.T) {
+	type Product struct {
+		Name     string    `gosparse:"name:name;filter"`
+		Price    int       `gosparse:"name:price;filter"`
+		Active   bool      `gosparse:"name:active;filter"`
+		Released time.Time `gosparse:"name:released;filter"`
+	}
+
+	gs, err := Extract(Product{}, AcceptFilters("custom"), FilterExpressions())
+	require.Nil(t, err)
+
+	testtable := []struct {
+		desc  string
+		query url.Values
+		err   error
+	}{
+		{desc: "should accept comparison on text", query: url.Values{"filter[name_gt]": {"m"}}},
+		{desc: "should accept range on number", query: url.Values{"filter[price_gte]": {"10"}}},
+		{desc: "should accept equality on boolean", query: url.Values{"filter[active]": {"true"}}},
+		{desc: "should accept any predicate without type", query: url.Values{"filter[custom_start]": {"x"}}},
+		{
+			desc:  "should reject prefix on number",
+			query: url.Values{"filter[price_start]": {"1"}},
+			err:   fmt.Errorf("unsupported predicate start on filter price"),
+		},
+		{
+			desc:  "should reject comparison on boolean",
+			query: url.Values{"filter[active_gt]": {"false"}},
+			err:   fmt.Errorf("unsupported predicate gt on filter active"),
+		},
+		{
+			desc:  "should reject blank on date",
+			query: url.Values{"filter[released_blank]": {"true"}},
+			err:   fmt.Errorf("unsupported predicate blank on filter released"),
+		},
+		{
+			desc:  "should reject predicate from expression",
+			query: url.Values{"filter": {`active > false`}},
+			err:   fmt.Errorf("unsupported predicate gt on filter active"),
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			_, err := gs.Parse(tt.query)
+			if tt.err == nil {
+				require.Nil(t, err)
+				return
+			}
+
+			require.EqualError(t, err, tt.err.Error())
+		})
+	}
+}
+
+func TestNestedFieldsTypedPrimary(t *testing.T) {
+	gs := New(
+		TypeName("articles"),
+		NestedFields(),
+		AcceptFieldset("articles", "title", "author"),
+		AcceptPaths("title", "author"),
+	)
+
+	_, err := gs.Parse(url.Values{"fields[articles]": {"bogus"}})
+	require.EqualError(t, err, "unsupported field bogus on path bogus")
+
+	_, err = gs.Parse(url.Values{"fields": {"bogus"}})
+	require.EqualError(t, err, "unsupported field bogus on path bogus")
+}
+
+func TestBareFieldsWithoutTypeName(t *testing.T) {
+	gs := New(AcceptFields("articles"))
+
+	q, err := gs.Parse(url.Values{"fields": {"title"}})
+	require.Nil(t, err)
+	require.Equal(t, []string{"title"}, q.Select(sparsefieldsets.PRIMARY))
+	require.Equal(t, "fields=title", q.String())
+
+	same, err := gs.Parse(q.Encode())
+	require.Nil(t, err)
+	require.Equal(t, q.Fields, same.Fields)
+}
+
+func TestAliasUnknownRelation(t *testing.T) {
+	gs := New(AcceptRelations("author"), AliasRelation("x", "nonexistent"))
+
+	_, err := gs.Parse(url.Values{"include": {"x"}})
+	require.EqualError(t, err, "include alias x targets unsupported relation nonexistent")
+}
@@ -0,0 +1,125 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Operation é um custom type para as operações que podem ser
+// autorizadas em um campo
+type Operation string
+
+// Policy é um custom type para a política aplicada quando o
+// acesso a um campo é negado
+type Policy byte
+
+const (
+	SELECT  Operation = "select"
+	FILTER  Operation = "filter"
+	SORT    Operation = "sort"
+	INCLUDE Operation = "include"
+)
+
+const (
+	// REJECT rejeita a request com um ForbiddenError
+	REJECT Policy = iota
+	// STRIP remove o campo negado silenciosamente
+	STRIP
+)
+
+// ErrForbidden é o erro base de todo ForbiddenError
+//
+//	errors.Is(err, authorization.ErrForbidden)
+var ErrForbidden = errors.New("forbidden")
+
+// Authorizer é consultado para cada campo solicitado pelo cliente.
+//
+// Allow deve devolver nil quando a operação no campo é permitida ou um
+// erro explicando o motivo quando é negada. O contexto recebido é o
+// contexto da request, permitindo recuperar o usuário autenticado.
+type Authorizer interface {
+	Allow(ctx context.Context, op Operation, resourceType, field string) error
+}
+
+// AuthorizerFunc permite utilizar uma função comum como Authorizer
+//
+//	authorization.AuthorizerFunc(func(ctx context.Context, op Operation, typ, field string) error {
+//		if field == "salary" && !isAdmin(ctx) {
+//			return fmt.Errorf("admins only")
+//		}
+//
+//		return nil
+//	})
+type AuthorizerFunc func(ctx context.Context, op Operation, resourceType, field string) error
+
+// Allow chama a própria função
+func (f AuthorizerFunc) Allow(ctx context.Context, op Operation, resourceType, field string) error {
+	return f(ctx, op, resourceType, field)
+}
+
+// ForbiddenError é o erro devolvido quando o acesso a um campo é negado
+// com a política REJECT. Deve ser respondido como 403 Forbidden.
+type ForbiddenError struct {
+	Op       Operation
+	Resource string
+	Field    string
+	// Err é o erro devolvido pelo Authorizer
+	Err error
+}
+
+func (e *ForbiddenError) Error() string {
+	return fmt.Sprintf("forbidden %s on field %s of resource %s", e.Op, e.Field, e.Resource)
+}
+
+// Unwrap devolve o erro do Authorizer
+func (e *ForbiddenError) Unwrap() error {
+	return e.Err
+}
+
+// Is permite que errors.Is(err, ErrForbidden) identifique o ForbiddenError
+func (e *ForbiddenError) Is(target error) bool {
+	return target == ErrForbidden
+}
+
+// StatusCode devolve o status HTTP correspondente ao erro
+func (e *ForbiddenError) StatusCode() int {
+	return http.StatusForbidden
+}
+
+// Guard armazena o Authorizer e a política aplicada pelos parâmetros
+// de busca.
+//
+// Um Guard zero valued permite qualquer campo.
+type Guard struct {
+	Authorizer Authorizer
+	Policy     Policy
+}
+
+// Check consulta o Authorizer para o campo.
+//
+// Devolve true quando o campo é permitido. Quando o campo é negado devolve
+// false e, para a política REJECT, um ForbiddenError. Para a política STRIP
+// nenhum erro é devolvido e o campo deve ser removido.
+func (g Guard) Check(ctx context.Context, op Operation, resourceType, field string) (bool, error) {
+	if g.Authorizer == nil {
+		return true, nil
+	}
+
+	err := g.Authorizer.Allow(ctx, op, resourceType, field)
+	if err == nil {
+		return true, nil
+	}
+
+	if g.Policy == STRIP {
+		return false, nil
+	}
+
+	var forbidden *ForbiddenError
+	if errors.As(err, &forbidden) {
+		return false, err
+	}
+
+	return false, &ForbiddenError{Op: op, Resource: resourceType, Field: field, Err: err}
+}
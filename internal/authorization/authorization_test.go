@@ -0,0 +1,82 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheck(t *testing.T) {
+	denySalary := AuthorizerFunc(func(ctx context.Context, op Operation, typ, field string) error {
+		if field == "salary" {
+			return fmt.Errorf("admins only")
+		}
+
+		return nil
+	})
+
+	testtable := []struct {
+		desc    string
+		guard   Guard
+		field   string
+		allowed bool
+		err     error
+	}{
+		{
+			desc:    "should allow anything without authorizer",
+			guard:   Guard{},
+			field:   "salary",
+			allowed: true,
+		},
+		{
+			desc:    "should allow permitted field",
+			guard:   Guard{Authorizer: denySalary},
+			field:   "name",
+			allowed: true,
+		},
+		{
+			desc:    "should reject forbidden field",
+			guard:   Guard{Authorizer: denySalary, Policy: REJECT},
+			field:   "salary",
+			allowed: false,
+			err: &ForbiddenError{
+				Op:       SELECT,
+				Resource: "people",
+				Field:    "salary",
+				Err:      fmt.Errorf("admins only"),
+			},
+		},
+		{
+			desc:    "should strip forbidden field",
+			guard:   Guard{Authorizer: denySalary, Policy: STRIP},
+			field:   "salary",
+			allowed: false,
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			allowed, err := tt.guard.Check(context.Background(), SELECT, "people", tt.field)
+
+			require.Equal(t, tt.allowed, allowed)
+			require.EqualValues(t, tt.err, err)
+		})
+	}
+}
+
+func TestForbiddenError(t *testing.T) {
+	cause := fmt.Errorf("admins only")
+	err := error(&ForbiddenError{Op: SORT, Resource: "people", Field: "salary", Err: cause})
+
+	require.EqualError(t, err, "forbidden sort on field salary of resource people")
+	require.True(t, errors.Is(err, ErrForbidden))
+	require.True(t, errors.Is(err, cause))
+
+	var forbidden *ForbiddenError
+	require.True(t, errors.As(err, &forbidden))
+	require.Equal(t, http.StatusForbidden, forbidden.StatusCode())
+}
@@ -0,0 +1,22 @@
+// Package authorization
+//
+// Alguns campos de um recurso (por exemplo "salary" ou "email") só podem ser
+// selecionados, filtrados, ordenados ou incluídos por determinados usuários.
+//
+// Um Authorizer é consultado pelos parâmetros "fields", "filter", "sort" e
+// "include" para cada campo solicitado pelo cliente. Quando o Authorizer nega
+// o acesso a um campo, a política (Policy) define se a request deve ser
+// rejeitada com um erro da classe 403 Forbidden (REJECT) ou se o campo deve
+// ser removido silenciosamente (STRIP).
+//
+//	GET /people?fields[people]=name,salary HTTP/1.1
+//	Accept: application/hal+json
+//
+// Com a política REJECT a request acima deve responder 403 Forbidden para um
+// usuário sem permissão ao campo "salary". Com a política STRIP somente o
+// campo "name" será retornado.
+//
+// # References
+//
+//   - https://jsonapi.org/format/#errors
+package authorization
@@ -0,0 +1,147 @@
+package backend
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/convert"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+)
+
+// Values converte os valores do filtro para o tipo do campo no Schema.
+// Sem Schema, ou para campos desconhecidos, os valores são mantidos como
+// texto.
+//
+// Os valores dos predicados de texto (START / END) e de presença (NULL /
+// NOT_NULL / BLANK) não são convertidos.
+func Values(schema *gosparse.Schema, field string, f filter.Field) ([]any, error) {
+	var t reflect.Type
+	if schema != nil {
+		if conf, exists := schema.Field(field); exists {
+			t = conf.Type
+		}
+	}
+
+	switch f.Predicate {
+	case filter.START, filter.END, filter.NULL, filter.NOT_NULL, filter.BLANK:
+		t = nil
+	}
+
+	values, err := convert.Values(t, f.Values)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %s: %w", field, err)
+	}
+
+	return values, nil
+}
+
+// Single devolve o único valor do filtro.
+func Single[T any](field string, predicate filter.Predicate, values []T) (T, error) {
+	if len(values) != 1 {
+		var zero T
+		return zero, fmt.Errorf("filter %s with predicate %s requires a single value", field, predicate)
+	}
+
+	return values[0], nil
+}
+
+// Flag devolve o valor booleano dos predicados de presença. Sem valor o
+// predicado é verdadeiro:
+//
+//	filter[deleted_at_null]        // true
+//	filter[deleted_at_null]=false  // false
+func Flag(field string, f filter.Field) (bool, error) {
+	if len(f.Values) == 0 || (len(f.Values) == 1 && f.Values[0] == "") {
+		return true, nil
+	}
+
+	value, err := Single(field, f.Predicate, f.Values)
+	if err != nil {
+		return false, err
+	}
+
+	set, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("filter %s with predicate %s requires a boolean value", field, f.Predicate)
+	}
+
+	return set, nil
+}
+
+// Path devolve o caminho do campo no documento a partir do mapeamento do
+// Schema. Sem Schema, ou para campos desconhecidos, o próprio nome do campo
+// é utilizado.
+//
+// Os campos virtuais (expressões SQL ou computados) não existem no
+// documento, portanto não podem ser consultados.
+func Path(schema *gosparse.Schema, field string) (string, error) {
+	if schema == nil {
+		return field, nil
+	}
+
+	storage, exists := schema.Storage(field)
+	if !exists {
+		return field, nil
+	}
+
+	if storage.Virtual() {
+		return "", fmt.Errorf("unsupported field %s: virtual fields are not stored", field)
+	}
+
+	return storage.Column, nil
+}
+
+// Paginate recebe o Page e devolve o deslocamento e o tamanho da página.
+//
+// Quando page[offset] é enviado ele é utilizado como deslocamento, caso
+// contrário o deslocamento é calculado a partir de page[number]. Sem
+// paginação configurada o tamanho é 0, ou seja, a consulta não deve ser
+// limitada.
+func Paginate(page pagination.Page) (offset, size int64) {
+	size = int64(page.Get(pagination.SIZE))
+	if size < 0 {
+		size = 0
+	}
+
+	if page.IsSet(pagination.OFFSET) {
+		offset = int64(page.Get(pagination.OFFSET))
+	} else if number := page.Get(pagination.NUMBER); number > 1 {
+		offset = int64(number-1) * size
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	return offset, size
+}
+
+// Paginated indica se o Page tem um tamanho configurado. Somente as
+// consultas sem paginação configurada não devem ser limitadas, um tamanho
+// 0 configurado não devolve nenhum item.
+func Paginated(page pagination.Page) bool {
+	return page.Get(pagination.SIZE) >= 0
+}
+
+// New recebe as opções de configuração e devolve o valor configurado,
+// ignorando as opções nil. É o construtor dos Builders dos backends:
+//
+//	func New(opt ...BuilderOpt) *Builder {
+//		return backend.New(opt...)
+//	}
+func New[T any, O ~func(*T)](opt ...O) *T {
+	value := new(T)
+
+	for _, o := range opt {
+		if o == nil {
+			continue
+		}
+
+		o(value)
+	}
+
+	return value
+}
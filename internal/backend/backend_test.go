@@ -0,0 +1,135 @@
+package backend
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/stretchr/testify/require"
+)
+
+type Article struct {
+	_     struct{} `gosparse:"type:articles"`
+	Title string   `gosparse:"name:title;select;filter;column:headline"`
+	Price float64  `gosparse:"name:price;select;filter"`
+}
+
+func TestValues(t *testing.T) {
+	schema := gosparse.MustCompile[Article]()
+
+	testtable := []struct {
+		desc   string
+		schema *gosparse.Schema
+		field  filter.Field
+		expect []any
+		err    error
+	}{
+		{
+			desc:   "should convert to field type",
+			schema: schema,
+			field:  filter.Field{Predicate: filter.IN, Values: []string{"1", "2.5"}},
+			expect: []any{1.0, 2.5},
+		},
+		{
+			desc:   "should keep text without schema",
+			field:  filter.Field{Predicate: filter.GT, Values: []string{"1"}},
+			expect: []any{"1"},
+		},
+		{
+			desc:   "should keep text predicates",
+			schema: schema,
+			field:  filter.Field{Predicate: filter.START, Values: []string{"1"}},
+			expect: []any{"1"},
+		},
+		{
+			desc:   "should fail invalid value",
+			schema: schema,
+			field:  filter.Field{Predicate: filter.GT, Values: []string{"one"}},
+			err:    fmt.Errorf("invalid filter price: value one is not a valid float64"),
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			values, err := Values(tt.schema, "price", tt.field)
+			if tt.err != nil {
+				require.EqualError(t, err, tt.err.Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tt.expect, values)
+		})
+	}
+}
+
+func TestFlag(t *testing.T) {
+	testtable := []struct {
+		desc   string
+		values []string
+		expect bool
+		err    error
+	}{
+		{desc: "should be true without value", values: nil, expect: true},
+		{desc: "should be true with blank value", values: []string{""}, expect: true},
+		{desc: "should parse value", values: []string{"false"}, expect: false},
+		{
+			desc:   "should fail with many values",
+			values: []string{"true", "false"},
+			err:    fmt.Errorf("filter deleted_at with predicate null requires a single value"),
+		},
+		{
+			desc:   "should fail with non boolean value",
+			values: []string{"maybe"},
+			err:    fmt.Errorf("filter deleted_at with predicate null requires a boolean value"),
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			flag, err := Flag("deleted_at", filter.Field{Predicate: filter.NULL, Values: tt.values})
+			if tt.err != nil {
+				require.EqualError(t, err, tt.err.Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tt.expect, flag)
+		})
+	}
+}
+
+func TestPath(t *testing.T) {
+	schema, err := gosparse.Compile(Article{}, gosparse.VirtualField("price", "amount * 2"))
+	require.Nil(t, err)
+
+	path, err := Path(schema, "title")
+	require.Nil(t, err)
+	require.Equal(t, "headline", path)
+
+	path, err = Path(nil, "title")
+	require.Nil(t, err)
+	require.Equal(t, "title", path)
+
+	_, err = Path(schema, "price")
+	require.EqualError(t, err, "unsupported field price: virtual fields are not stored")
+}
+
+func TestPaginate(t *testing.T) {
+	gs := gosparse.New(gosparse.AcceptPagination(10))
+
+	query, err := gs.Parse(url.Values{"page[number]": {"3"}})
+	require.Nil(t, err)
+
+	offset, size := Paginate(query.Page)
+	require.Equal(t, int64(20), offset)
+	require.Equal(t, int64(10), size)
+	require.True(t, Paginated(query.Page))
+
+	offset, size = Paginate(gosparse.Query{}.Page)
+	require.Zero(t, offset)
+	require.Zero(t, size)
+	require.False(t, Paginated(gosparse.Query{}.Page))
+}
@@ -0,0 +1,10 @@
+// Package backend
+//
+// Reúne a interpretação do gosparse.Query compartilhada pelos backends
+// (sqlexec, mongo, elastic e memory), que diferem somente na consulta
+// montada:
+//
+//	filter[price_gte]=10         // Values: []any{10.0}
+//	filter[deleted_at_null]      // Flag: true
+//	page[number]=3&page[size]=10 // Paginate: 20, 10
+package backend
@@ -0,0 +1,128 @@
+package convert
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Value recebe o tipo do campo e o valor em texto e devolve o valor
+// convertido para o tipo do campo.
+//
+// Referências são convertidas para o tipo referenciado e listas (slices /
+// arrays) para o tipo dos itens, uma vez que cada valor de um filtro é um
+// item. Datas (time.Time) são aceitas no formato time.RFC3339 ou
+// "2006-01-02".
+//
+// Caso o tipo seja nil ou não seja suportado o próprio texto é devolvido.
+func Value(t reflect.Type, raw string) (any, error) {
+	if t == nil {
+		return raw, nil
+	}
+
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		date, err := Time(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		return date, nil
+	}
+
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		value := reflect.New(t)
+		if err := value.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw)); err != nil {
+			return nil, fmt.Errorf("value %s is not a valid %s", raw, t)
+		}
+
+		return value.Elem().Interface(), nil
+	}
+
+	value, err := parse(t, raw)
+	if err != nil {
+		return nil, fmt.Errorf("value %s is not a valid %s", raw, t)
+	}
+
+	return value, nil
+}
+
+// Values converte cada um dos valores em texto (veja Value).
+func Values(t reflect.Type, raw []string) ([]any, error) {
+	values := make([]any, 0, len(raw))
+
+	for _, r := range raw {
+		value, err := Value(t, r)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// parse converte o texto para os tipos básicos, devolvendo o valor com o
+// tipo do campo (por exemplo int32 para um campo int32).
+func parse(t reflect.Type, raw string) (any, error) {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.ValueOf(n).Convert(t).Interface(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.ValueOf(n).Convert(t).Interface(), nil
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.ValueOf(n).Convert(t).Interface(), nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, err
+		}
+
+		return reflect.ValueOf(b).Convert(t).Interface(), nil
+	case reflect.String:
+		return reflect.ValueOf(raw).Convert(t).Interface(), nil
+	}
+
+	return raw, nil
+}
+
+// Time converte o texto para time.Time no formato time.RFC3339 ou somente
+// com a data ("2006-01-02").
+func Time(raw string) (time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, raw); err == nil {
+		return date, nil
+	}
+
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("value %s is not a valid %s", raw, timeType)
+	}
+
+	return date, nil
+}
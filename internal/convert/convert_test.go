@@ -0,0 +1,65 @@
+package convert
+
+import (
+	"fmt"
+	"net/netip"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue(t *testing.T) {
+	type Status string
+
+	testtable := []struct {
+		desc   string
+		typ    reflect.Type
+		raw    string
+		expect any
+		err    error
+	}{
+		{desc: "should keep text without type", typ: nil, raw: "10", expect: "10"},
+		{desc: "should convert int", typ: reflect.TypeOf(0), raw: "10", expect: 10},
+		{desc: "should convert int32 pointer", typ: reflect.TypeOf(new(int32)), raw: "-3", expect: int32(-3)},
+		{desc: "should convert slice item", typ: reflect.TypeOf([]uint8{}), raw: "255", expect: uint8(255)},
+		{desc: "should convert float", typ: reflect.TypeOf(0.0), raw: "1.5", expect: 1.5},
+		{desc: "should convert bool", typ: reflect.TypeOf(true), raw: "true", expect: true},
+		{desc: "should convert named string", typ: reflect.TypeOf(Status("")), raw: "draft", expect: Status("draft")},
+		{
+			desc:   "should convert date",
+			typ:    reflect.TypeOf(time.Time{}),
+			raw:    "2023-01-01",
+			expect: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:   "should convert datetime",
+			typ:    reflect.TypeOf(time.Time{}),
+			raw:    "2023-01-01T10:00:00Z",
+			expect: time.Date(2023, 1, 1, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:   "should convert text unmarshaler",
+			typ:    reflect.TypeOf(netip.Addr{}),
+			raw:    "127.0.0.1",
+			expect: netip.MustParseAddr("127.0.0.1"),
+		},
+		{desc: "should fail invalid int", typ: reflect.TypeOf(0), raw: "ten", err: fmt.Errorf("value ten is not a valid int")},
+		{desc: "should fail overflow", typ: reflect.TypeOf(int8(0)), raw: "300", err: fmt.Errorf("value 300 is not a valid int8")},
+		{desc: "should fail invalid date", typ: reflect.TypeOf(time.Time{}), raw: "yesterday", err: fmt.Errorf("value yesterday is not a valid time.Time")},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			value, err := Value(tt.typ, tt.raw)
+			if tt.err != nil {
+				require.EqualError(t, err, tt.err.Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tt.expect, value)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+// Package convert
+//
+// Os valores de um filtro são recebidos como texto na querystring:
+//
+//	filter[price_gte]=10
+//
+// Backends como MongoDB e Elasticsearch diferenciam o número 10 do texto
+// "10", portanto os valores precisam ser convertidos para o tipo do campo da
+// estrutura antes de montar a consulta.
+package convert
@@ -0,0 +1,166 @@
+package filter
+
+import (
+	stdsort "sort"
+)
+
+// Operator é um tipo para definir um enum dos operadores lógicos de uma
+// Condition
+type Operator int
+
+const (
+	// AND é atendida quando todas as Conditions são atendidas. É o operador
+	// da Condition zero valued, que sem Conditions é sempre atendida.
+	AND Operator = iota
+	// OR é atendida quando alguma das Conditions é atendida
+	OR
+	// NOT é atendida quando a única Condition não é atendida
+	NOT
+	// LEAF é o filtro de um único campo (Field e Filter)
+	LEAF
+)
+
+func (o Operator) String() string {
+	switch o {
+	case AND:
+		return "AND"
+	case OR:
+		return "OR"
+	case NOT:
+		return "NOT"
+	}
+
+	return ""
+}
+
+// Condition é um nó da árvore de condições do parâmetro "filter". As folhas
+// (LEAF) são os filtros de um único campo e os demais nós combinam as
+// condições de Conditions:
+//
+//	// price > 10 AND (status = "open" OR NOT archived)
+//	Condition{Operator: AND, Conditions: []Condition{
+//		{Operator: LEAF, Field: "price", Filter: Field{GT, []string{"10"}}},
+//		{Operator: OR, Conditions: []Condition{
+//			{Operator: LEAF, Field: "status", Filter: Field{EQ, []string{"open"}}},
+//			{Operator: LEAF, Field: "archived", Filter: Field{EQ, []string{"false"}}},
+//		}},
+//	}}
+//
+// Os parâmetros "filter[FIELD_PREDICATE]" são uma conjunção (AND) de folhas
+// (veja All).
+type Condition struct {
+	// Operator é o operador lógico do nó
+	Operator Operator
+	// Conditions são as condições combinadas por AND e OR, ou a única
+	// condição negada por NOT
+	Conditions []Condition
+	// Field é o campo filtrado pela folha
+	Field string
+	// Filter é o predicado e os valores da folha
+	Filter Field
+}
+
+// Leaf recebe o campo e o filtro e devolve a folha correspondente.
+func Leaf(field string, f Field) Condition {
+	return Condition{Operator: LEAF, Field: field, Filter: f}
+}
+
+// All recebe os filtros e devolve a conjunção (AND) das folhas, em ordem
+// alfabética dos campos.
+func All(filters Filters) Condition {
+	fields := make([]string, 0, len(filters))
+	for field := range filters {
+		fields = append(fields, field)
+	}
+
+	stdsort.Strings(fields)
+
+	and := Condition{Operator: AND}
+	for _, field := range fields {
+		and.Conditions = append(and.Conditions, Leaf(field, filters[field]))
+	}
+
+	return and
+}
+
+// And devolve a conjunção das condições. As conjunções recebidas são
+// unidas em uma única conjunção:
+//
+//	And(All(filters), Condition{}) // All(filters)
+func And(conditions ...Condition) Condition {
+	and := Condition{Operator: AND}
+
+	for _, c := range conditions {
+		if c.Operator == AND {
+			and.Conditions = append(and.Conditions, c.Conditions...)
+			continue
+		}
+
+		and.Conditions = append(and.Conditions, c)
+	}
+
+	return and
+}
+
+// Not devolve a negação da condição. A negação de NOT devolve a própria
+// condição negada.
+func Not(c Condition) Condition {
+	if c.Operator == NOT {
+		return c.Conditions[0]
+	}
+
+	return Condition{Operator: NOT, Conditions: []Condition{c}}
+}
+
+// Empty indica se a condição é uma conjunção sem condições, ou seja, se é
+// sempre atendida.
+func (c Condition) Empty() bool {
+	return c.Operator == AND && len(c.Conditions) == 0
+}
+
+// Fields devolve os campos filtrados pelas folhas da condição, sem
+// repetições e em ordem alfabética.
+func (c Condition) Fields() []string {
+	seen := make(map[string]struct{})
+	c.leaves(func(leaf Condition) {
+		seen[leaf.Field] = struct{}{}
+	})
+
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+
+	stdsort.Strings(fields)
+	return fields
+}
+
+// leaves percorre as folhas da condição.
+func (c Condition) leaves(visit func(leaf Condition)) {
+	if c.Operator == LEAF {
+		visit(c)
+		return
+	}
+
+	for _, nested := range c.Conditions {
+		nested.leaves(visit)
+	}
+}
+
+// split separa as folhas da conjunção, que são representadas pelos
+// parâmetros "filter[FIELD_PREDICATE]", das demais condições.
+func split(c Condition) (Filters, Condition) {
+	filters := make(Filters)
+	rest := Condition{Operator: AND}
+
+	for _, nested := range And(c).Conditions {
+		if nested.Operator == LEAF {
+			filters[nested.Field] = nested.Filter
+			continue
+		}
+
+		rest.Conditions = append(rest.Conditions, nested)
+	}
+
+	return filters, rest
+}
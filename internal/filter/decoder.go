@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -23,7 +24,12 @@ var (
 	//
 	// Se o campo não tiver predicado o regex não encontrará
 	// nada e retornará nil
-	filterMatcherWithPredicate = regexp.MustCompile(`^filter\[([a-zA-Z_0-9]+)_([a-zA-Z_0-9]+)\]`).FindStringSubmatch
+	//
+	// O campo pode ser um atributo de relacionamento, com os
+	// segmentos separados por pontos:
+	//
+	//  filter[author.name_start]=J
+	filterMatcherWithPredicate = regexp.MustCompile(`^filter\[(` + fieldPattern + `)_([a-zA-Z_0-9]+)\]`).FindStringSubmatch
 
 	// filterMatcherSimple
 	//
@@ -36,12 +42,22 @@ var (
 	//
 	//  matches[0] = filter[username]
 	//  matches[1] = username
-	filterMatcherSimple = regexp.MustCompile(`^filter\[([a-zA-Z_0-9]+)\]`).FindStringSubmatch
+	filterMatcherSimple = regexp.MustCompile(`^filter\[(` + fieldPattern + `)\]`).FindStringSubmatch
 )
 
+// fieldPattern é o formato do nome de um campo no parâmetro "filter",
+// com segmentos separados por pontos para atributos de relacionamento.
+const fieldPattern = `[a-zA-Z_0-9]+(?:\.[a-zA-Z_0-9]+)*`
+
 // extractFilter recebe a chave da querystring da
 // request e extrai o nome do campo e o predicado.
 //
+// Caso o sufixo após o último "_" não seja um predicado conhecido ele faz
+// parte do nome do campo:
+//
+//	filter[created_at_gte] // created_at, GTE
+//	filter[created_at]     // created_at, NONE
+//
 // para um formato inválido de chave, retorna uma string vazia, um NONE e um erro
 func extractFilter(f string) (string, Predicate, error) {
 	if f == SEARCH_PARAM {
@@ -64,7 +80,12 @@ func extractFilter(f string) (string, Predicate, error) {
 		return matches[1], NONE, nil
 	}
 
-	return matches[1], getPredicate(matches[2]), nil
+	predicate, found := lookupPredicate(matches[2])
+	if !found {
+		return matches[1] + "_" + matches[2], NONE, nil
+	}
+
+	return matches[1], predicate, nil
 }
 
 // resetValues recebe o slice de strings da query e
@@ -74,11 +95,70 @@ func extractFilter(f string) (string, Predicate, error) {
 //
 //	resetValues([]string{"john,anne", "paul"}) // []string{"john", "anne", "paul"}
 //	resetValues([]string{"john,anne"}) // []string{"john", "anne"}
+//	resetValues([]string{`a\,b,c`}) // []string{"a,b", "c"}
+//
+// Vírgulas precedidas de barra invertida fazem parte do valor (veja
+// Encode).
 func resetValues(v []string) []string {
 	joined := strings.Join(v, ",")
-	v = strings.Split(joined, ",")
+	values := make([]string, 0, 1)
+
+	var value strings.Builder
+	for i := 0; i < len(joined); i++ {
+		switch c := joined[i]; {
+		case c == '\\' && i+1 < len(joined) && (joined[i+1] == ',' || joined[i+1] == '\\'):
+			i++
+			value.WriteByte(joined[i])
+		case c == ',':
+			values = append(values, value.String())
+			value.Reset()
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	return append(values, value.String())
+}
+
+// escaper escapa as vírgulas e barras invertidas dos valores codificados
+var escaper = strings.NewReplacer(`\`, `\\`, `,`, `\,`)
+
+// unordered são os predicados em que a ordem dos valores não altera o
+// filtro, portanto os valores são codificados em ordem alfabética
+var unordered = map[Predicate]bool{NONE: true, EQ: true, NEQ: true, IN: true, NIN: true}
+
+// Encode recebe os filtros e devolve a query correspondente, inversa de
+// Decode:
+//
+//	Encode(Filters{"created_at": {GTE, []string{"2023-01-01"}}})
+//	// url.Values{"filter[created_at_gte]": {"2023-01-01"}}
+//
+// Os valores são unidos por vírgula, com vírgulas e barras invertidas dos
+// próprios valores escapadas com barra invertida (`a\,b`). Os valores de
+// predicados de igualdade (como IN e NIN) são ordenados, portanto a query
+// é canônica.
+func Encode(filters Filters) url.Values {
+	query := make(url.Values, len(filters))
+
+	for field, filter := range filters {
+		key := field
+		if suffix := filter.Predicate.String(); suffix != "" {
+			key = key + "_" + suffix
+		}
+
+		values := make([]string, 0, len(filter.Values))
+		for _, value := range filter.Values {
+			values = append(values, escaper.Replace(value))
+		}
+
+		if unordered[filter.Predicate] {
+			sort.Strings(values)
+		}
+
+		query.Set(SEARCH_PARAM+"["+key+"]", strings.Join(values, ","))
+	}
 
-	return v
+	return query
 }
 
 // Decode recebe a query e extrai os valores de campo e valores da query.
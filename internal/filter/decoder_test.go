@@ -36,6 +36,20 @@ func TestDecode(t *testing.T) {
 			err:      fmt.Errorf("filter has invalid format: field-invalid"),
 		},
 
+		{
+			desc:  "should extract relationship attribute with predicate",
+			query: url.Values{"filter[author.first_name_start]": {"J"}, "filter[comments.author.id]": {"1"}},
+			expected: Filters{
+				"author.first_name":  Field{START, []string{"J"}},
+				"comments.author.id": Field{NONE, []string{"1"}},
+			},
+		},
+		{
+			desc:     "should fail empty path segment",
+			query:    url.Values{"filter[author..name]": {"J"}},
+			expected: (Filters)(nil),
+			err:      fmt.Errorf("filter has invalid format: filter[author..name]"),
+		},
 		{
 			desc:  "should extract filter with predicate",
 			query: url.Values{"filter[username_in]": {"john,anne"}},
@@ -57,6 +71,35 @@ func TestDecode(t *testing.T) {
 				"username": Field{EQ, []string{"john", "anne"}},
 			},
 		},
+		{
+			desc:  "should keep unknown predicate as part of field",
+			query: url.Values{"filter[created_at]": {"2023-01-01"}, "filter[updated_at_gte]": {"2023-01-02"}},
+			expected: Filters{
+				"created_at": Field{NONE, []string{"2023-01-01"}},
+				"updated_at": Field{GTE, []string{"2023-01-02"}},
+			},
+		},
+		{
+			desc:  "should keep escaped comma in value",
+			query: url.Values{"filter[title]": {`a\,b`}},
+			expected: Filters{
+				"title": Field{NONE, []string{"a,b"}},
+			},
+		},
+		{
+			desc:  "should unescape backslash before comma",
+			query: url.Values{"filter[path_in]": {`C:\\,D:\`}},
+			expected: Filters{
+				"path": Field{IN, []string{`C:\`, `D:\`}},
+			},
+		},
+		{
+			desc:  "should keep backslash not followed by comma or backslash",
+			query: url.Values{"filter[path]": {`C:\tmp\go`}},
+			expected: Filters{
+				"path": Field{NONE, []string{`C:\tmp\go`}},
+			},
+		},
 		{
 			desc:     "should fail filter without field param",
 			query:    url.Values{"filter": {"anne"}},
@@ -74,3 +117,32 @@ func TestDecode(t *testing.T) {
 		})
 	}
 }
+
+func TestEncode(t *testing.T) {
+	filters := Filters{
+		"username":   Field{NONE, []string{"john", "anne"}},
+		"title":      Field{IN, []string{"go,rust", `c\`}},
+		"created_at": Field{NOT_NULL, []string{"true"}},
+		"age":        Field{GTE, []string{"18"}},
+	}
+
+	query := Encode(filters)
+	require.Equal(t, url.Values{
+		"filter[username]":           {"anne,john"},
+		"filter[title_in]":           {`c\\,go\,rust`},
+		"filter[created_at_notnull]": {"true"},
+		"filter[age_gte]":            {"18"},
+	}, query)
+
+	decoded, err := Decode(query)
+	require.Nil(t, err)
+	require.Equal(t, Filters{
+		"username":   Field{NONE, []string{"anne", "john"}},
+		"title":      Field{IN, []string{`c\`, "go,rust"}},
+		"created_at": Field{NOT_NULL, []string{"true"}},
+		"age":        Field{GTE, []string{"18"}},
+	}, decoded)
+
+	require.Equal(t, "in", IN.String())
+	require.Equal(t, "", NONE.String())
+}
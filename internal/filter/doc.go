@@ -34,9 +34,26 @@
 // os caracteres [az], o JSON:API reserva a capacidade de padronizar parâmetros
 // de consulta adicionais posteriormente sem entrar em conflito com as implementações existentes.
 //
+// # Expressões AIP-160
+//
+// Com Expressions habilitado, o parâmetro "filter" sem colchetes recebe uma
+// expressão AIP-160, convertida em uma árvore de condições (veja
+// ParseExpression e Condition). As folhas do AND principal são os mesmos
+// filtros dos parâmetros com colchetes:
+//
+//	filter=price > 10 AND (status = "open" OR status = "draft")
+//	// filter[price_gt]=10&filter[status_in]=open,draft
+//
+// Os demais operadores, como OR entre campos diferentes, ficam na árvore
+// (veja GetCondition):
+//
+//	filter=price > 10 AND status = "open" OR NOT archived
+//	// filter[price_gt]=10 AND (status = "open" OR archived = "false")
+//
 // # References
 //
 //   - https://docs.commercelayer.io/core/filtering-data
 //   - https://jsonapi.org/format/#fetching-filtering
 //   - https://jsonapi.org/format/#query-parameters-families
+//   - https://google.aip.dev/160
 package filter
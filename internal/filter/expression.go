@@ -0,0 +1,691 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Expressões AIP-160 ---------------------
+//
+// ParseExpression converte a gramática AIP-160 na árvore de condições
+// (veja Condition). As restrições são convertidas nas mesmas folhas dos
+// parâmetros "filter[FIELD_PREDICATE]":
+//
+//	price > 10 AND status = "open"      // filter[price_gt]=10&filter[status_eq]=open
+//	status = "open" OR status = "draft" // filter[status_in]=open,draft
+//	NOT archived                        // filter[archived_eq]=false
+//	deleted_at = null                   // filter[deleted_at_null]=true
+//	title = "Go*"                       // filter[title_start]=Go
+//
+// As demais combinações são mantidas como nós OR e NOT da árvore:
+//
+//	price > 10 AND status = "open" OR NOT archived
+//	// AND(price_gt, OR(status_eq, archived_eq))
+//
+// Assim como na AIP-160, OR tem precedência sobre AND.
+
+// comparators relaciona os comparadores com o predicado e o predicado da
+// restrição negada (NOT).
+var comparators = map[string][2]Predicate{
+	"=":  {EQ, NEQ},
+	"!=": {NEQ, EQ},
+	"<":  {LT, GTE},
+	"<=": {LTE, GT},
+	">":  {GT, LTE},
+	">=": {GTE, LT},
+}
+
+const (
+	// HAS é o comparador de presença, aceito somente com WILDCARD
+	//
+	//	deleted_at:*
+	HAS string = ":"
+	// WILDCARD é o curinga dos valores de texto
+	WILDCARD string = "*"
+	// NULL_VALUE é o valor sem aspas que representa a ausência de valor
+	NULL_VALUE string = "null"
+)
+
+var fieldMatcher = regexp.MustCompile(`^` + fieldPattern + `$`).MatchString
+
+// expression é um nó da expressão
+type expression interface{}
+
+// conjunction são as expressões unidas por AND
+type conjunction []expression
+
+// disjunction são as expressões unidas por OR
+type disjunction []expression
+
+// negation é a expressão negada por NOT ou "-"
+type negation struct {
+	expression
+}
+
+// restriction é a comparação de um campo com um valor. Sem comparador o
+// campo é tratado como booleano:
+//
+//	archived // archived = true
+type restriction struct {
+	field      string
+	comparator string
+	value      string
+	// quoted indica se o valor estava entre aspas, portanto null e *
+	// são tratados como texto
+	quoted bool
+}
+
+// token é um item léxico da expressão com a posição na expressão
+type token struct {
+	text string
+	// quoted indica se o texto estava entre aspas
+	quoted bool
+	// symbol indica se o token é um parêntese ou comparador
+	symbol bool
+	pos    int
+}
+
+// parser é o analisador sintático da expressão
+type parser struct {
+	tokens []token
+	next   int
+}
+
+// ParseExpression recebe uma expressão no formato AIP-160 e devolve a
+// conjunção (AND) das condições da expressão.
+//
+// Assim como nos parâmetros "filter[FIELD_PREDICATE]", cada campo pode ser
+// filtrado uma única vez nas folhas da conjunção. Dentro dos nós OR e NOT
+// os campos podem ser repetidos. Uma expressão vazia devolve uma conjunção
+// vazia.
+func ParseExpression(expr string) (Condition, error) {
+	tokens, err := lex(expr)
+	if err != nil {
+		return Condition{}, err
+	}
+
+	if len(tokens) == 0 {
+		return Condition{}, nil
+	}
+
+	p := &parser{tokens: tokens}
+
+	root, err := p.expression()
+	if err != nil {
+		return Condition{}, err
+	}
+
+	if p.next < len(p.tokens) {
+		return Condition{}, p.unexpected()
+	}
+
+	converted, err := convert(root, false)
+	if err != nil {
+		return Condition{}, err
+	}
+
+	and := And(converted)
+
+	seen := make(map[string]struct{}, len(and.Conditions))
+	for _, c := range and.Conditions {
+		if c.Operator != LEAF {
+			continue
+		}
+
+		if _, duplicate := seen[c.Field]; duplicate {
+			return Condition{}, fmt.Errorf("filter %s used more than once in expression", c.Field)
+		}
+
+		seen[c.Field] = struct{}{}
+	}
+
+	return and, nil
+}
+
+// Lexer ---------------------------------
+
+// lex separa a expressão em tokens: parênteses, comparadores, textos entre
+// aspas e textos sem aspas (campos, valores e palavras-chave).
+func lex(expr string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(expr); {
+		c := expr[i]
+
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+		case c == '(' || c == ')' || c == ':':
+			tokens = append(tokens, token{text: string(c), symbol: true, pos: i})
+			i++
+		case c == '=':
+			tokens = append(tokens, token{text: "=", symbol: true, pos: i})
+			i++
+		case c == '!' || c == '<' || c == '>':
+			op := string(c)
+			if i+1 < len(expr) && expr[i+1] == '=' {
+				op += "="
+			}
+
+			if op == "!" {
+				return nil, fmt.Errorf("invalid filter expression: unexpected %q at position %d", op, i)
+			}
+
+			tokens = append(tokens, token{text: op, symbol: true, pos: i})
+			i += len(op)
+		case c == '"' || c == '\'':
+			text, end, err := quoted(expr, i)
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token{text: text, quoted: true, pos: i})
+			i = end
+		default:
+			start := i
+			for i < len(expr) && !unicode.IsSpace(rune(expr[i])) && !strings.ContainsRune(`()=!<>:"'`, rune(expr[i])) {
+				i++
+			}
+
+			tokens = append(tokens, token{text: expr[start:i], pos: start})
+		}
+	}
+
+	return tokens, nil
+}
+
+// quoted devolve o texto entre aspas iniciado na posição start, sem as
+// aspas e com os escapes (\) resolvidos, e a posição seguinte às aspas.
+func quoted(expr string, start int) (string, int, error) {
+	quote := expr[start]
+
+	var text strings.Builder
+	for i := start + 1; i < len(expr); i++ {
+		switch expr[i] {
+		case '\\':
+			if i+1 < len(expr) {
+				i++
+				text.WriteByte(expr[i])
+			}
+		case quote:
+			return text.String(), i + 1, nil
+		default:
+			text.WriteByte(expr[i])
+		}
+	}
+
+	return "", 0, fmt.Errorf("invalid filter expression: unterminated string at position %d", start)
+}
+
+// Parser --------------------------------
+
+// expression: sequence { AND sequence }
+//
+// Sequências separadas somente por espaços também são unidas por AND.
+func (p *parser) expression() (expression, error) {
+	var and conjunction
+
+	for {
+		factor, err := p.factor()
+		if err != nil {
+			return nil, err
+		}
+
+		and = append(and, factor)
+
+		if p.keyword("AND") {
+			continue
+		}
+
+		if p.done() || (p.peek().symbol && p.peek().text == ")") {
+			break
+		}
+	}
+
+	if len(and) == 1 {
+		return and[0], nil
+	}
+
+	return and, nil
+}
+
+// factor: term { OR term }
+func (p *parser) factor() (expression, error) {
+	term, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+
+	or := disjunction{term}
+	for p.keyword("OR") {
+		term, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+
+		or = append(or, term)
+	}
+
+	if len(or) == 1 {
+		return or[0], nil
+	}
+
+	return or, nil
+}
+
+// term: [NOT | -] simple
+func (p *parser) term() (expression, error) {
+	if p.keyword("NOT") {
+		simple, err := p.simple()
+		if err != nil {
+			return nil, err
+		}
+
+		return negation{simple}, nil
+	}
+
+	// "-" seguido do campo, sem espaços, também nega a restrição
+	if p.done() {
+		return nil, p.unexpected()
+	}
+
+	if tok := p.peek(); !tok.quoted && !tok.symbol && len(tok.text) > 1 && tok.text[0] == '-' {
+		p.tokens[p.next].text = tok.text[1:]
+		p.tokens[p.next].pos++
+
+		simple, err := p.simple()
+		if err != nil {
+			return nil, err
+		}
+
+		return negation{simple}, nil
+	}
+
+	return p.simple()
+}
+
+// simple: restriction | ( expression )
+func (p *parser) simple() (expression, error) {
+	if p.done() {
+		return nil, p.unexpected()
+	}
+
+	if tok := p.peek(); tok.symbol && tok.text == "(" {
+		p.next++
+
+		expr, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.done() || !p.peek().symbol || p.peek().text != ")" {
+			return nil, p.unexpected()
+		}
+
+		p.next++
+		return expr, nil
+	}
+
+	return p.restriction()
+}
+
+// restriction: field [ comparator value ]
+func (p *parser) restriction() (expression, error) {
+	field := p.peek()
+	if field.quoted || field.symbol || !fieldMatcher(field.text) || isKeyword(field.text) {
+		return nil, p.unexpected()
+	}
+
+	p.next++
+
+	r := restriction{field: field.text}
+	if p.done() {
+		return r, nil
+	}
+
+	comparator := p.peek()
+	if !comparator.symbol || comparator.text == "(" || comparator.text == ")" {
+		return r, nil
+	}
+
+	p.next++
+	if p.done() {
+		return nil, p.unexpected()
+	}
+
+	value := p.peek()
+	if value.symbol || (!value.quoted && isKeyword(value.text)) {
+		return nil, p.unexpected()
+	}
+
+	p.next++
+
+	r.comparator = comparator.text
+	r.value = value.text
+	r.quoted = value.quoted
+
+	return r, nil
+}
+
+// keyword consome a palavra-chave caso seja o próximo token.
+func (p *parser) keyword(word string) bool {
+	if p.done() {
+		return false
+	}
+
+	if tok := p.peek(); tok.quoted || tok.symbol || tok.text != word {
+		return false
+	}
+
+	p.next++
+	return true
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) done() bool {
+	return p.next >= len(p.tokens)
+}
+
+// unexpected devolve o erro do token atual ou do fim da expressão.
+func (p *parser) unexpected() error {
+	if p.done() {
+		return fmt.Errorf("invalid filter expression: unexpected end of expression")
+	}
+
+	tok := p.peek()
+	return fmt.Errorf("invalid filter expression: unexpected %q at position %d", tok.text, tok.pos)
+}
+
+// isKeyword indica se o texto é um dos operadores lógicos
+func isKeyword(text string) bool {
+	return text == "AND" || text == "OR" || text == "NOT"
+}
+
+// Conversão -----------------------------
+
+// convert converte a expressão na árvore de condições, aplicando a negação
+// recebida. A negação das restrições é aplicada no próprio predicado
+// (NOT price > 10 // price_lte), nos demais nós é mantida como NOT.
+func convert(expr expression, negated bool) (Condition, error) {
+	switch node := expr.(type) {
+	case conjunction:
+		and := Condition{Operator: AND}
+		for _, item := range node {
+			converted, err := convert(item, false)
+			if err != nil {
+				return Condition{}, err
+			}
+
+			and = And(and, converted)
+		}
+
+		if negated {
+			return Not(and), nil
+		}
+
+		return and, nil
+	case disjunction:
+		if c, ok, err := oneOf(node, negated); ok || err != nil {
+			return c, err
+		}
+
+		or := Condition{Operator: OR}
+		for _, item := range node {
+			converted, err := convert(item, false)
+			if err != nil {
+				return Condition{}, err
+			}
+
+			if converted.Operator == OR {
+				or.Conditions = append(or.Conditions, converted.Conditions...)
+				continue
+			}
+
+			or.Conditions = append(or.Conditions, converted)
+		}
+
+		if negated {
+			return Not(or), nil
+		}
+
+		return or, nil
+	case negation:
+		return convert(node.expression, !negated)
+	case restriction:
+		return node.condition(negated)
+	}
+
+	return Condition{}, fmt.Errorf("unsupported filter expression")
+}
+
+// oneOf converte as igualdades de um mesmo campo unidas por OR no predicado
+// IN, ou NIN quando negadas:
+//
+//	status = "open" OR status = "draft" // filter[status_in]=open,draft
+//
+// Caso o OR tenha outras restrições, ou campos diferentes, ok será false.
+func oneOf(or disjunction, negated bool) (c Condition, ok bool, err error) {
+	c = Leaf("", Field{Predicate: IN})
+	if negated {
+		c.Filter.Predicate = NIN
+	}
+
+	var equalities []restriction
+	for _, item := range or {
+		switch node := item.(type) {
+		case disjunction:
+			for _, nested := range node {
+				r, isRestriction := nested.(restriction)
+				if !isRestriction {
+					return c, false, nil
+				}
+
+				equalities = append(equalities, r)
+			}
+		case restriction:
+			equalities = append(equalities, node)
+		default:
+			return c, false, nil
+		}
+	}
+
+	for _, r := range equalities {
+		if r.comparator != "=" || strings.Contains(r.value, WILDCARD) || (!r.quoted && r.value == NULL_VALUE) {
+			return c, false, nil
+		}
+
+		if c.Field != "" && c.Field != r.field {
+			return c, false, nil
+		}
+
+		c.Field = r.field
+	}
+
+	for _, r := range equalities {
+		c.Filter.Values = append(c.Filter.Values, r.value)
+	}
+
+	return c, true, nil
+}
+
+// condition converte a restrição na folha com o predicado e os valores
+// equivalentes.
+func (r restriction) condition(negated bool) (Condition, error) {
+	c := Leaf(r.field, Field{})
+
+	switch {
+	case r.comparator == "":
+		// campo booleano
+		c.Filter = Field{Predicate: EQ, Values: []string{fmt.Sprint(!negated)}}
+		return c, nil
+	case r.comparator == HAS:
+		if r.quoted || r.value != WILDCARD {
+			return c, fmt.Errorf("unsupported filter expression: %s:%s, only %s:%s is supported", r.field, r.value, r.field, WILDCARD)
+		}
+
+		return r.presence(c, !negated), nil
+	case !r.quoted && r.value == NULL_VALUE && (r.comparator == "=" || r.comparator == "!="):
+		return r.presence(c, (r.comparator == "!=") != negated), nil
+	}
+
+	predicates := comparators[r.comparator]
+
+	c.Filter.Predicate = predicates[0]
+	if negated {
+		c.Filter.Predicate = predicates[1]
+	}
+
+	value := r.value
+
+	if r.comparator == "=" && strings.Contains(value, WILDCARD) {
+		switch {
+		case strings.Count(value, WILDCARD) == 1 && strings.HasSuffix(value, WILDCARD):
+			c.Filter.Predicate = START
+			value = strings.TrimSuffix(value, WILDCARD)
+		case strings.Count(value, WILDCARD) == 1 && strings.HasPrefix(value, WILDCARD):
+			c.Filter.Predicate = END
+			value = strings.TrimPrefix(value, WILDCARD)
+		default:
+			return c, fmt.Errorf("unsupported filter expression: wildcard should be at the start or the end of filter %s", r.field)
+		}
+	}
+
+	c.Filter.Values = []string{value}
+
+	// os predicados START e END não têm negação, portanto a folha é negada
+	if negated && (c.Filter.Predicate == START || c.Filter.Predicate == END) {
+		return Not(c), nil
+	}
+
+	return c, nil
+}
+
+// presence devolve a condição de presença do campo:
+//
+//	deleted_at:*      // filter[deleted_at_notnull]=true
+//	deleted_at = null // filter[deleted_at_null]=true
+func (r restriction) presence(c Condition, present bool) Condition {
+	c.Filter = Field{Predicate: NULL, Values: []string{"true"}}
+	if present {
+		c.Filter.Predicate = NOT_NULL
+	}
+
+	return c
+}
+
+// Formatação ----------------------------
+
+// String devolve a expressão AIP-160 da condição, inversa de
+// ParseExpression:
+//
+//	status = "open" AND (price > "10" OR NOT archived = "true")
+//
+// Os nós AND e OR aninhados são delimitados por parênteses e os valores são
+// sempre escritos entre aspas. O predicado BLANK não tem representação na
+// AIP-160, portanto não é produzido pelas expressões.
+func (c Condition) String() string {
+	switch c.Operator {
+	case LEAF:
+		return c.restriction()
+	case NOT:
+		return "NOT " + c.Conditions[0].group()
+	}
+
+	if len(c.Conditions) == 1 {
+		return c.Conditions[0].String()
+	}
+
+	operands := make([]string, 0, len(c.Conditions))
+	for _, nested := range c.Conditions {
+		operands = append(operands, nested.group())
+	}
+
+	return strings.Join(operands, " "+c.Operator.String()+" ")
+}
+
+// group devolve a expressão da condição delimitada por parênteses quando
+// é um nó AND ou OR com mais de uma condição.
+func (c Condition) group() string {
+	if (c.Operator == AND || c.Operator == OR) && len(c.Conditions) > 1 {
+		return "(" + c.String() + ")"
+	}
+
+	return c.String()
+}
+
+// operators relaciona os predicados de comparação com os comparadores
+var operators = map[Predicate]string{
+	EQ:  "=",
+	NEQ: "!=",
+	GT:  ">",
+	GTE: ">=",
+	LT:  "<",
+	LTE: "<=",
+}
+
+// restriction devolve a restrição AIP-160 da folha.
+func (c Condition) restriction() string {
+	field, f := c.Field, c.Filter
+
+	values := make([]string, 0, len(f.Values))
+	for _, value := range f.Values {
+		values = append(values, quote(value))
+	}
+
+	switch f.Predicate {
+	case NONE, EQ, IN, NEQ, NIN:
+		if len(values) == 1 && f.Predicate != IN && f.Predicate != NIN {
+			return field + " " + operators[f.Predicate] + " " + values[0]
+		}
+
+		equalities := make([]string, 0, len(values))
+		for _, value := range values {
+			equalities = append(equalities, field+" = "+value)
+		}
+
+		or := "(" + strings.Join(equalities, " OR ") + ")"
+		if f.Predicate == NEQ || f.Predicate == NIN {
+			return "NOT " + or
+		}
+
+		return or
+	case START:
+		return field + " = " + quote(first(f.Values)+WILDCARD)
+	case END:
+		return field + " = " + quote(WILDCARD+first(f.Values))
+	case NULL, NOT_NULL:
+		// sem valor, ou com um valor inválido, o predicado é verdadeiro
+		null := f.Predicate == NULL
+		if len(f.Values) == 1 && f.Values[0] == "false" {
+			null = !null
+		}
+
+		if null {
+			return field + " = " + NULL_VALUE
+		}
+
+		return field + " != " + NULL_VALUE
+	}
+
+	return field + " " + operators[f.Predicate] + " " + quote(first(f.Values))
+}
+
+// first devolve o primeiro valor, ou vazio quando não há valores.
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
+
+// quote devolve o valor entre aspas, com as aspas e barras invertidas do
+// próprio valor escapadas.
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
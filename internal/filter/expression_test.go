@@ -0,0 +1,294 @@
+package filter
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpression(t *testing.T) {
+	testtable := []struct {
+		desc     string
+		expr     string
+		brackets url.Values
+	}{
+		{
+			desc:     "should convert comparisons",
+			expr:     `price > 10 AND stock <= 5 AND rating >= 4.5 AND weight < 2`,
+			brackets: url.Values{"filter[price_gt]": {"10"}, "filter[stock_lte]": {"5"}, "filter[rating_gte]": {"4.5"}, "filter[weight_lt]": {"2"}},
+		},
+		{
+			desc:     "should convert equalities",
+			expr:     `status = "open" author.name != 'Ana'`,
+			brackets: url.Values{"filter[status_eq]": {"open"}, "filter[author.name_neq]": {"Ana"}},
+		},
+		{
+			desc:     "should convert equalities of the same field joined by OR",
+			expr:     `(status = "open" OR status = draft) AND price > 10`,
+			brackets: url.Values{"filter[status_in]": {"open,draft"}, "filter[price_gt]": {"10"}},
+		},
+		{
+			desc:     "should give OR precedence over AND",
+			expr:     `price > 10 AND status = "open" OR status = "draft"`,
+			brackets: url.Values{"filter[status_in]": {"open,draft"}, "filter[price_gt]": {"10"}},
+		},
+		{
+			desc:     "should negate equalities joined by OR",
+			expr:     `NOT (status = "open" OR status = "draft")`,
+			brackets: url.Values{"filter[status_nin]": {"open,draft"}},
+		},
+		{
+			desc:     "should negate comparisons",
+			expr:     `NOT price > 10 -status = "open"`,
+			brackets: url.Values{"filter[price_lte]": {"10"}, "filter[status_neq]": {"open"}},
+		},
+		{
+			desc:     "should convert boolean fields",
+			expr:     `featured AND NOT archived`,
+			brackets: url.Values{"filter[featured_eq]": {"true"}, "filter[archived_eq]": {"false"}},
+		},
+		{
+			desc:     "should convert presence restrictions",
+			expr:     `deleted_at = null AND published_at:* AND NOT archived_at:* AND reviewed_at != null`,
+			brackets: url.Values{"filter[deleted_at_null]": {"true"}, "filter[published_at_notnull]": {"true"}, "filter[archived_at_null]": {"true"}, "filter[reviewed_at_notnull]": {"true"}},
+		},
+		{
+			desc:     "should compare quoted null as text",
+			expr:     `name = "null"`,
+			brackets: url.Values{"filter[name_eq]": {"null"}},
+		},
+		{
+			desc:     "should convert wildcards",
+			expr:     `title = "Go*" AND email = *@example.com`,
+			brackets: url.Values{"filter[title_start]": {"Go"}, "filter[email_end]": {"@example.com"}},
+		},
+		{
+			desc:     "should unescape quoted values",
+			expr:     `title = "say \"hi\""`,
+			brackets: url.Values{"filter[title_eq]": {`say "hi"`}},
+		},
+		{
+			desc:     "should accept negative values",
+			expr:     `balance < -10`,
+			brackets: url.Values{"filter[balance_lt]": {"-10"}},
+		},
+		{
+			desc:     "should return empty filters for empty expressions",
+			expr:     "  ",
+			brackets: url.Values{},
+		},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			expected, err := Decode(tc.brackets)
+			require.Nil(t, err)
+
+			got, err := ParseExpression(tc.expr)
+			require.Nil(t, err)
+
+			// as restrições são as mesmas folhas dos parâmetros com colchetes
+			filters, rest := split(got)
+			require.Equal(t, expected, filters)
+			require.True(t, rest.Empty())
+		})
+	}
+}
+
+func TestParseExpressionTree(t *testing.T) {
+	leaf := func(field string, predicate Predicate, values ...string) Condition {
+		return Leaf(field, Field{predicate, values})
+	}
+
+	testtable := []struct {
+		desc     string
+		expr     string
+		expected Condition
+		// formatted é a expressão de String, quando difere da expressão
+		formatted string
+	}{
+		{
+			desc: "should keep OR between different fields",
+			expr: `price > 10 AND status = "open" OR NOT archived`,
+			expected: And(
+				leaf("price", GT, "10"),
+				Condition{Operator: OR, Conditions: []Condition{
+					leaf("status", EQ, "open"),
+					leaf("archived", EQ, "false"),
+				}},
+			),
+			formatted: `price > "10" AND (status = "open" OR archived = "false")`,
+		},
+		{
+			desc: "should keep NOT before AND",
+			expr: `NOT (price > 10 AND stock > 1)`,
+			expected: And(Not(And(
+				leaf("price", GT, "10"),
+				leaf("stock", GT, "1"),
+			))),
+			formatted: `NOT (price > "10" AND stock > "1")`,
+		},
+		{
+			desc: "should merge equalities of the same field inside OR",
+			expr: `(kind = "post" AND (status = "open" OR status = "draft")) OR kind = "page"`,
+			expected: And(Condition{Operator: OR, Conditions: []Condition{
+				And(leaf("kind", EQ, "post"), leaf("status", IN, "open", "draft")),
+				leaf("kind", EQ, "page"),
+			}}),
+			formatted: `(kind = "post" AND (status = "open" OR status = "draft")) OR kind = "page"`,
+		},
+		{
+			desc:      "should negate wildcards with NOT",
+			expr:      `NOT title = "Go*"`,
+			expected:  And(Not(leaf("title", START, "Go"))),
+			formatted: `NOT title = "Go*"`,
+		},
+		{
+			desc: "should format presence and negated lists",
+			expr: `deleted_at = null OR NOT (status = "a" OR status = "b") OR published_at:*`,
+			expected: And(Condition{Operator: OR, Conditions: []Condition{
+				leaf("deleted_at", NULL, "true"),
+				leaf("status", NIN, "a", "b"),
+				leaf("published_at", NOT_NULL, "true"),
+			}}),
+			formatted: `deleted_at = null OR NOT (status = "a" OR status = "b") OR published_at != null`,
+		},
+		{
+			desc:      "should keep commas in quoted values",
+			expr:      `title = "a,b" OR title = "c" OR NOT author = "x,*"`,
+			formatted: `title = "a,b" OR title = "c" OR NOT author = "x,*"`,
+			expected: And(Condition{Operator: OR, Conditions: []Condition{
+				leaf("title", EQ, "a,b"),
+				leaf("title", EQ, "c"),
+				Not(leaf("author", START, "x,")),
+			}}),
+		},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			got, err := ParseExpression(tc.expr)
+			require.Nil(t, err)
+			require.Equal(t, tc.expected, got)
+
+			// String é inversa de ParseExpression
+			require.Equal(t, tc.formatted, got.String())
+
+			reparsed, err := ParseExpression(got.String())
+			require.Nil(t, err)
+			require.Equal(t, got, reparsed)
+		})
+	}
+}
+
+func TestParseExpressionInvalid(t *testing.T) {
+	testtable := []struct {
+		desc string
+		expr string
+		err  string
+	}{
+		{
+			desc: "should reject fields used more than once",
+			expr: `price > 10 AND price < 20`,
+			err:  "filter price used more than once in expression",
+		},
+		{
+			desc: "should reject has restrictions without wildcard",
+			expr: `tags:go`,
+			err:  "unsupported filter expression: tags:go, only tags:* is supported",
+		},
+		{
+			desc: "should reject wildcards in the middle",
+			expr: `title = "G*o"`,
+			err:  "unsupported filter expression: wildcard should be at the start or the end of filter title",
+		},
+		{
+			desc: "should reject missing values",
+			expr: `price >`,
+			err:  "invalid filter expression: unexpected end of expression",
+		},
+		{
+			desc: "should reject dangling operators",
+			expr: `price > 10 AND`,
+			err:  "invalid filter expression: unexpected end of expression",
+		},
+		{
+			desc: "should reject unbalanced parentheses",
+			expr: `(price > 10`,
+			err:  "invalid filter expression: unexpected end of expression",
+		},
+		{
+			desc: "should reject unexpected parentheses",
+			expr: `price > 10)`,
+			err:  `invalid filter expression: unexpected ")" at position 10`,
+		},
+		{
+			desc: "should reject comparators as values",
+			expr: `price = = 10`,
+			err:  `invalid filter expression: unexpected "=" at position 8`,
+		},
+		{
+			desc: "should reject quoted fields",
+			expr: `"price" = 10`,
+			err:  `invalid filter expression: unexpected "price" at position 0`,
+		},
+		{
+			desc: "should reject unterminated strings",
+			expr: `title = "Go`,
+			err:  "invalid filter expression: unterminated string at position 8",
+		},
+		{
+			desc: "should reject a lone bang",
+			expr: `price ! 10`,
+			err:  `invalid filter expression: unexpected "!" at position 6`,
+		},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			_, err := ParseExpression(tc.expr)
+			require.EqualError(t, err, tc.err)
+		})
+	}
+}
+
+func TestHandleExpression(t *testing.T) {
+	f := New(AcceptField("price", "status", "title"), Expressions())
+
+	query := url.Values{
+		"filter":              {`price > 10`, `status = "open" OR status = "draft"`, ""},
+		"filter[title_start]": {"Go"},
+	}
+
+	ctx, err := f.Handle(context.Background(), query)
+	require.Nil(t, err)
+	require.Equal(t, Filters{
+		"price":  {GT, []string{"10"}},
+		"status": {IN, []string{"open", "draft"}},
+		"title":  {START, []string{"Go"}},
+	}, f.GetAll(ctx))
+
+	// a query da request não é alterada
+	require.Equal(t, []string{`price > 10`, `status = "open" OR status = "draft"`, ""}, query["filter"])
+
+	_, err = f.Handle(context.Background(), url.Values{"filter": {`body = "x"`}})
+	require.EqualError(t, err, "unsupported filter resource: body")
+
+	_, err = f.Handle(context.Background(), url.Values{"filter": {`price > 10`}, "filter[price_lt]": {"20"}})
+	require.EqualError(t, err, "filter price used more than once")
+
+	_, err = f.Handle(context.Background(), url.Values{"filter": {`price > 10`, `price < 20`}})
+	require.EqualError(t, err, "filter price used more than once")
+
+	// as posições dos erros se referem à expressão enviada
+	_, err = f.Handle(context.Background(), url.Values{"filter": {`(`}})
+	require.EqualError(t, err, "invalid filter expression: unexpected end of expression")
+	_, err = f.Handle(context.Background(), url.Values{"filter": {`price > 10`, `status = = "open"`}})
+	require.EqualError(t, err, `invalid filter expression: unexpected "=" at position 9`)
+
+	// sem a opção a expressão continua sendo rejeitada
+	_, err = New(AcceptField("price")).Handle(context.Background(), url.Values{"filter": {`price > 10`}})
+	require.EqualError(t, err, "has no filter field param")
+}
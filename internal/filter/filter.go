@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"reflect"
 	"strings"
+
+	"github.com/jeanmolossi/gosparse/internal/authorization"
 )
 
 // Filters é um map de structs vazias
@@ -23,6 +26,31 @@ import (
 //	}
 type Filters map[string]Field
 
+// Filter armazena a configuração do parâmetro de busca "filter".
+//
+// O valor zero de Filter não aceita nenhum campo e pode ser configurado
+// diretamente com AddFilter.
+type Filter struct {
+	// Accepted são os campos aceitos no parâmetro "filter"
+	Accepted Filters
+	// Types são os tipos dos campos aceitos, que restringem os predicados
+	// de cada campo (veja Predicates). Campos sem tipo aceitam todos os
+	// predicados.
+	Types map[string]reflect.Type
+	// Resource é o tipo de recurso dos dados primários, informado ao
+	// Authorizer do Guard
+	Resource string
+	// Guard consulta a autorização de cada campo filtrado
+	Guard authorization.Guard
+	// Expressions habilita o parâmetro "filter" sem colchetes com uma
+	// expressão AIP-160 (veja ParseExpression):
+	//
+	//	filter=price > 10 AND status = "open"
+	Expressions bool
+
+	frozen bool
+}
+
 // Field é a estrutura que armazena a configuração
 // de um campo específico no parâmetro de busca "filter"
 type Field struct {
@@ -31,13 +59,17 @@ type Field struct {
 }
 
 // FiltersOpt é umas assinatura para opções de configuração
-// para o construtor de Filters
-type FiltersOpt func(*Filters)
+// para o construtor de Filter
+type FiltersOpt func(*Filter)
 
 // CtxKey é uma chave para o contexto.
 // struct vazias são mais performaticas até mesmo que strings
 type CtxKey struct{}
 
+// ConditionCtxKey é a chave do contexto para as condições das expressões
+// (veja GetCondition).
+type ConditionCtxKey struct{}
+
 const (
 	SEARCH_PARAM string = "filter"
 )
@@ -65,30 +97,138 @@ func extractFilterFromQuery(query url.Values) url.Values {
 // Caso haja algum valor de "filter" que não está definido como "AcceptFilter"
 // será retornado um erro de recurso de campo não suportado, uma vez que o
 // parâmetro "filter" só deve ser recebido com valores aceitos ou não deve ser utilizado.
-func (f Filters) Handle(ctx context.Context, query url.Values) (context.Context, error) {
+//
+// Cada campo filtrado também é autorizado pelo Guard. Os campos negados
+// com a política STRIP são removidos somente da conjunção principal, dentro
+// dos nós OR e NOT a remoção alteraria o significado da expressão, portanto
+// o campo é rejeitado com um ForbiddenError.
+func (f Filter) Handle(ctx context.Context, query url.Values) (context.Context, error) {
 	query = extractFilterFromQuery(query)
 	if len(query) == 0 {
 		return ctx, nil
 	}
 
-	filters, err := Decode(query)
+	condition, err := f.decode(query)
+	if err != nil {
+		return ctx, err
+	}
+
+	condition, _, err = f.check(ctx, condition, true)
 	if err != nil {
 		return ctx, err
 	}
 
-	for filter := range filters {
-		if _, exists := f[filter]; !exists {
-			return ctx, fmt.Errorf("unsupported filter resource: %s", filter)
+	filters, nested := split(condition)
+
+	ctx = context.WithValue(ctx, CtxKey{}, filters)
+	return context.WithValue(ctx, ConditionCtxKey{}, nested), nil
+}
+
+// check valida os campos e os predicados das folhas da condição e consulta
+// o Guard para cada campo. Devolve a condição sem as folhas removidas pelo
+// Guard e se a própria condição deve ser mantida.
+//
+// root indica se a condição faz parte da conjunção principal, ou seja, se
+// as folhas negadas podem ser removidas.
+func (f Filter) check(ctx context.Context, c Condition, root bool) (Condition, bool, error) {
+	if c.Operator != LEAF {
+		checked := Condition{Operator: c.Operator}
+
+		for _, nested := range c.Conditions {
+			nested, keep, err := f.check(ctx, nested, root && c.Operator == AND)
+			if err != nil {
+				return c, false, err
+			}
+
+			if keep {
+				checked.Conditions = append(checked.Conditions, nested)
+			}
+		}
+
+		return checked, true, nil
+	}
+
+	if _, exists := f.Accepted[c.Field]; !exists {
+		return c, false, fmt.Errorf("unsupported filter resource: %s", c.Field)
+	}
+
+	if predicate := c.Filter.Predicate; !allows(f.Types[c.Field], predicate) {
+		return c, false, fmt.Errorf("unsupported predicate %s on filter %s", predicate, c.Field)
+	}
+
+	allowed, err := f.Guard.Check(ctx, authorization.FILTER, f.Resource, c.Field)
+	if err != nil {
+		return c, false, err
+	}
+
+	if !allowed && !root {
+		return c, false, &authorization.ForbiddenError{
+			Op:       authorization.FILTER,
+			Resource: f.Resource,
+			Field:    c.Field,
+			Err:      fmt.Errorf("denied filter inside OR or NOT can not be stripped"),
 		}
 	}
 
-	return context.WithValue(ctx, CtxKey{}, filters), nil
+	return c, allowed, nil
+}
+
+// decode extrai as condições dos parâmetros "filter[FIELD_PREDICATE]" e, com
+// Expressions habilitado, da expressão do parâmetro "filter".
+//
+// A expressão e os parâmetros com colchetes podem ser combinados, desde que
+// cada campo seja filtrado uma única vez na conjunção principal.
+func (f Filter) decode(query url.Values) (Condition, error) {
+	expressions, present := query[SEARCH_PARAM]
+	if !f.Expressions || !present {
+		filters, err := Decode(query)
+		return All(filters), err
+	}
+
+	delete(query, SEARCH_PARAM)
+
+	filters, err := Decode(query)
+	if err != nil {
+		return Condition{}, err
+	}
+
+	condition := All(filters)
+
+	// múltiplos parâmetros "filter" são unidos por AND. Cada expressão é
+	// analisada separadamente, portanto as posições dos erros se referem
+	// à expressão enviada pelo cliente
+	for _, expr := range expressions {
+		if strings.TrimSpace(expr) == "" {
+			continue
+		}
+
+		parsed, err := ParseExpression(expr)
+		if err != nil {
+			return Condition{}, err
+		}
+
+		for _, c := range parsed.Conditions {
+			if c.Operator != LEAF {
+				continue
+			}
+
+			if _, duplicate := filters[c.Field]; duplicate {
+				return Condition{}, fmt.Errorf("filter %s used more than once", c.Field)
+			}
+
+			filters[c.Field] = c.Filter
+		}
+
+		condition = And(condition, parsed)
+	}
+
+	return condition, nil
 }
 
 // Get recebe o contexto e a chave do campo de "filter" já validado e tratado.
 //
 // Caso o contexto não tenha o valor do campo será retornado um Field zero valued.
-func (f Filters) Get(ctx context.Context, field string) Field {
+func (f Filter) Get(ctx context.Context, field string) Field {
 	if values, ok := ctx.Value(CtxKey{}).(Filters); ok {
 		return values[field]
 	}
@@ -101,7 +241,7 @@ func (f Filters) Get(ctx context.Context, field string) Field {
 //
 // Caso os filtros não estejam presentes no contexto, será
 // devolvido uma instância vazia dos Filters
-func (f Filters) GetAll(ctx context.Context) Filters {
+func (f Filter) GetAll(ctx context.Context) Filters {
 	if values, ok := ctx.Value(CtxKey{}).(Filters); ok {
 		return values
 	}
@@ -109,26 +249,93 @@ func (f Filters) GetAll(ctx context.Context) Filters {
 	return make(Filters)
 }
 
+// GetCondition recebe o contexto e devolve as condições das expressões
+// que não são representadas pelos filtros de GetAll, ou seja, os nós OR e
+// NOT unidos por AND aos filtros (veja ParseExpression).
+//
+// Sem expressões, ou quando todas as restrições são folhas, será devolvida
+// uma conjunção vazia.
+func (f Filter) GetCondition(ctx context.Context) Condition {
+	if condition, ok := ctx.Value(ConditionCtxKey{}).(Condition); ok {
+		return condition
+	}
+
+	return Condition{}
+}
+
 // AddFilter recebe a chave do campo aceito no parâmetro "filter".
 //
 // Caso a chave recebida já esteja na lista de campos suportados, ela
 // será ignorada.
-func (f Filters) AddFilter(filter string) {
-	if f == nil {
-		f = make(Filters)
+func (f *Filter) AddFilter(filter string) {
+	f.mutable()
+
+	if f.Accepted == nil {
+		f.Accepted = make(Filters)
 	}
 
-	if _, duplicate := f[filter]; !duplicate {
-		f[filter] = Field{}
+	if _, duplicate := f.Accepted[filter]; !duplicate {
+		f.Accepted[filter] = Field{}
+	}
+}
+
+// AddType recebe a chave do campo e o tipo do campo na estrutura, que
+// restringe os predicados aceitos no parâmetro "filter" (veja Predicates).
+func (f *Filter) AddType(filter string, t reflect.Type) {
+	f.mutable()
+
+	if f.Types == nil {
+		f.Types = make(map[string]reflect.Type)
+	}
+
+	f.Types[filter] = t
+}
+
+// Freeze congela os campos aceitos no parâmetro "filter". Chamadas de
+// AddFilter e AddType após o congelamento entram em pânico.
+//
+// Accepted e Types são copiados, portanto não são compartilhados com quem
+// montou a configuração, e continuam exportados somente para leitura.
+func (f *Filter) Freeze() {
+	if f.Accepted != nil {
+		accepted := make(Filters, len(f.Accepted))
+		for field, value := range f.Accepted {
+			accepted[field] = value
+		}
+
+		f.Accepted = accepted
+	}
+
+	if f.Types != nil {
+		types := make(map[string]reflect.Type, len(f.Types))
+		for field, t := range f.Types {
+			types[field] = t
+		}
+
+		f.Types = types
+	}
+
+	f.frozen = true
+}
+
+// Frozen indica se a configuração foi congelada (veja Freeze).
+func (f Filter) Frozen() bool {
+	return f.frozen
+}
+
+// mutable entra em pânico caso a configuração esteja congelada.
+func (f Filter) mutable() {
+	if f.frozen {
+		panic("filter: can not change a frozen configuration")
 	}
 }
 
 // Options -----------------
 
-// AcceptField é uma opção do construtor de *FiltersOpt. Essa função recebe um
+// AcceptField é uma opção do construtor de *Filter. Essa função recebe um
 // slice de strings que serão as chaves de campos aceitos ao validar a query da request
 func AcceptField(filters ...string) FiltersOpt {
-	return func(f *Filters) {
+	return func(f *Filter) {
 		if len(filters) == 0 {
 			return
 		}
@@ -139,10 +346,35 @@ func AcceptField(filters ...string) FiltersOpt {
 	}
 }
 
+// Resource é uma opção do construtor de *Filter. Essa função recebe o tipo
+// de recurso dos dados primários, informado ao Authorizer.
+func Resource(typ string) FiltersOpt {
+	return func(f *Filter) {
+		f.Resource = typ
+	}
+}
+
+// Expressions é uma opção do construtor de *Filter. Essa função habilita a
+// expressão AIP-160 no parâmetro "filter" sem colchetes.
+func Expressions() FiltersOpt {
+	return func(f *Filter) {
+		f.Expressions = true
+	}
+}
+
+// Authorize é uma opção do construtor de *Filter. Essa função recebe o
+// Authorizer consultado para cada campo filtrado e a política aplicada
+// quando o campo é negado.
+func Authorize(authorizer authorization.Authorizer, policy authorization.Policy) FiltersOpt {
+	return func(f *Filter) {
+		f.Guard = authorization.Guard{Authorizer: authorizer, Policy: policy}
+	}
+}
+
 // Constructor -----------------
 
-func New(opt ...FiltersOpt) *Filters {
-	fields := &Filters{}
+func New(opt ...FiltersOpt) *Filter {
+	fields := &Filter{Accepted: make(Filters)}
 
 	for _, o := range opt {
 		if o == nil {
@@ -1,5 +1,11 @@
 package filter
 
+import (
+	"encoding"
+	"reflect"
+	"time"
+)
+
 // Predicate é um tipo para definir um enum de predicados
 // aceitos nos campos do parâmetro "fields"
 type Predicate int
@@ -21,30 +27,144 @@ const (
 	END
 )
 
+// predicates relaciona o sufixo do campo no parâmetro "filter" com o
+// predicado correspondente.
+var predicates = map[string]Predicate{
+	"eq":      EQ,
+	"neq":     NEQ,
+	"in":      IN,
+	"nin":     NIN,
+	"gt":      GT,
+	"gte":     GTE,
+	"lt":      LT,
+	"lte":     LTE,
+	"blank":   BLANK,
+	"null":    NULL,
+	"notnull": NOT_NULL,
+	"start":   START,
+	"end":     END,
+}
+
+// lookupPredicate recebe o sufixo do campo e devolve o predicado
+// correspondente e se o sufixo é um predicado conhecido.
+func lookupPredicate(pre string) (Predicate, bool) {
+	predicate, found := predicates[pre]
+	return predicate, found
+}
+
 func getPredicate(pre string) Predicate {
 	if pre == "" {
 		return NONE
 	}
 
-	preMap := map[string]Predicate{
-		"eq":      EQ,
-		"neq":     NEQ,
-		"in":      IN,
-		"nin":     NIN,
-		"gt":      GT,
-		"gte":     GTE,
-		"lt":      LT,
-		"lte":     LTE,
-		"blank":   BLANK,
-		"null":    NULL,
-		"notnull": NOT_NULL,
-		"start":   START,
-		"end":     END,
-	}
-
-	if predicate, found := preMap[pre]; found {
+	if predicate, found := lookupPredicate(pre); found {
 		return predicate
 	}
 
 	return NONE
 }
+
+// String devolve o sufixo do predicado utilizado no parâmetro "filter".
+//
+//	IN.String() // "in"
+//
+// NONE e predicados desconhecidos devolvem uma string vazia.
+func (p Predicate) String() string {
+	for suffix, predicate := range predicates {
+		if predicate == p {
+			return suffix
+		}
+	}
+
+	return ""
+}
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Kind é a classificação do tipo de um campo filtrado, que define os
+// predicados permitidos (veja Predicates). Os valores são os tipos e
+// formatos do JSON Schema.
+type Kind string
+
+const (
+	STRING    Kind = "string"
+	INTEGER   Kind = "integer"
+	NUMBER    Kind = "number"
+	BOOLEAN   Kind = "boolean"
+	DATE_TIME Kind = "date-time"
+)
+
+// KindOf devolve a classificação do tipo do campo.
+//
+// Referências e listas (slices / arrays) são classificadas pelo tipo dos
+// itens, uma vez que cada valor de um filtro é um item. Tipos que
+// implementam encoding.TextUnmarshaler e campos sem tipo (nil) são
+// classificados como textos.
+func KindOf(t reflect.Type) Kind {
+	if t == nil {
+		return STRING
+	}
+
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return DATE_TIME
+	}
+
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return STRING
+	}
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return INTEGER
+	case reflect.Float32, reflect.Float64:
+		return NUMBER
+	case reflect.Bool:
+		return BOOLEAN
+	}
+
+	return STRING
+}
+
+// Predicates devolve os predicados permitidos para o tipo do campo (veja
+// KindOf), além de NONE (filter[FIELD]), que é sempre permitido:
+//
+//   - textos: todos, comparados em ordem lexicográfica
+//   - números e datas: eq, neq, in, nin, gt, gte, lt, lte, null, notnull
+//   - booleanos: eq, neq, null, notnull
+func Predicates(t reflect.Type) []Predicate {
+	switch KindOf(t) {
+	case INTEGER, NUMBER, DATE_TIME:
+		return []Predicate{EQ, NEQ, IN, NIN, GT, GTE, LT, LTE, NULL, NOT_NULL}
+	case BOOLEAN:
+		return []Predicate{EQ, NEQ, NULL, NOT_NULL}
+	}
+
+	return text
+}
+
+// text são os predicados permitidos para textos, ou seja, todos
+var text = []Predicate{EQ, NEQ, IN, NIN, GT, GTE, LT, LTE, START, END, BLANK, NULL, NOT_NULL}
+
+// allows indica se o predicado é permitido para o tipo do campo (veja
+// Predicates).
+func allows(t reflect.Type, predicate Predicate) bool {
+	if predicate == NONE {
+		return true
+	}
+
+	for _, allowed := range Predicates(t) {
+		if allowed == predicate {
+			return true
+		}
+	}
+
+	return false
+}
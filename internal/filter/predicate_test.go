@@ -0,0 +1,37 @@
+package filter
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// level é um número com a representação em texto
+type level int
+
+func (l *level) UnmarshalText(text []byte) error {
+	return nil
+}
+
+func TestKindOf(t *testing.T) {
+	testtable := []struct {
+		desc   string
+		typ    reflect.Type
+		expect Kind
+	}{
+		{desc: "should classify integers", typ: reflect.TypeOf(uint8(0)), expect: INTEGER},
+		{desc: "should classify items of lists", typ: reflect.TypeOf([]float32{}), expect: NUMBER},
+		{desc: "should classify references", typ: reflect.TypeOf(new(bool)), expect: BOOLEAN},
+		{desc: "should classify dates", typ: reflect.TypeOf(&time.Time{}), expect: DATE_TIME},
+		{desc: "should classify text unmarshalers as texts", typ: reflect.TypeOf(level(0)), expect: STRING},
+		{desc: "should classify fields without type as texts", typ: nil, expect: STRING},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			require.Equal(t, tt.expect, KindOf(tt.typ))
+		})
+	}
+}
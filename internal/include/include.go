@@ -4,24 +4,90 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
+
+	"github.com/jeanmolossi/gosparse/internal/authorization"
 )
 
-// Includes é um map de structs vazias
+// Relations é uma árvore de relacionamentos aceitos
 //
-// maps tem mais performance para busca por chaves
+// Cada nó da árvore é um map onde a chave é o nome do relacionamento e
+// o valor são os relacionamentos aceitos a partir dele. maps tem mais
+// performance para busca por chaves.
 //
 // Isso vai armazenar os valores aceitos para o parâmetro
 // de busca "include" no seguinte formato:
 //
-//	map[string]struct{}{
+//	// include=comments,comments.author,posts
+//	map[string]Relations{
 //		// ...
-//		"comments": {},
-//		"comments.author": {},
+//		"comments": {
+//			"author": {},
+//		},
 //		"posts": {},
 //		// ...
 //	}
-type Includes map[string]struct{}
+type Relations map[string]Relations
+
+// Includes armazena a configuração do parâmetro de busca "include".
+//
+// O valor zero de Includes não aceita nenhuma relação e pode ser
+// configurado diretamente com AddRel e AddAlias.
+type Includes struct {
+	// Relations é a árvore de relacionamentos aceitos
+	Relations Relations
+	// MaxDepth é a quantidade máxima de relacionamentos em um mesmo
+	// caminho. "comments.author" tem profundidade 2.
+	//
+	// Zero indica que não há limite.
+	MaxDepth int
+	// MaxPaths é a quantidade máxima de caminhos distintos que podem
+	// ser solicitados em um mesmo parâmetro "include".
+	//
+	// Zero indica que não há limite.
+	MaxPaths int
+	// Aliases armazena os nomes alternativos aceitos para caminhos de
+	// relacionamento no formato alias -> caminho canônico:
+	//
+	//	map[string]string{
+	//		"commentAuthors": "comments.author",
+	//	}
+	Aliases map[string]string
+	// Resource é o tipo de recurso dos dados primários, informado ao
+	// Authorizer do Guard
+	Resource string
+	// Guard consulta a autorização de cada caminho de relacionamento
+	Guard authorization.Guard
+
+	frozen bool
+}
+
+// Relation é um caminho de relacionamento solicitado no parâmetro
+// "include" já validado e resolvido.
+type Relation struct {
+	// Path é o caminho canônico do relacionamento
+	//
+	//	comments.author
+	Path string
+	// Alias é o nome alternativo utilizado pelo cliente para solicitar o
+	// relacionamento. Vazio quando solicitado pelo caminho canônico.
+	//
+	//	commentAuthors
+	Alias string
+}
+
+// Name devolve o nome com o qual o relacionamento foi solicitado, ou
+// seja, o Alias quando houver ou o Path canônico.
+//
+// Serializers devem utilizar Name para expor o relacionamento.
+func (r Relation) Name() string {
+	if r.Alias != "" {
+		return r.Alias
+	}
+
+	return r.Path
+}
 
 // IncludeOpt é uma assinatura para opções de configuração
 // para o construtor de Includes
@@ -31,6 +97,11 @@ type CtxKey struct{}
 
 const (
 	SEARCH_PARAM string = "include"
+
+	// PATH_SEPARATOR separa os nomes de relacionamento de um caminho
+	//
+	//	comments.author
+	PATH_SEPARATOR string = "."
 )
 
 // Handle vai receber um contexto e a query da request.
@@ -43,6 +114,10 @@ const (
 // será retornado um erro de relação não suportada, uma vez que o parâmetro
 // "include" só deve ser recebido com valores aceitos ou não deve ser utilizado.
 //
+// Os caminhos recebidos são resolvidos com Resolve, portanto os
+// relacionamentos intermediários também serão retornados por Get e os
+// nomes alternativos (Aliases) são resolvidos para o caminho canônico.
+//
 // É importante ressaltar que o contexto será sobrescrito por um novo contexto,
 // agora com o valor do "include", portanto, repasse o contexto retornado de
 // Handle para as chamadas seguintes que poderão recuperar os valores de "include".
@@ -51,43 +126,469 @@ func (r Includes) Handle(ctx context.Context, query url.Values) (context.Context
 		return ctx, nil
 	}
 
-	values := strings.Split(query[SEARCH_PARAM][0], ",")
+	// join para juntar quaisquer valores adicionais
+	// exemplo: url.Values{"include":{"comments","posts"}}
+	values := strings.Split(strings.Join(query[SEARCH_PARAM], ","), ",")
+
+	relations, err := r.Resolve(values)
+	if err != nil {
+		return ctx, err
+	}
+
+	// os limites são aplicados aos caminhos já expandidos, inclusive os
+	// caminhos dos nomes alternativos e os intermediários
+	if err := r.checkLimits(paths(relations)); err != nil {
+		return ctx, err
+	}
+
+	relations, err = r.authorize(ctx, relations)
+	if err != nil {
+		return ctx, err
+	}
+
+	return context.WithValue(ctx, CtxKey{}, relations), nil
+}
+
+// authorize consulta o Guard para o caminho canônico de cada relação.
+//
+// Quando um caminho é removido pela política STRIP, os caminhos que
+// dependem dele também são removidos, pois o recurso intermediário
+// não será retornado.
+func (r Includes) authorize(ctx context.Context, relations []Relation) ([]Relation, error) {
+	allowed := make([]Relation, 0, len(relations))
+	stripped := make([]string, 0)
+
+	for _, rel := range relations {
+		if hasPrefix(rel.Path, stripped) {
+			continue
+		}
+
+		ok, err := r.Guard.Check(ctx, authorization.INCLUDE, r.Resource, rel.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			stripped = append(stripped, rel.Path)
+			continue
+		}
+
+		allowed = append(allowed, rel)
+	}
+
+	return allowed, nil
+}
+
+// hasPrefix indica se o caminho é igual ou descendente de algum dos
+// caminhos recebidos.
+func hasPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, prefix+PATH_SEPARATOR) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Merge recebe o contexto e caminhos de relacionamento adicionais, como
+// os implícitos em uma seleção aninhada de campos, e devolve um novo
+// contexto com as relações já existentes no contexto e as adicionais.
+//
+// Os caminhos adicionais são validados e resolvidos com Resolve, as
+// relações repetidas são mantidas somente uma vez e os limites de
+// profundidade e de caminhos valem para o conjunto mesclado.
+func (r Includes) Merge(ctx context.Context, implied []string) (context.Context, error) {
+	relations, err := r.Resolve(implied)
+	if err != nil {
+		return ctx, err
+	}
+
+	relations, err = r.authorize(ctx, relations)
+	if err != nil {
+		return ctx, err
+	}
+
+	merged := r.GetRelations(ctx)
+	seen := make(map[Relation]struct{}, len(merged)+len(relations))
+
+	for _, rel := range merged {
+		seen[rel] = struct{}{}
+	}
+
+	for _, rel := range relations {
+		if _, duplicate := seen[rel]; !duplicate {
+			seen[rel] = struct{}{}
+			merged = append(merged, rel)
+		}
+	}
+
+	if err := r.checkLimits(paths(merged)); err != nil {
+		return ctx, err
+	}
+
+	sortRelations(merged)
+	return context.WithValue(ctx, CtxKey{}, merged), nil
+}
+
+// Encode recebe as relações e devolve a query com o parâmetro "include"
+// correspondente, utilizando o nome com o qual cada relação foi solicitada
+// (veja Relation.Name) em ordem alfabética e sem repetições.
+//
+//	Encode([]Relation{{Path: "comments.author", Alias: "commentAuthors"}})
+//	// url.Values{"include": {"commentAuthors"}}
+//
+// Caso não haja relações será devolvida uma query vazia.
+func Encode(relations []Relation) url.Values {
+	query := url.Values{}
+	if len(relations) == 0 {
+		return query
+	}
+
+	names := make([]string, 0, len(relations))
+	seen := make(map[string]struct{}, len(relations))
+
+	for _, rel := range relations {
+		if _, duplicate := seen[rel.Name()]; !duplicate {
+			seen[rel.Name()] = struct{}{}
+			names = append(names, rel.Name())
+		}
+	}
+
+	sort.Strings(names)
+	query.Set(SEARCH_PARAM, strings.Join(names, ","))
+	return query
+}
+
+// paths devolve os caminhos canônicos das relações.
+func paths(relations []Relation) []string {
+	canonical := make([]string, 0, len(relations))
+	for _, rel := range relations {
+		canonical = append(canonical, rel.Path)
+	}
+
+	return canonical
+}
+
+// checkLimits valida os caminhos solicitados contra os limites de
+// profundidade (MaxDepth) e de quantidade de caminhos (MaxPaths).
+//
+// Caminhos repetidos são contados somente uma vez.
+func (r Includes) checkLimits(paths []string) error {
+	distinct := make(map[string]struct{}, len(paths))
+
+	for _, path := range paths {
+		depth := strings.Count(path, PATH_SEPARATOR) + 1
+		if r.MaxDepth > 0 && depth > r.MaxDepth {
+			return fmt.Errorf("include relation %s exceeds max depth of %d", path, r.MaxDepth)
+		}
+
+		distinct[path] = struct{}{}
+	}
+
+	if r.MaxPaths > 0 && len(distinct) > r.MaxPaths {
+		return fmt.Errorf("include exceeds max of %d relation paths", r.MaxPaths)
+	}
+
+	return nil
+}
+
+// Normalize recebe os caminhos de relacionamento solicitados, valida
+// cada um deles na árvore de relacionamentos aceitos e devolve o
+// conjunto expandido e ordenado de caminhos.
+//
+// Conforme a especificação, os recursos intermediários de um caminho de
+// várias partes devem ser retornados, portanto ao solicitar
+// "comments.author" será devolvido também "comments".
+//
+// Caminhos repetidos são devolvidos somente uma vez.
+//
+//	r.Normalize([]string{"comments.author", "comments"})
+//	// []string{"comments", "comments.author"}
+func (r Includes) Normalize(paths []string) ([]string, error) {
+	expanded := make(map[string]struct{}, len(paths))
+
+	for _, path := range paths {
+		if !r.Has(path) {
+			return nil, fmt.Errorf("unsupported include relation %s", path)
+		}
+
+		segments := strings.Split(path, PATH_SEPARATOR)
+		for i := range segments {
+			expanded[strings.Join(segments[:i+1], PATH_SEPARATOR)] = struct{}{}
+		}
+	}
+
+	normalized := make([]string, 0, len(expanded))
+	for path := range expanded {
+		normalized = append(normalized, path)
+	}
+
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// Resolve recebe os nomes de relacionamento solicitados e devolve as
+// relações resolvidas e ordenadas pelo caminho canônico.
+//
+// Os nomes alternativos (Aliases) são resolvidos para o caminho canônico
+// e, assim como os caminhos canônicos, expandem os relacionamentos
+// intermediários. O relacionamento do nome alternativo é devolvido com o
+// Alias:
+//
+//	// Aliases: {"commentAuthors": "comments.author"}
+//	r.Resolve([]string{"commentAuthors", "posts"})
+//	// []Relation{
+//	//	{Path: "comments"},
+//	//	{Path: "comments.author", Alias: "commentAuthors"},
+//	//	{Path: "posts"},
+//	// }
+//
+// Os caminhos são validados e expandidos com Normalize.
+func (r Includes) Resolve(names []string) ([]Relation, error) {
+	canonical := make([]string, 0, len(names))
+	aliased := make(map[string]string)
+
+	for _, name := range names {
+		if path, isAlias := r.Aliases[name]; isAlias && !r.Has(name) {
+			// as opções podem registrar o nome alternativo antes da
+			// relação, portanto o caminho é validado somente aqui
+			if !r.Has(path) {
+				return nil, fmt.Errorf("include alias %s targets unsupported relation %s", name, path)
+			}
+
+			aliased[name] = path
+
+			// somente os intermediários do caminho são canônicos
+			if i := strings.LastIndex(path, PATH_SEPARATOR); i > 0 {
+				canonical = append(canonical, path[:i])
+			}
+
+			continue
+		}
+
+		canonical = append(canonical, name)
+	}
+
+	paths, err := r.Normalize(canonical)
+	if err != nil {
+		return nil, err
+	}
+
+	relations := make([]Relation, 0, len(paths)+len(aliased))
+	for _, path := range paths {
+		relations = append(relations, Relation{Path: path})
+	}
+
+	for alias, path := range aliased {
+		relations = append(relations, Relation{Path: path, Alias: alias})
+	}
+
+	sortRelations(relations)
+	return relations, nil
+}
+
+// sortRelations ordena as relações pelo caminho canônico e, em seguida,
+// pelo nome alternativo.
+func sortRelations(relations []Relation) {
+	sort.Slice(relations, func(i, j int) bool {
+		if relations[i].Path == relations[j].Path {
+			return relations[i].Alias < relations[j].Alias
+		}
+
+		return relations[i].Path < relations[j].Path
+	})
+}
+
+// Has recebe um caminho de relacionamento e indica se ele é aceito.
+func (r Includes) Has(path string) bool {
+	return r.Relations.Has(path)
+}
+
+// Paths devolve todos os caminhos de relacionamento aceitos, ordenados.
+func (r Includes) Paths() []string {
+	return r.Relations.Paths()
+}
+
+// Has recebe um caminho de relacionamento e indica se ele é aceito.
+//
+// Um caminho é aceito quando cada um dos seus nomes de relacionamento
+// existe na árvore a partir do relacionamento anterior.
+func (r Relations) Has(path string) bool {
+	if path == "" {
+		return false
+	}
+
+	node := r
+	for _, segment := range strings.Split(path, PATH_SEPARATOR) {
+		next, exists := node[segment]
+		if !exists {
+			return false
+		}
+
+		node = next
+	}
+
+	return true
+}
+
+// Paths devolve todos os caminhos de relacionamento aceitos, ordenados.
+//
+//	// map[string]Relations{"comments": {"author": {}}}
+//	r.Paths() // []string{"comments", "comments.author"}
+func (r Relations) Paths() []string {
+	paths := make([]string, 0, len(r))
 
-	for _, val := range values {
-		if _, exists := r[val]; !exists {
-			return ctx, fmt.Errorf("unsupported include relation %s", val)
+	for name, children := range r {
+		paths = append(paths, name)
+
+		for _, child := range children.Paths() {
+			paths = append(paths, name+PATH_SEPARATOR+child)
 		}
 	}
 
-	return context.WithValue(ctx, CtxKey{}, values), nil
+	sort.Strings(paths)
+	return paths
 }
 
-// Get recebe o contexto com os valores de "include" já validados e tratados.
+// Get recebe o contexto com os valores de "include" já validados e tratados
+// e devolve os caminhos canônicos dos relacionamentos que devem ser
+// carregados, sem repetições.
 //
 // Caso o contexto não tenha os valores de "include" será retornado um slice
 // de strings vazio.
 //
 //	[]string{}
 func (r Includes) Get(ctx context.Context) []string {
-	if values, ok := ctx.Value(CtxKey{}).([]string); ok {
+	relations := r.GetRelations(ctx)
+
+	paths := make([]string, 0, len(relations))
+	seen := make(map[string]struct{}, len(relations))
+
+	for _, rel := range relations {
+		if _, duplicate := seen[rel.Path]; duplicate {
+			continue
+		}
+
+		seen[rel.Path] = struct{}{}
+		paths = append(paths, rel.Path)
+	}
+
+	return paths
+}
+
+// GetRelations recebe o contexto com os valores de "include" já validados
+// e devolve as relações com o caminho canônico e o nome alternativo
+// solicitado, se houver.
+//
+// Caso o contexto não tenha os valores de "include" será retornado um slice
+// vazio.
+func (r Includes) GetRelations(ctx context.Context) []Relation {
+	if values, ok := ctx.Value(CtxKey{}).([]Relation); ok {
 		return values
 	}
 
-	return make([]string, 0)
+	return make([]Relation, 0)
 }
 
 // AddRel recebe uma nova relação de campos aceitos no parâmetro "include".
 //
+// A relação pode ser um caminho separado por pontos, nesse caso os
+// relacionamentos intermediários também passam a ser aceitos:
+//
+//	r.AddRel("comments.author") // aceita "comments" e "comments.author"
+//
 // Caso a relação recebida seja duplicada, ela não será adicionada às relações
 // aceitas no campo
-func (r Includes) AddRel(rel string) {
+func (r *Includes) AddRel(rel string) {
+	r.mutable()
+	r.Relations.AddRel(rel)
+}
+
+// AddAlias recebe um nome alternativo para um caminho de relacionamento.
+//
+//	r.AddAlias("commentAuthors", "comments.author")
+//
+// Caso o nome alternativo já exista ele será sobrescrito.
+func (r *Includes) AddAlias(alias, path string) {
+	r.mutable()
+
+	if r.Aliases == nil {
+		r.Aliases = make(map[string]string)
+	}
+
+	r.Aliases[alias] = path
+}
+
+// Freeze congela as relações e os nomes alternativos aceitos. Após
+// congelado, Includes pode ser compartilhado entre goroutines e qualquer
+// chamada de AddRel ou AddAlias entra em pânico.
+//
+// Relations e Aliases são copiados, portanto não são compartilhados com
+// quem montou a configuração. Os campos continuam exportados somente
+// para leitura: alterá-los diretamente, inclusive com Relations.AddRel,
+// não é verificado e não deve ser feito após o congelamento.
+func (r *Includes) Freeze() {
+	r.Relations = r.Relations.clone()
+
+	if r.Aliases != nil {
+		aliases := make(map[string]string, len(r.Aliases))
+		for alias, path := range r.Aliases {
+			aliases[alias] = path
+		}
+
+		r.Aliases = aliases
+	}
+
+	r.frozen = true
+}
+
+// Frozen indica se a configuração foi congelada (veja Freeze).
+func (r Includes) Frozen() bool {
+	return r.frozen
+}
+
+// mutable entra em pânico caso a configuração esteja congelada.
+func (r Includes) mutable() {
+	if r.frozen {
+		panic("include: can not change a frozen configuration")
+	}
+}
+
+// AddRel recebe um novo caminho de relacionamento e o adiciona à árvore.
+//
+// Caso a árvore seja nil ela será criada, portanto o valor zero de
+// Relations pode ser utilizado:
+//
+//	var rels Relations
+//	rels.AddRel("comments.author")
+func (r *Relations) AddRel(rel string) {
+	if *r == nil {
+		*r = make(Relations)
+	}
+
+	node := *r
+	for _, segment := range strings.Split(rel, PATH_SEPARATOR) {
+		if _, duplicate := node[segment]; !duplicate {
+			node[segment] = make(Relations)
+		}
+
+		node = node[segment]
+	}
+}
+
+// clone devolve uma cópia da árvore de relacionamentos.
+func (r Relations) clone() Relations {
 	if r == nil {
-		r = make(Includes)
+		return nil
 	}
 
-	if _, duplicate := r[rel]; !duplicate {
-		r[rel] = struct{}{}
+	copied := make(Relations, len(r))
+	for segment, children := range r {
+		copied[segment] = children.clone()
 	}
+
+	return copied
 }
 
 // Options -----------------
@@ -106,10 +607,56 @@ func AcceptRel(rels ...string) IncludeOpt {
 	}
 }
 
+// Alias é uma opção do construtor de *Includes. Essa função recebe um nome
+// alternativo e o caminho canônico de relacionamento que ele representa.
+//
+//	include.New(include.Alias("commentAuthors", "comments.author"))
+func Alias(alias, path string) IncludeOpt {
+	return func(i *Includes) {
+		i.AddAlias(alias, path)
+	}
+}
+
+// Resource é uma opção do construtor de *Includes. Essa função recebe o tipo
+// de recurso dos dados primários, informado ao Authorizer.
+func Resource(typ string) IncludeOpt {
+	return func(i *Includes) {
+		i.Resource = typ
+	}
+}
+
+// Authorize é uma opção do construtor de *Includes. Essa função recebe o
+// Authorizer consultado para cada caminho de relacionamento solicitado e a
+// política aplicada quando o caminho é negado.
+func Authorize(authorizer authorization.Authorizer, policy authorization.Policy) IncludeOpt {
+	return func(i *Includes) {
+		i.Guard = authorization.Guard{Authorizer: authorizer, Policy: policy}
+	}
+}
+
+// MaxDepth é uma opção do construtor de *Includes que limita a quantidade
+// de relacionamentos em um mesmo caminho do parâmetro "include".
+//
+//	// include=a.b.a.b.a.b
+//	include.New(include.MaxDepth(3)) // include relation a.b.a.b.a.b exceeds max depth of 3
+func MaxDepth(depth int) IncludeOpt {
+	return func(i *Includes) {
+		i.MaxDepth = depth
+	}
+}
+
+// MaxPaths é uma opção do construtor de *Includes que limita a quantidade
+// de caminhos distintos solicitados no parâmetro "include".
+func MaxPaths(paths int) IncludeOpt {
+	return func(i *Includes) {
+		i.MaxPaths = paths
+	}
+}
+
 // Constructor -----------------
 
 func New(opt ...IncludeOpt) *Includes {
-	inc := &Includes{}
+	inc := &Includes{Relations: make(Relations)}
 
 	for _, o := range opt {
 		if o == nil {
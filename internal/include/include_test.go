@@ -14,6 +14,20 @@ func TestNew(t *testing.T) {
 	require.NotNil(t, include.New(nil))
 }
 
+func TestZeroValue(t *testing.T) {
+	var rels include.Relations
+	rels.AddRel("comments.author")
+	require.Equal(t, []string{"comments", "comments.author"}, rels.Paths())
+
+	var inc include.Includes
+	inc.AddRel("author")
+	inc.AddAlias("writer", "author")
+
+	ctx, err := inc.Handle(context.Background(), url.Values{"include": {"writer"}})
+	require.Nil(t, err)
+	require.Equal(t, []string{"author"}, inc.Get(ctx))
+}
+
 func TestHandle(t *testing.T) {
 	testtable := []struct {
 		testdescription string
@@ -43,6 +57,34 @@ func TestHandle(t *testing.T) {
 			want:            []string{},
 			err:             fmt.Errorf("unsupported include relation posts"),
 		},
+		{
+			testdescription: "should accept parent path of accepted nested path",
+			include:         "comments",
+			acceptable:      []string{"comments.author"},
+			want:            []string{"comments"},
+			err:             nil,
+		},
+		{
+			testdescription: "should expand intermediate relationships",
+			include:         "comments.author.avatar",
+			acceptable:      []string{"comments.author.avatar", "posts"},
+			want:            []string{"comments", "comments.author", "comments.author.avatar"},
+			err:             nil,
+		},
+		{
+			testdescription: "should deduplicate repeated paths",
+			include:         "comments.author,comments,comments.author",
+			acceptable:      []string{"comments.author"},
+			want:            []string{"comments", "comments.author"},
+			err:             nil,
+		},
+		{
+			testdescription: "should fail if nested path is not accepted",
+			include:         "comments.posts",
+			acceptable:      []string{"comments.author"},
+			want:            []string{},
+			err:             fmt.Errorf("unsupported include relation comments.posts"),
+		},
 		{
 			testdescription: "should try with wrong values",
 			include:         "",
@@ -67,3 +109,168 @@ func TestHandle(t *testing.T) {
 	}
 
 }
+
+func TestNormalize(t *testing.T) {
+	inc := include.New(include.AcceptRel("comments.author", "posts"))
+
+	paths, err := inc.Normalize([]string{"posts", "comments.author", "posts"})
+	require.Nil(t, err)
+	require.Equal(t, []string{"comments", "comments.author", "posts"}, paths)
+
+	_, err = inc.Normalize([]string{""})
+	require.EqualError(t, err, "unsupported include relation ")
+}
+
+func TestPaths(t *testing.T) {
+	inc := include.New(include.AcceptRel("comments.author", "comments", "posts"))
+
+	require.Len(t, inc.Relations, 2)
+	require.Equal(t, []string{"comments", "comments.author", "posts"}, inc.Paths())
+	require.True(t, inc.Has("comments.author"))
+	require.False(t, inc.Has("author"))
+}
+
+func TestLimits(t *testing.T) {
+	testtable := []struct {
+		desc    string
+		include string
+		opts    []include.IncludeOpt
+		err     error
+	}{
+		{
+			desc:    "should fail if path is deeper than max depth",
+			include: "a.b.a",
+			opts:    []include.IncludeOpt{include.MaxDepth(2)},
+			err:     fmt.Errorf("include relation a.b.a exceeds max depth of 2"),
+		},
+		{
+			desc:    "should accept path within max depth",
+			include: "a.b",
+			opts:    []include.IncludeOpt{include.MaxDepth(2)},
+		},
+		{
+			desc:    "should fail if has more paths than max paths",
+			include: "a,a.b,c",
+			opts:    []include.IncludeOpt{include.MaxPaths(2)},
+			err:     fmt.Errorf("include exceeds max of 2 relation paths"),
+		},
+		{
+			desc:    "should check the depth of aliased paths",
+			include: "deep",
+			opts:    []include.IncludeOpt{include.MaxDepth(1), include.Alias("deep", "a.b")},
+			err:     fmt.Errorf("include relation a.b exceeds max depth of 1"),
+		},
+		{
+			desc:    "should count the intermediate paths",
+			include: "a.b,c",
+			opts:    []include.IncludeOpt{include.MaxPaths(2)},
+			err:     fmt.Errorf("include exceeds max of 2 relation paths"),
+		},
+		{
+			desc:    "should count repeated paths once",
+			include: "a,a,c",
+			opts:    []include.IncludeOpt{include.MaxPaths(2)},
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			opts := append([]include.IncludeOpt{include.AcceptRel("a.b.a.b", "c")}, tt.opts...)
+			inc := include.New(opts...)
+
+			_, err := inc.Handle(context.Background(), url.Values{include.SEARCH_PARAM: {tt.include}})
+			require.EqualValues(t, tt.err, err)
+		})
+	}
+}
+
+func TestAlias(t *testing.T) {
+	testtable := []struct {
+		desc      string
+		include   string
+		want      []string
+		relations []include.Relation
+		err       error
+	}{
+		{
+			desc:    "should resolve alias to canonical path",
+			include: "commentAuthors",
+			want:    []string{"comments", "comments.author"},
+			relations: []include.Relation{
+				{Path: "comments"},
+				{Path: "comments.author", Alias: "commentAuthors"},
+			},
+		},
+		{
+			desc:    "should report alias and canonical path",
+			include: "commentAuthors,comments.author,posts",
+			want:    []string{"comments", "comments.author", "posts"},
+			relations: []include.Relation{
+				{Path: "comments"},
+				{Path: "comments.author"},
+				{Path: "comments.author", Alias: "commentAuthors"},
+				{Path: "posts"},
+			},
+		},
+		{
+			desc:      "should fail with unknown alias",
+			include:   "postAuthors",
+			want:      []string{},
+			relations: []include.Relation{},
+			err:       fmt.Errorf("unsupported include relation postAuthors"),
+		},
+		{
+			desc:      "should fail with alias to unknown relation",
+			include:   "ghosts",
+			want:      []string{},
+			relations: []include.Relation{},
+			err:       fmt.Errorf("include alias ghosts targets unsupported relation nonexistent"),
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			inc := include.New(
+				include.AcceptRel("comments.author", "posts"),
+				include.Alias("commentAuthors", "comments.author"),
+				include.Alias("ghosts", "nonexistent"),
+			)
+
+			ctx, err := inc.Handle(context.Background(), url.Values{include.SEARCH_PARAM: {tt.include}})
+			require.EqualValues(t, tt.err, err)
+			require.Equal(t, tt.want, inc.Get(ctx))
+			require.Equal(t, tt.relations, inc.GetRelations(ctx))
+		})
+	}
+
+	t.Run("should name relation by alias", func(t *testing.T) {
+		require.Equal(t, "commentAuthors", include.Relation{Path: "comments.author", Alias: "commentAuthors"}.Name())
+		require.Equal(t, "comments.author", include.Relation{Path: "comments.author"}.Name())
+	})
+}
+
+func TestMerge(t *testing.T) {
+	inc := include.New(include.AcceptRel("comments.author", "posts"))
+
+	ctx, err := inc.Handle(context.Background(), url.Values{include.SEARCH_PARAM: {"posts,comments"}})
+	require.Nil(t, err)
+
+	ctx, err = inc.Merge(ctx, []string{"comments.author"})
+	require.Nil(t, err)
+	require.Equal(t, []string{"comments", "comments.author", "posts"}, inc.Get(ctx))
+
+	_, err = inc.Merge(ctx, []string{"author"})
+	require.EqualError(t, err, "unsupported include relation author")
+
+	limited := include.New(include.AcceptRel("comments.author", "posts"), include.MaxPaths(2))
+
+	ctx, err = limited.Handle(context.Background(), url.Values{include.SEARCH_PARAM: {"posts"}})
+	require.Nil(t, err)
+
+	_, err = limited.Merge(ctx, []string{"comments.author"})
+	require.EqualError(t, err, "include exceeds max of 2 relation paths")
+
+	_, err = include.New(include.AcceptRel("comments.author"), include.MaxDepth(1)).
+		Merge(context.Background(), []string{"comments.author"})
+	require.EqualError(t, err, "include relation comments.author exceeds max depth of 1")
+}
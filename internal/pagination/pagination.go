@@ -30,6 +30,34 @@ type PaginationOpt func(*Pagination)
 // structs vazias tem mais performance.
 type CtxKey struct{}
 
+// Page é o valor armazenado no contexto após o Handle.
+//
+// Page mantém os valores da request já mesclados sobre os valores
+// padrão configurados e também os valores que foram de fato enviados
+// pelo cliente, permitindo diferenciar um valor padrão de um valor
+// solicitado.
+type Page struct {
+	values Pagination
+	sent   Pagination
+}
+
+// Get devolve o valor do campo de "page" já mesclado com o padrão.
+//
+// Caso o campo não seja uma das propriedades aceitas será retornado -1
+func (p Page) Get(field accepted) int {
+	if value, ok := p.values[field]; ok {
+		return value
+	}
+
+	return -1
+}
+
+// IsSet indica se o campo de "page" foi enviado pelo cliente na request.
+func (p Page) IsSet(field accepted) bool {
+	_, ok := p.sent[field]
+	return ok
+}
+
 // accepted é um custom type para as propriedades aceitas
 // para o parâmetro de busca "page"
 type accepted string
@@ -41,6 +69,10 @@ const (
 	NUMBER accepted = "number"
 	OFFSET accepted = "offset"
 	LIMIT  accepted = "size"
+
+	// MAX é o tamanho máximo aceito em page[size]. Não é um parâmetro da
+	// query, somente uma configuração (veja MaxPageSize).
+	MAX accepted = "max"
 )
 
 var (
@@ -55,6 +87,14 @@ var (
 	//  matches[0] = page[number]
 	//  matches[1] = number
 	pageMatcher = regexp.MustCompile(`^page\[([a-zA-Z_0-9]+)\]$`).FindStringSubmatch
+
+	// minimum são os menores valores aceitos em cada propriedade de "page".
+	// Um size zero significaria uma página sem limite nos backends.
+	minimum = map[accepted]int{
+		SIZE:   1,
+		NUMBER: 1,
+		OFFSET: 0,
+	}
 )
 
 // extractPaginationFromQuery recebe a query e devolve um novo
@@ -100,6 +140,23 @@ func decode(query url.Values) (Pagination, error) {
 	return pagination, nil
 }
 
+// Encode recebe o Page e devolve a query com os valores de "page" que
+// foram enviados pelo cliente, inversa do decode.
+//
+// Os valores padrão não são codificados, portanto IsSet é preservado:
+//
+//	// ?page[number]=2
+//	Encode(page) // url.Values{"page[number]": {"2"}}
+func Encode(page Page) url.Values {
+	query := make(url.Values, len(page.sent))
+
+	for field, value := range page.sent {
+		query.Set(PAGE_PARAM+"["+string(field)+"]", strconv.Itoa(value))
+	}
+
+	return query
+}
+
 // StrToPageParam recebe a propriedade (size / number) e checa se
 // é valida. Se for válida, retorna no formato accepted.
 //
@@ -134,24 +191,56 @@ func StrToPageParam(p string) (accepted, error) {
 // retornado um erro de campo inválido / faltando.
 //
 // O parâmetro page só deve ser recebido com valores aceitos ou não deve ser utilizado.
+// Os valores devem respeitar os mínimos de cada propriedade (size e number
+// a partir de 1, offset a partir de 0), mesmo sem MaxPageSize.
 func (p Pagination) Handle(ctx context.Context, query url.Values) (context.Context, error) {
 	query = extractPaginationFromQuery(query)
 	if len(query) == 0 {
 		return ctx, nil
 	}
 
-	pagination, err := decode(query)
+	sent, err := decode(query)
 	if err != nil {
 		return ctx, err
 	}
 
-	return context.WithValue(ctx, CtxKey{}, pagination), nil
+	for _, param := range []accepted{SIZE, NUMBER, OFFSET} {
+		if value, ok := sent[param]; ok && value < minimum[param] {
+			return ctx, fmt.Errorf("pagination param %s should be at least %d", param, minimum[param])
+		}
+	}
+
+	if max := p[MAX]; max > 0 && sent[SIZE] > max {
+		return ctx, fmt.Errorf("pagination param size exceeds max of %d", max)
+	}
+
+	return context.WithValue(ctx, CtxKey{}, p.merge(sent)), nil
+}
+
+// merge recebe os valores enviados na request e os mescla sobre os
+// valores padrão configurados, devolvendo um Page.
+func (p Pagination) merge(sent Pagination) Page {
+	values := make(Pagination, len(p)+len(sent))
+
+	for k, v := range p {
+		values[k] = v
+	}
+
+	for k, v := range sent {
+		values[k] = v
+	}
+
+	return Page{values: values, sent: sent}
 }
 
 // Get recebe o contexto e a chave do campo de "page" já validada e tratada.
 //
-// Caso o contexto não tenha o valor do campo e o campo não de match com
-// nenhuma das propriedades aceitas (number / size), será retornado -1
+// Os valores enviados na request são mesclados sobre os valores padrão,
+// portanto uma request somente com page[number]=2 ainda devolve o size
+// padrão configurado.
+//
+// Caso o campo não de match com nenhuma das propriedades aceitas
+// (number / size / offset), será retornado -1
 //
 // Exemplos:
 //
@@ -160,8 +249,8 @@ func (p Pagination) Handle(ctx context.Context, query url.Values) (context.Conte
 //	pagination.Get(ctx, SIZE)	// 10 - default
 //	pagination.Get(ctx, NUMBER)	// 1  - default
 func (p Pagination) Get(ctx context.Context, field accepted) int {
-	if values, ok := ctx.Value(CtxKey{}).(Pagination); ok {
-		return values[field]
+	if page, ok := ctx.Value(CtxKey{}).(Page); ok {
+		return page.Get(field)
 	}
 
 	switch field {
@@ -176,6 +265,49 @@ func (p Pagination) Get(ctx context.Context, field accepted) int {
 	return -1
 }
 
+// GetPage recebe o contexto e devolve o Page da request.
+//
+// Caso o parâmetro "page" não tenha sido enviado, o Page devolvido
+// contém somente os valores padrão configurados.
+func (p Pagination) GetPage(ctx context.Context) Page {
+	if page, ok := ctx.Value(CtxKey{}).(Page); ok {
+		return page
+	}
+
+	return p.merge(nil)
+}
+
+// IsSet recebe o contexto e a chave do campo de "page" e indica se o
+// valor foi enviado pelo cliente ou se é o valor padrão configurado.
+//
+// Exemplos:
+//
+//	// ?page[number]=2
+//	pagination.IsSet(ctx, NUMBER)	// true
+//	pagination.IsSet(ctx, SIZE)	// false - default
+func (p Pagination) IsSet(ctx context.Context, field accepted) bool {
+	if page, ok := ctx.Value(CtxKey{}).(Page); ok {
+		return page.IsSet(field)
+	}
+
+	return false
+}
+
+// Copy devolve uma cópia dos valores padrão, que pode ser alterada sem
+// afetar a configuração original.
+func (p Pagination) Copy() Pagination {
+	if p == nil {
+		return nil
+	}
+
+	copied := make(Pagination, len(p))
+	for param, value := range p {
+		copied[param] = value
+	}
+
+	return copied
+}
+
 // DefaultPageSize altera o tamanho padrão do parâmetro size
 //
 // @Default = 10
@@ -188,10 +320,30 @@ func DefaultPageSize(pageSize uint32) PaginationOpt {
 			pageSize = 10
 		}
 
-		(*p)[SIZE] = int(pageSize)
+		p.set(SIZE, int(pageSize))
 	}
 }
 
+// MaxPageSize limita o tamanho solicitado em page[size]. Requests com
+// um tamanho maior são rejeitadas.
+//
+// Zero indica que não há limite.
+func MaxPageSize(pageSize uint32) PaginationOpt {
+	return func(p *Pagination) {
+		p.set(MAX, int(pageSize))
+	}
+}
+
+// set altera o valor padrão do campo, iniciando o map caso a Pagination
+// seja zero valued.
+func (p *Pagination) set(field accepted, value int) {
+	if *p == nil {
+		*p = make(Pagination)
+	}
+
+	(*p)[field] = value
+}
+
 // Constructor -----------------
 
 func New(opt ...PaginationOpt) *Pagination {
@@ -33,9 +33,16 @@ func TestPagination(t *testing.T) {
 				"page[offset]": {"30"},
 				"page[limit]":  {"30"},
 			},
+			expectNumber: 1, // default
 			expectOffset: 30,
 			expectSize:   30,
 		},
+		{
+			desc:         "should merge sent params over defaults",
+			query:        url.Values{"page[number]": {"2"}},
+			expectNumber: 2,
+			expectSize:   10,
+		},
 		{
 			desc:         "should fail if passed invalid prop",
 			query:        url.Values{"page[total]": {"1"}},
@@ -104,6 +111,53 @@ func TestPagination(t *testing.T) {
 		require.Equal(t, 10, pagination.Get(ctx, SIZE))
 	})
 
+	t.Run("should reject size over the max", func(t *testing.T) {
+		pagination := New(MaxPageSize(50))
+
+		ctx, err := pagination.Handle(context.Background(), url.Values{"page[size]": {"50"}})
+		require.Nil(t, err)
+		require.Equal(t, 50, pagination.Get(ctx, SIZE))
+
+		_, err = pagination.Handle(context.Background(), url.Values{"page[limit]": {"51"}})
+		require.EqualError(t, err, "pagination param size exceeds max of 50")
+	})
+
+	t.Run("should reject values under the minimum", func(t *testing.T) {
+		testtable := []struct {
+			desc  string
+			query url.Values
+			err   string
+		}{
+			{desc: "zero size", query: url.Values{"page[size]": {"0"}}, err: "pagination param size should be at least 1"},
+			{desc: "negative size", query: url.Values{"page[limit]": {"-5"}}, err: "pagination param size should be at least 1"},
+			{desc: "zero number", query: url.Values{"page[number]": {"0"}}, err: "pagination param number should be at least 1"},
+			{desc: "negative number", query: url.Values{"page[number]": {"-1"}}, err: "pagination param number should be at least 1"},
+			{desc: "negative offset", query: url.Values{"page[offset]": {"-1"}}, err: "pagination param offset should be at least 0"},
+		}
+
+		for _, tt := range testtable {
+			t.Run(tt.desc, func(t *testing.T) {
+				// o mínimo não depende de MaxPageSize
+				_, err := New().Handle(context.Background(), tt.query)
+				require.EqualError(t, err, tt.err)
+			})
+		}
+
+		ctx, err := New().Handle(context.Background(), url.Values{"page[offset]": {"0"}, "page[size]": {"1"}})
+		require.Nil(t, err)
+		require.Equal(t, 1, New().Get(ctx, SIZE))
+	})
+
+	t.Run("should configure zero valued pagination", func(t *testing.T) {
+		var p Pagination
+		DefaultPageSize(10)(&p)
+		MaxPageSize(50)(&p)
+
+		ctx, err := p.Handle(context.Background(), url.Values{"page[size]": {"51"}})
+		require.EqualError(t, err, "pagination param size exceeds max of 50")
+		require.Equal(t, 10, p.Get(ctx, SIZE))
+	})
+
 	t.Run("should instantiate with default", func(t *testing.T) {
 		pagination := New(nil)
 		ctx, err := pagination.Handle(
@@ -115,3 +169,62 @@ func TestPagination(t *testing.T) {
 		require.Equal(t, 10, pagination.Get(ctx, SIZE))
 	})
 }
+
+func TestIsSet(t *testing.T) {
+	testtable := []struct {
+		desc      string
+		query     url.Values
+		expectSet map[accepted]bool
+	}{
+		{
+			desc:  "should tell sent params from defaults",
+			query: url.Values{"page[number]": {"2"}},
+			expectSet: map[accepted]bool{
+				NUMBER: true,
+				SIZE:   false,
+				OFFSET: false,
+			},
+		},
+		{
+			desc:  "should tell limit as size",
+			query: url.Values{"page[limit]": {"5"}},
+			expectSet: map[accepted]bool{
+				NUMBER: false,
+				LIMIT:  true,
+			},
+		},
+		{
+			desc:  "should not be set without page param",
+			query: url.Values{},
+			expectSet: map[accepted]bool{
+				NUMBER: false,
+				SIZE:   false,
+			},
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			pagination := New(DefaultPageSize(20))
+			ctx, err := pagination.Handle(context.Background(), tt.query)
+			require.Nil(t, err)
+
+			for field, set := range tt.expectSet {
+				require.Equal(t, set, pagination.IsSet(ctx, field), "field %s", field)
+			}
+		})
+	}
+
+	t.Run("should keep configured default when not sent", func(t *testing.T) {
+		pagination := New(DefaultPageSize(20))
+		ctx, err := pagination.Handle(
+			context.Background(),
+			url.Values{"page[number]": {"3"}},
+		)
+
+		require.Nil(t, err)
+		require.Equal(t, 3, pagination.Get(ctx, NUMBER))
+		require.Equal(t, 20, pagination.Get(ctx, SIZE))
+		require.Equal(t, -1, pagination.Get(ctx, "invalid"))
+	})
+}
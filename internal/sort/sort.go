@@ -6,6 +6,8 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+
+	"github.com/jeanmolossi/gosparse/internal/authorization"
 )
 
 // Sorting é um custom type para os valores de ordenação aceitos
@@ -24,15 +26,49 @@ type Sorting byte
 //	}
 type Sort map[string]Sorting
 
+// Sorter armazena a configuração do parâmetro de busca "sort".
+//
+// O valor zero de Sorter não aceita nenhum campo e pode ser configurado
+// diretamente com AddField.
+type Sorter struct {
+	// Accepted são os campos aceitos no parâmetro "sort"
+	Accepted Sort
+	// Resource é o tipo de recurso dos dados primários, informado ao
+	// Authorizer do Guard
+	Resource string
+	// Guard consulta a autorização de cada campo ordenado
+	Guard authorization.Guard
+
+	frozen bool
+}
+
+// Key é um campo ordenado com a respectiva ordenação, na ordem em que foi
+// solicitado no parâmetro "sort":
+//
+//	// sort=-created_at,title
+//	[]Key{{"created_at", DESC}, {"title", ASC}}
+type Key struct {
+	Field   string
+	Sorting Sorting
+}
+
 // CtxKey é uma chave para o contexto.
 // structs vazias tem mais performance.
 type CtxKey struct{}
 
+// orderCtxKey é a chave do contexto para a ordem dos campos ([]Key).
+type orderCtxKey struct{}
+
 const (
 	ASC Sorting = iota
 	DESC
 
 	SORT_PARAM string = "sort"
+
+	// PATH_SEPARATOR separa os segmentos de um atributo de relacionamento
+	//
+	//	author.name
+	PATH_SEPARATOR string = "."
 )
 
 // extractSortFromQuery recebe a query e devolve um novo
@@ -57,37 +93,97 @@ func extractSortFromQuery(query url.Values) url.Values {
 //
 // Caso o parâmetro de "sort" não seja informado o servidor pode aplicar
 // parâmetros de ordenação padrão.
-func (s Sort) Handle(ctx context.Context, query url.Values) (context.Context, error) {
+//
+// Cada campo ordenado também é autorizado pelo Guard.
+func (s Sorter) Handle(ctx context.Context, query url.Values) (context.Context, error) {
 	query = extractSortFromQuery(query)
 	if len(query) == 0 {
 		return ctx, nil
 	}
 
-	sort, err := Decode(query)
+	keys, err := DecodeOrder(query)
 	if err != nil {
 		return ctx, err
 	}
 
-	for field := range sort {
-		if _, exists := s[field]; !exists {
-			return ctx, fmt.Errorf("unsupported sorting by: %s", field)
+	sort := make(Sort, len(keys))
+	order := make([]Key, 0, len(keys))
+
+	for _, key := range keys {
+		if _, exists := s.Accepted[key.Field]; !exists {
+			return ctx, fmt.Errorf("unsupported sorting by: %s", key.Field)
+		}
+
+		allowed, err := s.Guard.Check(ctx, authorization.SORT, s.Resource, key.Field)
+		if err != nil {
+			return ctx, err
+		}
+
+		if allowed {
+			sort[key.Field] = key.Sorting
+			order = append(order, key)
 		}
 	}
 
+	ctx = context.WithValue(ctx, orderCtxKey{}, order)
 	return context.WithValue(ctx, CtxKey{}, sort), nil
 }
 
 // hasInvalidChars checa se o campo recebido está dentro do range de
-// caracteres aceitos de acordo com o regexp
+// caracteres aceitos de acordo com o regexp. Os pontos separam os
+// segmentos de um atributo de relacionamento:
+//
+//	author.name
 func hasInvalidChars(f string) bool {
-	return regexp.MustCompile(`[a-zA-Z_0-9]+`).ReplaceAllString(f, "") != ""
+	return regexp.MustCompile(`[a-zA-Z_0-9.]+`).ReplaceAllString(f, "") != ""
+}
+
+// hasEmptySegment checa se o caminho separado por pontos tem algum
+// segmento vazio, como em "author..name" ou ".name".
+func hasEmptySegment(f string) bool {
+	if !strings.Contains(f, PATH_SEPARATOR) {
+		return false
+	}
+
+	for _, segment := range strings.Split(f, PATH_SEPARATOR) {
+		if segment == "" {
+			return true
+		}
+	}
+
+	return false
 }
 
 // Decode recebe a query e extrai os campos e valores da query.
 func Decode(query url.Values) (Sort, error) {
-	sort := Sort{}
+	keys, err := DecodeOrder(query)
+	if err != nil {
+		return nil, err
+	}
+
+	sort := make(Sort, len(keys))
+	for _, key := range keys {
+		sort[key.Field] = key.Sorting
+	}
 
-	for _, field := range strings.Split(query.Get(SORT_PARAM), ",") {
+	return sort, nil
+}
+
+// DecodeOrder recebe a query e extrai os campos ordenados na ordem em que
+// foram solicitados.
+//
+// Caso um campo seja repetido, ele mantém a posição da primeira ocorrência
+// com a ordenação da última:
+//
+//	// sort=title,-created_at,-title
+//	[]Key{{"title", DESC}, {"created_at", DESC}}
+func DecodeOrder(query url.Values) ([]Key, error) {
+	fields := strings.Split(query.Get(SORT_PARAM), ",")
+
+	keys := make([]Key, 0, len(fields))
+	position := make(map[string]int, len(fields))
+
+	for _, field := range fields {
 		sorting := ASC
 
 		if strings.HasPrefix(field, "-") {
@@ -99,10 +195,47 @@ func Decode(query url.Values) (Sort, error) {
 			return nil, fmt.Errorf("%s not acceptable, only [a-zA-Z_0-9]", field)
 		}
 
-		sort[field] = sorting
+		if hasEmptySegment(field) {
+			return nil, fmt.Errorf("%s not acceptable, empty path segment", field)
+		}
+
+		if i, duplicate := position[field]; duplicate {
+			keys[i].Sorting = sorting
+			continue
+		}
+
+		position[field] = len(keys)
+		keys = append(keys, Key{Field: field, Sorting: sorting})
 	}
 
-	return sort, nil
+	return keys, nil
+}
+
+// Encode recebe os campos ordenados e devolve a query correspondente,
+// inversa de DecodeOrder:
+//
+//	Encode([]Key{{"created_at", DESC}, {"title", ASC}})
+//	// url.Values{"sort": {"-created_at,title"}}
+//
+// Caso não haja campos ordenados será devolvida uma query vazia.
+func Encode(keys []Key) url.Values {
+	query := url.Values{}
+	if len(keys) == 0 {
+		return query
+	}
+
+	fields := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key.Sorting == DESC {
+			fields = append(fields, "-"+key.Field)
+			continue
+		}
+
+		fields = append(fields, key.Field)
+	}
+
+	query.Set(SORT_PARAM, strings.Join(fields, ","))
+	return query
 }
 
 // GetSort extraí o Sort recebido na request a partir do contexto.
@@ -119,7 +252,7 @@ func GetSort(ctx context.Context) (Sort, error) {
 //
 // Caso a chave do campo não esteja presente no contexto será devolvida
 // a ordenação padrão ASC
-func (s Sort) Get(ctx context.Context, f string) Sorting {
+func (s Sorter) Get(ctx context.Context, f string) Sorting {
 	if sorting, present := ctx.Value(CtxKey{}).(Sort); present {
 		return sorting[f]
 	}
@@ -131,7 +264,7 @@ func (s Sort) Get(ctx context.Context, f string) Sorting {
 //
 // Caso o contexto não contenha o Sort, uma instância vazia será
 // devolvida.
-func (s Sort) GetAll(ctx context.Context) Sort {
+func (s Sorter) GetAll(ctx context.Context) Sort {
 	sort, err := GetSort(ctx)
 	if err != nil {
 		return make(Sort)
@@ -140,6 +273,18 @@ func (s Sort) GetAll(ctx context.Context) Sort {
 	return sort
 }
 
+// GetOrder recebe o contexto e devolve os campos ordenados na ordem em que
+// foram solicitados no parâmetro "sort".
+//
+// Caso o contexto não contenha a ordenação, um slice vazio será devolvido.
+func (s Sorter) GetOrder(ctx context.Context) []Key {
+	if order, present := ctx.Value(orderCtxKey{}).([]Key); present {
+		return order
+	}
+
+	return make([]Key, 0)
+}
+
 // AddField recebe o campo aceito no parâmetro "sort".
 //
 // Caso a chave recebida já esteja na lista de campos suportados, ela
@@ -147,24 +292,56 @@ func (s Sort) GetAll(ctx context.Context) Sort {
 //
 // O sort por padrão de AddField é ASC, porém deve-se utilizar
 // o valor armazenado em contexto
-func (s Sort) AddField(field string) {
-	if s == nil {
-		s = make(Sort)
+func (s *Sorter) AddField(field string) {
+	s.mutable()
+
+	if s.Accepted == nil {
+		s.Accepted = make(Sort)
 	}
 
-	if _, duplicate := s[field]; !duplicate {
-		s[field] = ASC
+	if _, duplicate := s.Accepted[field]; !duplicate {
+		s.Accepted[field] = ASC
+	}
+}
+
+// Freeze congela os campos aceitos no parâmetro "sort". Chamadas de
+// AddField após o congelamento entram em pânico.
+//
+// Accepted é copiado, portanto não é compartilhado com quem montou a
+// configuração, e continua exportado somente para leitura.
+func (s *Sorter) Freeze() {
+	if s.Accepted != nil {
+		accepted := make(Sort, len(s.Accepted))
+		for field, sorting := range s.Accepted {
+			accepted[field] = sorting
+		}
+
+		s.Accepted = accepted
+	}
+
+	s.frozen = true
+}
+
+// Frozen indica se a configuração foi congelada (veja Freeze).
+func (s Sorter) Frozen() bool {
+	return s.frozen
+}
+
+// mutable entra em pânico caso a configuração esteja congelada.
+func (s Sorter) mutable() {
+	if s.frozen {
+		panic("sort: can not change a frozen configuration")
 	}
 }
 
 // Options -----------------
 
-type SortOpt func(*Sort)
+type SortOpt func(*Sorter)
 
-// AcceptField é uma opção do construtor de *Sort. Essa função recebe um
+// AcceptField é uma opção do construtor de *Sorter. Essa função recebe um
 // slice de strings que serão as chaves de campos aceitos ao validar a query da request
 func AcceptField(fields ...string) SortOpt {
-	return func(f *Sort) {
+	return func(f *Sorter) {
 		if len(fields) == 0 {
 			return
 		}
@@ -175,10 +352,27 @@ func AcceptField(fields ...string) SortOpt {
 	}
 }
 
+// Resource é uma opção do construtor de *Sorter. Essa função recebe o tipo
+// de recurso dos dados primários, informado ao Authorizer.
+func Resource(typ string) SortOpt {
+	return func(s *Sorter) {
+		s.Resource = typ
+	}
+}
+
+// Authorize é uma opção do construtor de *Sorter. Essa função recebe o
+// Authorizer consultado para cada campo ordenado e a política aplicada
+// quando o campo é negado.
+func Authorize(authorizer authorization.Authorizer, policy authorization.Policy) SortOpt {
+	return func(s *Sorter) {
+		s.Guard = authorization.Guard{Authorizer: authorizer, Policy: policy}
+	}
+}
+
 // Constructor -----------------
 
-func New(opt ...SortOpt) *Sort {
-	sort := &Sort{}
+func New(opt ...SortOpt) *Sorter {
+	sort := &Sorter{Accepted: make(Sort)}
 
 	for _, o := range opt {
 		if o == nil {
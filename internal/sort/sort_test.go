@@ -63,6 +63,17 @@ func TestHandle(t *testing.T) {
 
 		require.EqualValues(t, ctx, context.Background())
 	})
+
+	t.Run("should accept relationship attributes", func(t *testing.T) {
+		sort := New(AcceptField("author.name"))
+
+		ctx, err := sort.Handle(context.Background(), url.Values{"sort": {"-author.name"}})
+		require.Nil(t, err)
+		require.Equal(t, []Key{{Field: "author.name", Sorting: DESC}}, sort.GetOrder(ctx))
+
+		_, err = sort.Handle(context.Background(), url.Values{"sort": {"author..name"}})
+		require.EqualError(t, err, "author..name not acceptable, empty path segment")
+	})
 }
 
 func TestGet(t *testing.T) {
@@ -117,3 +128,40 @@ func TestGetSort(t *testing.T) {
 		require.EqualError(t, err, "sorter is not present on context")
 	})
 }
+
+func TestFreeze(t *testing.T) {
+	sort := New(AcceptField("created_at"))
+	require.False(t, sort.Frozen())
+
+	sort.Freeze()
+	require.True(t, sort.Frozen())
+	require.PanicsWithValue(t, "sort: can not change a frozen configuration", func() {
+		sort.AddField("title")
+	})
+
+	// a cópia de uma configuração congelada também é congelada
+	copied := *sort
+	require.Panics(t, func() { AcceptField("title")(&copied) })
+	require.NotContains(t, sort.Accepted, "title")
+}
+
+func TestZeroValue(t *testing.T) {
+	var sort Sorter
+	sort.AddField("title")
+
+	ctx, err := sort.Handle(context.Background(), url.Values{"sort": {"-title"}})
+	require.Nil(t, err)
+	require.Equal(t, DESC, sort.Get(ctx, "title"))
+}
+
+func TestDecodeOrder(t *testing.T) {
+	keys, err := DecodeOrder(url.Values{"sort": {"title,-created_at,-title"}})
+	require.Nil(t, err)
+	require.Equal(t, []Key{{"title", DESC}, {"created_at", DESC}}, keys)
+
+	sort := New(AcceptField("created_at", "title"))
+	ctx, err := sort.Handle(context.Background(), url.Values{"sort": {"-created_at,title"}})
+	require.Nil(t, err)
+	require.Equal(t, []Key{{"created_at", DESC}, {"title", ASC}}, sort.GetOrder(ctx))
+	require.Empty(t, sort.GetOrder(context.Background()))
+}
@@ -0,0 +1,11 @@
+// Package source
+//
+// Carrega os pacotes Go a partir do código fonte (go/packages) e interpreta as
+// tags "gosparse" das estruturas da mesma forma que gosparse.Compile, sem
+// executar o código do pacote. É utilizado pelos comandos que inspecionam
+// ou geram código a partir das estruturas:
+//
+//	pkg, err := source.Load(".", "./models")
+//	_, st, err := pkg.Lookup("Article")
+//	schema, err := source.Compile(st)
+package source
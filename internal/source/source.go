@@ -0,0 +1,457 @@
+package source
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"reflect"
+	stdsort "sort"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"golang.org/x/tools/go/packages"
+)
+
+// Field é um campo com a tag "gosparse" encontrado a partir da estrutura
+// compilada, com a configuração da tag e a variável do campo.
+type Field struct {
+	gosparse.Field
+	// Var é a variável do campo na estrutura, com o nome e o tipo
+	Var *types.Var
+	// Names são os nomes dos campos Go percorridos a partir da estrutura
+	// compilada
+	//
+	//	// author.name
+	//	[]string{"Author", "Name"}
+	Names []string
+}
+
+// Schema é a configuração de parâmetros aceitos de uma estrutura, montada
+// a partir das tags "gosparse" da mesma forma que gosparse.Compile, porém
+// sem executar o código do pacote.
+//
+// Como o código não é executado, o tipo de recurso definido pelo método
+// TypeName (gosparse.TypeNamer) não é conhecido, somente o definido pela
+// configuração "type" da tag de um campo em branco (_).
+type Schema struct {
+	// Resource é o tipo de recurso dos dados primários
+	Resource string
+	// Fields são os campos com a tag, incluindo os campos das relações com
+	// o nome da relação como prefixo (veja gosparse.Schema.Field)
+	Fields map[string]Field
+	// Paths são os caminhos dos campos em ordem alfabética
+	Paths []string
+	// Aliases são os nomes alternativos dos caminhos de relacionamento
+	Aliases map[string]string
+	// Fieldsets são os atributos aceitos de cada tipo de recurso, na ordem
+	// dos campos das estruturas
+	Fieldsets map[string][]string
+	// Always são os atributos sempre retornados de cada tipo de recurso
+	Always map[string][]string
+	// Defaults são os atributos da seleção padrão de cada tipo de recurso
+	Defaults map[string][]string
+}
+
+// Package é um pacote Go carregado a partir do código fonte.
+type Package struct {
+	*types.Package
+	// Dir é o diretório do pacote
+	Dir string
+	// Errors são os erros encontrados na verificação dos tipos. O pacote é
+	// verificado por completo mesmo com erros, por exemplo quando o código
+	// referencia um arquivo gerado que ainda não existe.
+	Errors []error
+}
+
+// Load carrega o pacote Go (pattern, um caminho de import ou um diretório
+// relativo a dir) com go/packages e verifica os tipos a partir do código
+// fonte, inclusive dos pacotes importados. Os arquivos e as dependências
+// são resolvidos pelo go list, portanto o go.mod, as build tags e o cgo
+// são respeitados da mesma forma que no go build.
+//
+// Os arquivos do pacote com os nomes em exclude são ignorados.
+func Load(dir, pattern string, exclude ...string) (*Package, error) {
+	excluded := make(map[string]struct{}, len(exclude))
+	for _, name := range exclude {
+		excluded[name] = struct{}{}
+	}
+
+	config := &packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedImports | packages.NeedDeps | packages.NeedTypes | packages.NeedSyntax,
+		Dir:  dir,
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			mode := parser.AllErrors | parser.ParseComments
+			if _, skip := excluded[filepath.Base(filename)]; skip {
+				// somente a declaração do pacote, sem as declarações do
+				// arquivo ignorado
+				mode = parser.PackageClauseOnly
+			}
+
+			return parser.ParseFile(fset, filename, src, mode)
+		},
+	}
+
+	loaded, err := packages.Load(config, pattern)
+	if err != nil {
+		return nil, fmt.Errorf("can not load %s: %w", pattern, err)
+	}
+
+	if len(loaded) != 1 {
+		return nil, fmt.Errorf("can not load %s: pattern should match a single package", pattern)
+	}
+
+	lp := loaded[0]
+	if len(lp.GoFiles) == 0 || lp.Types == nil {
+		if len(lp.Errors) > 0 {
+			return nil, fmt.Errorf("can not load %s: %w", pattern, lp.Errors[0])
+		}
+
+		return nil, fmt.Errorf("can not load %s: no Go files", pattern)
+	}
+
+	pkg := &Package{Package: lp.Types, Dir: filepath.Dir(lp.GoFiles[0])}
+	for _, err := range lp.Errors {
+		pkg.Errors = append(pkg.Errors, err)
+	}
+
+	return pkg, nil
+}
+
+// Lookup devolve a estrutura declarada no pacote com o nome.
+func (p *Package) Lookup(name string) (*types.Named, *types.Struct, error) {
+	obj, ok := p.Scope().Lookup(name).(*types.TypeName)
+	if !ok {
+		return nil, nil, fmt.Errorf("type %s not found in %s", name, p.Path())
+	}
+
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, nil, fmt.Errorf("type %s should be a struct", name)
+	}
+
+	st, ok := named.Underlying().(*types.Struct)
+	if !ok {
+		return nil, nil, fmt.Errorf("type %s should be a struct", name)
+	}
+
+	return named, st, nil
+}
+
+// Tagged devolve os nomes das estruturas declaradas no pacote com ao menos
+// um campo com a tag "gosparse", em ordem alfabética.
+func (p *Package) Tagged() []string {
+	var tagged []string
+
+	for _, name := range p.Scope().Names() {
+		obj, ok := p.Scope().Lookup(name).(*types.TypeName)
+		if !ok || obj.IsAlias() {
+			continue
+		}
+
+		st, ok := obj.Type().Underlying().(*types.Struct)
+		if !ok {
+			continue
+		}
+
+		for i := 0; i < st.NumFields(); i++ {
+			if reflect.StructTag(st.Tag(i)).Get(gosparse.Tagname) != "" {
+				tagged = append(tagged, name)
+				break
+			}
+		}
+	}
+
+	return tagged
+}
+
+// Compile monta o Schema da estrutura.
+func Compile(st *types.Struct) (*Schema, error) {
+	s := &Schema{
+		Resource:  Resource(st, gosparse.ROOT_TYPE),
+		Aliases:   map[string]string{},
+		Fieldsets: map[string][]string{},
+		Always:    map[string][]string{},
+		Defaults:  map[string][]string{},
+	}
+
+	fields, err := walkTags(st, map[*types.Struct]struct{}{st: {}})
+	if err != nil {
+		return nil, err
+	}
+
+	s.Fields = fields
+	s.Paths = make([]string, 0, len(fields))
+
+	for path := range fields {
+		s.Paths = append(s.Paths, path)
+	}
+
+	stdsort.Strings(s.Paths)
+
+	// o nome alternativo é registrado para o primeiro caminho em ordem
+	// alfabética, assim como em gosparse.Compile
+	for _, path := range s.Paths {
+		conf := fields[path]
+		if !conf.Relation || conf.Alias == "" {
+			continue
+		}
+
+		if rel, exists := fields[conf.Alias]; exists && rel.Relation {
+			continue
+		}
+
+		if _, exists := s.Aliases[conf.Alias]; !exists {
+			s.Aliases[conf.Alias] = path
+		}
+	}
+
+	if err := s.walkFieldsets(st, s.Resource, map[*types.Struct]struct{}{}); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Select devolve os caminhos dos campos que atendem ao filtro, em ordem
+// alfabética.
+//
+//	s.Select(func(f Field) bool { return f.Filter })
+func (s *Schema) Select(match func(Field) bool) []string {
+	paths := make([]string, 0, len(s.Paths))
+	for _, path := range s.Paths {
+		if match(s.Fields[path]) {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// Tag devolve a configuração do campo da estrutura a partir da tag
+// "gosparse", ou nil quando o campo não tem a tag ou é um campo em branco.
+func Tag(st *types.Struct, i int) *gosparse.Field {
+	tag := reflect.StructTag(st.Tag(i)).Get(gosparse.Tagname)
+	if tag == "" || tag == "-" || st.Field(i).Name() == "_" {
+		return nil
+	}
+
+	conf := gosparse.ParseTag(tag)
+	return &conf
+}
+
+// walkTags percorre os campos da estrutura e devolve a configuração de cada
+// campo com a tag, incluindo os campos das relações com o nome da relação
+// como prefixo.
+//
+// Relações para uma estrutura que já está no caminho atual são aceitas,
+// mas não são percorridas novamente.
+func walkTags(st *types.Struct, visiting map[*types.Struct]struct{}) (map[string]Field, error) {
+	fields := map[string]Field{}
+
+	for i := 0; i < st.NumFields(); i++ {
+		conf := Tag(st, i)
+		if conf == nil {
+			continue
+		}
+
+		v := st.Field(i)
+		fields[conf.Name] = Field{Field: *conf, Var: v, Names: []string{v.Name()}}
+
+		if !conf.Relation {
+			continue
+		}
+
+		rel, err := Relation(v)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, cycle := visiting[rel]; cycle {
+			continue
+		}
+
+		visiting[rel] = struct{}{}
+		res, err := walkTags(rel, visiting)
+		delete(visiting, rel)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for name, field := range res {
+			field.Names = append([]string{v.Name()}, field.Names...)
+			fields[conf.Name+"."+name] = field
+		}
+	}
+
+	return fields, nil
+}
+
+// walkFieldsets registra os atributos aceitos no parâmetro "fields[TYPE]"
+// para o tipo de recurso typ e para cada uma das relações.
+func (s *Schema) walkFieldsets(st *types.Struct, typ string, visited map[*types.Struct]struct{}) error {
+	visited[st] = struct{}{}
+	s.addAttributes(st, typ)
+
+	for i := 0; i < st.NumFields(); i++ {
+		conf := Tag(st, i)
+		if conf == nil || !conf.Relation {
+			continue
+		}
+
+		rel, err := Relation(st.Field(i))
+		if err != nil {
+			return err
+		}
+
+		relTypename := RelationResource(*conf, rel)
+
+		if _, seen := visited[rel]; seen {
+			s.addAttributes(rel, relTypename)
+			continue
+		}
+
+		if err := s.walkFieldsets(rel, relTypename, visited); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addAttributes registra os atributos selecionáveis da estrutura para o
+// tipo de recurso, sem repetições.
+func (s *Schema) addAttributes(st *types.Struct, typ string) {
+	if _, exists := s.Fieldsets[typ]; !exists {
+		s.Fieldsets[typ] = []string{}
+	}
+
+	for i := 0; i < st.NumFields(); i++ {
+		conf := Tag(st, i)
+		if conf == nil || !conf.Select {
+			continue
+		}
+
+		s.Fieldsets[typ] = appendUnique(s.Fieldsets[typ], conf.Name)
+
+		if conf.Always {
+			s.Always[typ] = appendUnique(s.Always[typ], conf.Name)
+		}
+
+		if conf.Default {
+			s.Defaults[typ] = appendUnique(s.Defaults[typ], conf.Name)
+		}
+	}
+}
+
+// Resource devolve o tipo de recurso definido pela configuração "type" da
+// tag de um campo em branco (_) da estrutura, ou fallback.
+func Resource(st *types.Struct, fallback string) string {
+	for i := 0; i < st.NumFields(); i++ {
+		if st.Field(i).Name() != "_" {
+			continue
+		}
+
+		tag := reflect.StructTag(st.Tag(i)).Get(gosparse.Tagname)
+		if conf := gosparse.ParseTag(tag); conf.Resource != "" {
+			return conf.Resource
+		}
+	}
+
+	return fallback
+}
+
+// RelationResource devolve o tipo de recurso da relação: a configuração
+// "type" da tag da relação, o tipo definido pela estrutura relacionada ou,
+// por fim, o nome da relação.
+func RelationResource(conf gosparse.Field, rel *types.Struct) string {
+	if conf.Resource != "" {
+		return conf.Resource
+	}
+
+	return Resource(rel, conf.Name)
+}
+
+// Relation devolve a estrutura relacionada pelo campo, que pode ser uma
+// estrutura, uma referência ou uma lista (slice / array) delas.
+func Relation(field *types.Var) (*types.Struct, error) {
+	st, ok := Elem(field.Type()).Underlying().(*types.Struct)
+	if !ok {
+		return nil, fmt.Errorf("relation %s should be a struct", field.Name())
+	}
+
+	return st, nil
+}
+
+// Elem devolve o tipo do item: referências são percorridas e listas
+// (slices / arrays) devolvem o tipo dos itens.
+//
+//	[]*Comment // Comment
+func Elem(t types.Type) types.Type {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+
+	switch list := t.(type) {
+	case *types.Slice:
+		t = list.Elem()
+	case *types.Array:
+		t = list.Elem()
+	}
+
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+
+	return t
+}
+
+// Kind devolve a classificação do tipo do campo da mesma forma que
+// filter.KindOf, a partir do tipo verificado (go/types).
+//
+// Referências e listas (slices / arrays) são classificadas pelo tipo dos
+// itens, uma vez que cada valor de um filtro é um item.
+func Kind(t types.Type) filter.Kind {
+	t = Elem(t)
+
+	if named, ok := t.(*types.Named); ok {
+		obj := named.Obj()
+		if obj.Pkg() != nil && obj.Pkg().Path() == "time" && obj.Name() == "Time" {
+			return filter.DATE_TIME
+		}
+	}
+
+	if types.NewMethodSet(types.NewPointer(t)).Lookup(nil, "UnmarshalText") != nil {
+		return filter.STRING
+	}
+
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		return filter.STRING
+	}
+
+	switch info := basic.Info(); {
+	case info&types.IsInteger != 0:
+		return filter.INTEGER
+	case info&types.IsFloat != 0:
+		return filter.NUMBER
+	case info&types.IsBoolean != 0:
+		return filter.BOOLEAN
+	}
+
+	return filter.STRING
+}
+
+// appendUnique adiciona o valor à lista caso ainda não esteja presente.
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+
+	return append(values, value)
+}
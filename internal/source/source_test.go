@@ -0,0 +1,116 @@
+package source
+
+import (
+	"go/types"
+	"testing"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/source/testdata/models"
+	"github.com/stretchr/testify/require"
+)
+
+const pattern = "./testdata/models"
+
+func TestLoad(t *testing.T) {
+	pkg, err := Load(".", pattern)
+	require.Nil(t, err)
+	require.Empty(t, pkg.Errors)
+	require.Equal(t, []string{"Article", "Author", "Comment"}, pkg.Tagged())
+
+	_, err = Load(".", "./testdata/missing")
+	require.NotNil(t, err)
+}
+
+func TestLookup(t *testing.T) {
+	pkg, err := Load(".", pattern)
+	require.Nil(t, err)
+
+	testtable := []struct {
+		desc string
+		name string
+		err  string
+	}{
+		{desc: "should find structs", name: "Article"},
+		{desc: "should reject unknown types", name: "Missing", err: "type Missing not found in " + pkg.Path()},
+		{desc: "should reject non struct types", name: "Status", err: "type Status should be a struct"},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			named, _, err := pkg.Lookup(tc.name)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.name, named.Obj().Name())
+		})
+	}
+}
+
+// TestCompile garante que o Schema montado a partir do código fonte é o
+// mesmo montado por gosparse.Compile a partir da estrutura compilada.
+func TestCompile(t *testing.T) {
+	pkg, err := Load(".", pattern)
+	require.Nil(t, err)
+
+	_, st, err := pkg.Lookup("Article")
+	require.Nil(t, err)
+
+	schema, err := Compile(st)
+	require.Nil(t, err)
+
+	expect, err := gosparse.Compile(models.Article{})
+	require.Nil(t, err)
+
+	require.Equal(t, expect.Resource, schema.Resource)
+	require.Len(t, schema.Fields, len(expect.FieldNames()))
+
+	for path, field := range schema.Fields {
+		// o tipo e o índice só existem na estrutura compilada
+		want, exists := expect.Field(path)
+		require.True(t, exists, path)
+		want.Type, want.Index = nil, nil
+
+		require.Equal(t, want, field.Field, path)
+	}
+
+	require.Equal(t, []string{"author", "author.articles", "author.name", "comments", "comments.author", "comments.author.articles", "comments.author.name", "comments.body", "id", "title"}, schema.Paths)
+	require.Equal(t, []string{"Comments", "Author", "Name"}, schema.Fields["comments.author.name"].Names)
+	require.Equal(t, map[string]string{"writer": "author"}, schema.Aliases)
+	require.Equal(t, map[string][]string{
+		"articles": {"id", "title", "author", "comments"},
+		"people":   {"name", "articles"},
+		"comments": {"body", "author"},
+	}, schema.Fieldsets)
+	require.Equal(t, map[string][]string{"articles": {"title"}}, schema.Defaults)
+	require.Equal(t, []string{"author.name", "comments.author.name", "title"}, schema.Select(func(f Field) bool { return f.Filter }))
+}
+
+func TestKind(t *testing.T) {
+	timePkg, err := Load(".", "time")
+	require.Nil(t, err)
+
+	timeType, _, err := timePkg.Lookup("Time")
+	require.Nil(t, err)
+
+	testtable := []struct {
+		desc   string
+		typ    types.Type
+		expect filter.Kind
+	}{
+		{desc: "should classify integers", typ: types.Typ[types.Uint8], expect: filter.INTEGER},
+		{desc: "should classify items of lists", typ: types.NewSlice(types.Typ[types.Float64]), expect: filter.NUMBER},
+		{desc: "should classify references", typ: types.NewPointer(types.Typ[types.Bool]), expect: filter.BOOLEAN},
+		{desc: "should classify dates", typ: types.NewPointer(timeType), expect: filter.DATE_TIME},
+		{desc: "should classify texts", typ: types.Typ[types.String], expect: filter.STRING},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			require.Equal(t, tc.expect, Kind(tc.typ))
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package models
+
+type Article struct {
+	_        struct{}   `gosparse:"type:articles"`
+	ID       string     `gosparse:"name:id;always"`
+	Title    string     `gosparse:"name:title;select;sort;filter;default"`
+	Author   *Author    `gosparse:"name:author;relation;select;alias:writer"`
+	Comments []*Comment `gosparse:"name:comments;relation;select"`
+	Internal string
+}
+
+type Author struct {
+	_        struct{}  `gosparse:"type:people"`
+	Name     string    `gosparse:"name:name;select;filter"`
+	Articles []Article `gosparse:"name:articles;relation"`
+}
+
+type Comment struct {
+	Body   string  `gosparse:"name:body;select"`
+	Author *Author `gosparse:"name:author;relation;select;alias:writer"`
+}
+
+type Status string
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/url"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -25,10 +26,12 @@ var (
 // extractField recebe a chave da querystring da
 // request e extrai o nome do campo.
 //
+// O parâmetro "fields" sem tipo devolve a chave PRIMARY.
+//
 // para um formato inválido de chave, retorna uma string vazia e um erro
 func extractField(f string) (string, error) {
 	if f == SEARCH_PARAM {
-		f = "fields[root]"
+		return PRIMARY, nil
 	}
 
 	// se não houver match em um campo
@@ -57,8 +60,36 @@ func resetValues(v []string) []string {
 	return v
 }
 
+// DecodeOpt é uma assinatura para opções de configuração do Decode
+type DecodeOpt func(*decoder)
+
+// decoder armazena os modos opcionais do Decode
+type decoder struct {
+	dotted bool
+}
+
+// DottedPaths é uma opção do Decode que habilita caminhos separados por
+// pontos no parâmetro "fields" sem tipo, como uma seleção aninhada:
+//
+//	fields=title,author.name,author.email
+//
+// Cada caminho tem seus segmentos validados e a árvore de seleção pode
+// ser obtida com Fields.Selection.
+func DottedPaths() DecodeOpt {
+	return func(d *decoder) {
+		d.dotted = true
+	}
+}
+
 // Decode recebe a query e extrai os valores de campo e valores da query.
-func Decode(query url.Values) (Fields, error) {
+func Decode(query url.Values, opts ...DecodeOpt) (Fields, error) {
+	d := decoder{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&d)
+		}
+	}
+
 	fields := Fields{}
 
 	for key, val := range query {
@@ -68,7 +99,62 @@ func Decode(query url.Values) (Fields, error) {
 		}
 
 		fields[field] = resetValues(val)
+
+		if d.dotted && field == PRIMARY {
+			if err := validatePaths(fields[field]); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return fields, nil
 }
+
+// Encode recebe os fields e devolve a query correspondente, inversa de
+// Decode. A chave PRIMARY é codificada como o parâmetro "fields" sem tipo:
+//
+//	Encode(Fields{PRIMARY: {"title"}, "people": {"name"}})
+//	// url.Values{"fields": {"title"}, "fields[people]": {"name"}}
+//
+// Os atributos são codificados em ordem alfabética, portanto a query é
+// canônica. Tipos de recurso sem atributos são codificados com o valor
+// vazio, que solicita nenhum atributo:
+//
+//	Encode(Fields{"people": {}}) // url.Values{"fields[people]": {""}}
+func Encode(fields Fields) url.Values {
+	query := make(url.Values, len(fields))
+
+	for typ, attrs := range fields {
+		key := SEARCH_PARAM
+		if typ != PRIMARY {
+			key = key + "[" + typ + "]"
+		}
+
+		sorted := append([]string(nil), attrs...)
+		sort.Strings(sorted)
+
+		query.Set(key, strings.Join(sorted, ","))
+	}
+
+	return query
+}
+
+// validatePaths checa se os caminhos separados por pontos não possuem
+// segmentos vazios.
+//
+//	validatePaths([]string{"author..name"}) // field has invalid path: author..name
+func validatePaths(paths []string) error {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+
+		for _, segment := range strings.Split(path, PATH_SEPARATOR) {
+			if segment == "" {
+				return fmt.Errorf("field has invalid path: %s", path)
+			}
+		}
+	}
+
+	return nil
+}
@@ -53,3 +53,36 @@ func TestDecode(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeDottedPaths(t *testing.T) {
+	testtable := []struct {
+		desc     string
+		query    url.Values
+		expected Selection
+		err      error
+	}{
+		{
+			desc:  "should parse dotted paths into selection tree",
+			query: url.Values{"fields": {"title,author.name,author.email"}},
+			expected: Selection{
+				"title":  {},
+				"author": {"name": {}, "email": {}},
+			},
+		},
+		{
+			desc:     "should fail with empty segment",
+			query:    url.Values{"fields": {"author..name"}},
+			expected: Selection{},
+			err:      fmt.Errorf("field has invalid path: author..name"),
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			fields, err := Decode(tt.query, DottedPaths())
+
+			require.EqualValues(t, tt.err, err, "errors does not match")
+			require.Equal(t, tt.expected, fields.Selection(PRIMARY))
+		})
+	}
+}
@@ -4,29 +4,118 @@ import (
 	"context"
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
+
+	"github.com/jeanmolossi/gosparse/internal/authorization"
 )
 
-// Fieldset é um map de structs vazias
+// Resources é um map de tipos de recurso para os atributos aceitos
 //
 // maps tem mais performance para busca por chaves
 //
 // Isso vai armazenar os valores aceitos para o parâmetro
-// de busca "fields" no seguinte formato:
+// de busca "fields[TYPE]" no seguinte formato:
 //
-//	map[string]struct{}{
+//	map[string]Attributes{
 //		// ...
-//		"comments": {},
-//		"author": {},
-//		"posts": {},
+//		"articles": {"title": {}, "body": {}},
+//		"people": {"name": {}},
+//		"comments": nil, // aceita qualquer atributo
 //		// ...
 //	}
-type Fieldset map[string]struct{}
+type Resources map[string]Attributes
+
+// Fieldset armazena a configuração do parâmetro de busca "fields".
+//
+// O valor zero de Fieldset não aceita nenhum tipo de recurso e pode ser
+// configurado diretamente com os métodos Add*.
+type Fieldset struct {
+	// Resources são os tipos de recurso aceitos e seus atributos
+	Resources Resources
+	// Primary é o tipo de recurso dos dados primários.
+	//
+	// O parâmetro "fields" sem tipo é tratado como "fields[Primary]":
+	//
+	//	// Primary: "articles"
+	//	fields=title            // fields[articles]=title
+	Primary string
+	// Nested habilita caminhos separados por pontos no parâmetro "fields"
+	// sem tipo (veja DottedPaths), validados contra Paths.
+	Nested bool
+	// Paths são os caminhos aceitos a partir do tipo de recurso primário
+	// quando Nested está habilitado:
+	//
+	//	map[string]struct{}{
+	//		"title": {},
+	//		"author": {},
+	//		"author.name": {},
+	//	}
+	Paths Attributes
+	// Always são os atributos de cada tipo de recurso que sempre serão
+	// retornados, mesmo que não sejam solicitados:
+	//
+	//	// Always: {"articles": {"id": {}}}
+	//	fields[articles]=title // []string{"title", "id"}
+	Always Resources
+	// Defaults são os atributos de cada tipo de recurso retornados quando
+	// o tipo não é solicitado no parâmetro "fields".
+	//
+	// Caso o tipo de recurso não tenha Defaults, todos os atributos aceitos
+	// serão retornados.
+	Defaults Resources
+	// Guard consulta a autorização de cada atributo selecionado
+	Guard authorization.Guard
+
+	frozen bool
+}
+
+// Attributes é um map de structs vazias com os atributos aceitos
+// para um tipo de recurso.
+//
+// Um Attributes nil indica que o tipo de recurso não tem restrição
+// de atributos, ou seja, qualquer atributo é aceito.
+type Attributes map[string]struct{}
 
 // Fields é o map que irá salvar a solicitação de
 // campo com predicado e valores
 type Fields map[string][]string
 
+// Selection é uma árvore de campos selecionados a partir de caminhos
+// separados por pontos:
+//
+//	// fields=title,author.name,author.email
+//	map[string]Selection{
+//		"title": {},
+//		"author": {
+//			"name": {},
+//			"email": {},
+//		},
+//	}
+type Selection map[string]Selection
+
+// Selection devolve a árvore de seleção dos campos do tipo de recurso.
+func (f Fields) Selection(typ string) Selection {
+	selection := make(Selection)
+
+	for _, path := range f[typ] {
+		if path == "" {
+			continue
+		}
+
+		node := selection
+		for _, segment := range strings.Split(path, PATH_SEPARATOR) {
+			if _, exists := node[segment]; !exists {
+				node[segment] = make(Selection)
+			}
+
+			node = node[segment]
+		}
+	}
+
+	return selection
+}
+
 // FieldsetOpt é uma assinatura para opções de configuração
 // para o construtor de Fieldset
 type FieldsetOpt func(*Fieldset)
@@ -35,6 +124,23 @@ type CtxKey struct{}
 
 const (
 	SEARCH_PARAM string = "fields"
+
+	// PRIMARY é a chave utilizada por Decode para o parâmetro "fields"
+	// sem tipo, que se refere ao tipo de recurso dos dados primários.
+	PRIMARY string = ""
+
+	// ROOT é o tipo de recurso do parâmetro "fields" sem tipo quando o
+	// tipo primário (Primary) não é definido. ROOT aceita qualquer
+	// atributo:
+	//
+	//	// Primary: ""
+	//	fields=title // fields[root]=title
+	ROOT string = "root"
+
+	// PATH_SEPARATOR separa os segmentos de um caminho aninhado
+	//
+	//	author.name
+	PATH_SEPARATOR string = "."
 )
 
 // extractFieldFromQuery recebe a query e devolve um novo
@@ -60,35 +166,290 @@ func extractFieldFromQuery(query url.Values) url.Values {
 // Caso haja algum valor de "fields" que não está definido como "AcceptField"
 // será retornado um erro de recurso de campo não suportado, uma vez que o
 // parâmetro "fields" só deve ser recebido com valores aceitos ou não deve ser utilizado.
+//
+// Para os tipos de recurso com atributos definidos (AcceptType) cada
+// atributo solicitado também é validado:
+//
+//	// fields[articles]=title,bogus
+//	// unsupported field bogus on resource articles
 func (f Fieldset) Handle(ctx context.Context, query url.Values) (context.Context, error) {
 	query = extractFieldFromQuery(query)
 	if len(query) == 0 {
 		return ctx, nil
 	}
 
-	fields, err := Decode(query)
+	opts := []DecodeOpt{}
+	if f.Nested {
+		opts = append(opts, DottedPaths())
+	}
+
+	fields, err := Decode(query, opts...)
 	if err != nil {
 		return ctx, err
 	}
 
-	for field := range fields {
-		if _, exists := f[field]; !exists {
-			return ctx, fmt.Errorf("unsupported field resource: %s", field)
+	fields, err = f.resolvePrimary(fields)
+	if err != nil {
+		return ctx, err
+	}
+
+	// com a seleção aninhada os atributos do tipo primário, enviados com
+	// ou sem tipo, são validados contra os caminhos aceitos
+	if f.Nested {
+		if err := f.validatePaths(fields[f.primary()]); err != nil {
+			return ctx, err
+		}
+	}
+
+	for typ, attrs := range fields {
+		if f.Nested && typ == f.primary() {
+			continue
+		}
+
+		accepted, exists := f.Resources[typ]
+		if !exists && typ != f.primary() {
+			return ctx, fmt.Errorf("unsupported field resource: %s", typ)
+		}
+
+		if err := accepted.validate(typ, attrs); err != nil {
+			return ctx, err
 		}
 	}
 
+	for typ, attrs := range fields {
+		attrs, err := f.authorize(ctx, typ, attrs)
+		if err != nil {
+			return ctx, err
+		}
+
+		fields[typ] = merge(attrs, f.Always[typ])
+	}
+
 	return context.WithValue(ctx, CtxKey{}, fields), nil
 }
 
+// resolvePrimary recebe os fields decodificados e trata o parâmetro
+// "fields" sem tipo como o tipo de recurso primário (Primary), ou ROOT
+// quando o tipo primário não é definido.
+//
+// Caso "fields" e "fields[Primary]" sejam enviados juntos, os atributos
+// serão mesclados.
+func (f Fieldset) resolvePrimary(fields Fields) (Fields, error) {
+	attrs, exists := fields[PRIMARY]
+	if !exists {
+		return fields, nil
+	}
+
+	primary := f.primary()
+
+	delete(fields, PRIMARY)
+	fields[primary] = append(fields[primary], attrs...)
+
+	return fields, nil
+}
+
+// primary devolve o tipo de recurso primário ou ROOT quando não definido.
+func (f Fieldset) primary() string {
+	if f.Primary == "" {
+		return ROOT
+	}
+
+	return f.Primary
+}
+
+// validatePaths checa cada segmento dos caminhos aninhados contra os
+// caminhos aceitos (Paths).
+//
+//	// fields=author.bogus
+//	// unsupported field bogus on path author.bogus
+func (f Fieldset) validatePaths(paths []string) error {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+
+		segments := strings.Split(path, PATH_SEPARATOR)
+		for i := range segments {
+			prefix := strings.Join(segments[:i+1], PATH_SEPARATOR)
+
+			if _, exists := f.Paths[prefix]; !exists {
+				return fmt.Errorf("unsupported field %s on path %s", segments[i], path)
+			}
+		}
+	}
+
+	return nil
+}
+
+// authorize consulta o Guard para cada atributo solicitado do tipo de
+// recurso e devolve somente os atributos permitidos.
+func (f Fieldset) authorize(ctx context.Context, typ string, attrs []string) ([]string, error) {
+	allowed := make([]string, 0, len(attrs))
+
+	for _, attr := range attrs {
+		if attr == "" {
+			continue
+		}
+
+		ok, err := f.Guard.Check(ctx, authorization.SELECT, typ, attr)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			allowed = append(allowed, attr)
+		}
+	}
+
+	return allowed, nil
+}
+
+// merge recebe os atributos solicitados e adiciona os atributos
+// obrigatórios que ainda não foram solicitados, em ordem alfabética.
+//
+// Valores vazios (nenhum campo solicitado) são descartados.
+func merge(attrs []string, mandatory Attributes) []string {
+	merged := make([]string, 0, len(attrs)+len(mandatory))
+	seen := make(map[string]struct{}, len(attrs)+len(mandatory))
+
+	for _, attr := range attrs {
+		if _, duplicate := seen[attr]; duplicate || attr == "" {
+			continue
+		}
+
+		seen[attr] = struct{}{}
+		merged = append(merged, attr)
+	}
+
+	for _, attr := range mandatory.sorted() {
+		if _, duplicate := seen[attr]; !duplicate {
+			merged = append(merged, attr)
+		}
+	}
+
+	return merged
+}
+
+// sorted devolve os atributos em ordem alfabética
+func (a Attributes) sorted() []string {
+	attrs := make([]string, 0, len(a))
+	for attr := range a {
+		attrs = append(attrs, attr)
+	}
+
+	sort.Strings(attrs)
+	return attrs
+}
+
+// validate recebe o tipo de recurso e os atributos solicitados e checa
+// se todos são aceitos.
+//
+// Um valor vazio indica que nenhum campo deve ser retornado, portanto
+// atributos vazios são ignorados.
+func (a Attributes) validate(typ string, attrs []string) error {
+	if a == nil {
+		return nil
+	}
+
+	for _, attr := range attrs {
+		if attr == "" {
+			continue
+		}
+
+		if _, exists := a[attr]; !exists {
+			return fmt.Errorf("unsupported field %s on resource %s", attr, typ)
+		}
+	}
+
+	return nil
+}
+
 // Get recebe o contexto e a chave do campo de "fields" já validado e tratado.
 //
-// Caso o contexto não tenha o valor do campo será retornado um slice vazio.
+// A chave PRIMARY se refere ao tipo de recurso primário (Primary).
+//
+// Os atributos obrigatórios (Always) sempre são retornados. Caso o
+// contexto não tenha o valor do campo será retornada a seleção padrão
+// do tipo de recurso (veja Default).
 func (f Fieldset) Get(ctx context.Context, field string) []string {
+	if field == PRIMARY {
+		field = f.primary()
+	}
+
 	if values, ok := ctx.Value(CtxKey{}).(Fields); ok {
-		return values[field]
+		if attrs, requested := values[field]; requested {
+			return attrs
+		}
+	}
+
+	// a seleção padrão não foi solicitada pelo cliente, portanto
+	// os atributos negados são sempre removidos silenciosamente
+	guard := f.Guard
+	guard.Policy = authorization.STRIP
+
+	defaults, _ := Fieldset{Guard: guard}.authorize(ctx, field, f.defaults(field))
+	return merge(defaults, f.Always[field])
+}
+
+// Default devolve a seleção padrão do tipo de recurso, utilizada quando
+// o tipo não é solicitado no parâmetro "fields".
+//
+// A seleção padrão são os atributos Defaults do tipo ou, caso não existam,
+// todos os atributos aceitos, somados aos atributos obrigatórios (Always).
+//
+// Para tipos de recurso desconhecidos ou sem restrição de atributos
+// somente os atributos obrigatórios são retornados.
+func (f Fieldset) Default(typ string) []string {
+	return merge(f.defaults(typ), f.Always[typ])
+}
+
+// defaults devolve os atributos Defaults do tipo de recurso ou, caso não
+// existam, todos os atributos aceitos, em ordem alfabética.
+func (f Fieldset) defaults(typ string) []string {
+	defaults := f.Defaults[typ]
+	if len(defaults) == 0 {
+		defaults = f.Resources[typ]
 	}
 
-	return make([]string, 0)
+	return defaults.sorted()
+}
+
+// GetSelection recebe o contexto e devolve a árvore de seleção do tipo de
+// recurso primário.
+//
+// Caso o contexto não tenha os fields será devolvida uma árvore vazia.
+func (f Fieldset) GetSelection(ctx context.Context) Selection {
+	return f.GetAll(ctx).Selection(f.primary())
+}
+
+// Implied recebe o contexto e devolve os caminhos de relacionamento
+// implícitos na seleção aninhada, que devem ser incluídos ("include")
+// para que os campos selecionados possam ser retornados.
+//
+//	// fields=title,author.name,comments.author.name
+//	f.Implied(ctx) // []string{"author", "comments.author"}
+//
+// Caso Nested não esteja habilitado será devolvido um slice vazio.
+func (f Fieldset) Implied(ctx context.Context) []string {
+	implied := make([]string, 0)
+	if !f.Nested {
+		return implied
+	}
+
+	seen := make(map[string]struct{})
+	for _, path := range f.GetAll(ctx)[f.primary()] {
+		i := strings.LastIndex(path, PATH_SEPARATOR)
+		if i < 0 {
+			continue
+		}
+
+		if _, duplicate := seen[path[:i]]; !duplicate {
+			seen[path[:i]] = struct{}{}
+			implied = append(implied, path[:i])
+		}
+	}
+
+	return implied
 }
 
 // GetAll recebe o contexto e devolve os fields contidos.
@@ -104,15 +465,66 @@ func (f Fieldset) GetAll(ctx context.Context) Fields {
 
 // AddField recebe a chave do campo aceito no parâmetro "fields".
 //
+// O tipo de recurso adicionado por AddField aceita qualquer atributo,
+// para restringir os atributos utilize AddAttributes.
+//
 // Caso a chave recebida já esteja na lista de campos suportados, ela
 // será ignorada.
-func (f Fieldset) AddField(field string) {
-	if f == nil {
-		f = make(Fieldset)
+func (f *Fieldset) AddField(field string) {
+	f.mutable()
+
+	if f.Resources == nil {
+		f.Resources = make(Resources)
+	}
+
+	if _, duplicate := f.Resources[field]; !duplicate {
+		f.Resources[field] = nil
 	}
+}
+
+// AddAttributes recebe o tipo de recurso e os atributos aceitos para ele
+// no parâmetro "fields[TYPE]".
+//
+// Ao chamar AddAttributes o tipo de recurso passa a aceitar somente os
+// atributos adicionados, mesmo que nenhum atributo seja informado.
+//
+// Caso o atributo recebido já esteja na lista de atributos suportados,
+// ele será ignorado.
+func (f *Fieldset) AddAttributes(typ string, attrs ...string) {
+	f.mutable()
+
+	if f.Resources == nil {
+		f.Resources = make(Resources)
+	}
+
+	f.Resources.add(typ, attrs...)
+}
+
+// Freeze congela os tipos de recurso, atributos e caminhos aceitos no
+// parâmetro "fields". Após congelado, AddField, AddAttributes, AddPath,
+// AddAlways e AddDefaults entram em pânico.
+//
+// Resources, Paths, Always e Defaults são copiados, portanto não são
+// compartilhados com quem montou a configuração. Os campos continuam
+// exportados somente para leitura e não devem ser alterados diretamente
+// após o congelamento.
+func (f *Fieldset) Freeze() {
+	f.Resources = f.Resources.clone()
+	f.Paths = f.Paths.clone()
+	f.Always = f.Always.clone()
+	f.Defaults = f.Defaults.clone()
+	f.frozen = true
+}
 
-	if _, duplicate := f[field]; !duplicate {
-		f[field] = struct{}{}
+// Frozen indica se a configuração foi congelada (veja Freeze).
+func (f Fieldset) Frozen() bool {
+	return f.frozen
+}
+
+// mutable entra em pânico caso a configuração esteja congelada.
+func (f Fieldset) mutable() {
+	if f.frozen {
+		panic("sparsefieldsets: can not change a frozen configuration")
 	}
 }
 
@@ -132,10 +544,163 @@ func AcceptField(fields ...string) FieldsetOpt {
 	}
 }
 
+// AcceptType é uma opção do construtor de *Fieldset. Essa função recebe o
+// tipo de recurso e os atributos aceitos para ele ao validar a query da request
+//
+//	sparsefieldsets.New(sparsefieldsets.AcceptType("articles", "title", "body"))
+func AcceptType(typ string, attrs ...string) FieldsetOpt {
+	return func(f *Fieldset) {
+		f.AddAttributes(typ, attrs...)
+	}
+}
+
+// AddPath recebe um caminho aceito a partir do tipo de recurso primário
+// para a seleção aninhada.
+//
+//	f.AddPath("author.name")
+func (f *Fieldset) AddPath(path string) {
+	f.mutable()
+
+	if f.Paths == nil {
+		f.Paths = make(Attributes)
+	}
+
+	f.Paths[path] = struct{}{}
+}
+
+// Authorize é uma opção do construtor de *Fieldset. Essa função recebe o
+// Authorizer consultado para cada atributo solicitado e a política aplicada
+// quando o atributo é negado.
+//
+// Os atributos obrigatórios (Always) não são consultados.
+func Authorize(authorizer authorization.Authorizer, policy authorization.Policy) FieldsetOpt {
+	return func(f *Fieldset) {
+		f.Guard = authorization.Guard{Authorizer: authorizer, Policy: policy}
+	}
+}
+
+// AddAlways recebe o tipo de recurso e os atributos que sempre serão
+// retornados para ele.
+func (f *Fieldset) AddAlways(typ string, attrs ...string) {
+	f.mutable()
+
+	if f.Always == nil {
+		f.Always = make(Resources)
+	}
+
+	f.Always.add(typ, attrs...)
+}
+
+// AddDefaults recebe o tipo de recurso e os atributos retornados quando o
+// tipo não for solicitado no parâmetro "fields".
+func (f *Fieldset) AddDefaults(typ string, attrs ...string) {
+	f.mutable()
+
+	if f.Defaults == nil {
+		f.Defaults = make(Resources)
+	}
+
+	f.Defaults.add(typ, attrs...)
+}
+
+// add recebe o tipo de recurso e adiciona os atributos a ele
+func (r Resources) add(typ string, attrs ...string) {
+	if r[typ] == nil {
+		r[typ] = make(Attributes, len(attrs))
+	}
+
+	for _, attr := range attrs {
+		r[typ][attr] = struct{}{}
+	}
+}
+
+// clone devolve uma cópia dos tipos de recurso e de seus atributos.
+func (r Resources) clone() Resources {
+	if r == nil {
+		return nil
+	}
+
+	copied := make(Resources, len(r))
+	for typ, attrs := range r {
+		copied[typ] = attrs.clone()
+	}
+
+	return copied
+}
+
+// clone devolve uma cópia dos atributos.
+func (a Attributes) clone() Attributes {
+	if a == nil {
+		return nil
+	}
+
+	copied := make(Attributes, len(a))
+	for attr := range a {
+		copied[attr] = struct{}{}
+	}
+
+	return copied
+}
+
+// Always é uma opção do construtor de *Fieldset. Essa função recebe o tipo
+// de recurso e os atributos que sempre serão retornados, mesmo quando o
+// cliente solicita um conjunto restrito de campos.
+//
+//	sparsefieldsets.New(sparsefieldsets.Always("articles", "id"))
+//	// fields[articles]=title // []string{"title", "id"}
+func Always(typ string, attrs ...string) FieldsetOpt {
+	return func(f *Fieldset) {
+		f.AddAlways(typ, attrs...)
+	}
+}
+
+// Defaults é uma opção do construtor de *Fieldset. Essa função recebe o
+// tipo de recurso e os atributos retornados quando o cliente não solicita
+// o tipo no parâmetro "fields".
+func Defaults(typ string, attrs ...string) FieldsetOpt {
+	return func(f *Fieldset) {
+		f.AddDefaults(typ, attrs...)
+	}
+}
+
+// NestedPaths é uma opção do construtor de *Fieldset. Essa função habilita
+// a seleção aninhada com caminhos separados por pontos no parâmetro "fields"
+// sem tipo e recebe os caminhos aceitos.
+//
+//	sparsefieldsets.New(
+//		sparsefieldsets.PrimaryType("articles"),
+//		sparsefieldsets.NestedPaths("title", "author", "author.name"),
+//	)
+func NestedPaths(paths ...string) FieldsetOpt {
+	return func(f *Fieldset) {
+		f.Nested = true
+
+		for _, path := range paths {
+			f.AddPath(path)
+		}
+	}
+}
+
+// PrimaryType é uma opção do construtor de *Fieldset. Essa função recebe o
+// tipo de recurso dos dados primários, que também será aceito no parâmetro
+// "fields" sem tipo.
+//
+//	sparsefieldsets.New(sparsefieldsets.PrimaryType("articles"))
+//	// fields=title é o mesmo que fields[articles]=title
+func PrimaryType(typ string) FieldsetOpt {
+	return func(f *Fieldset) {
+		f.Primary = typ
+
+		if _, exists := f.Resources[typ]; !exists {
+			f.AddField(typ)
+		}
+	}
+}
+
 // Constructor -----------------
 
 func New(opt ...FieldsetOpt) *Fieldset {
-	fields := &Fieldset{}
+	fields := &Fieldset{Resources: make(Resources)}
 
 	for _, o := range opt {
 		if o == nil {
@@ -145,7 +710,5 @@ func New(opt ...FieldsetOpt) *Fieldset {
 		o(fields)
 	}
 
-	AcceptField("root")(fields)
-
 	return fields
 }
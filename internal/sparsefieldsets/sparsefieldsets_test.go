@@ -59,3 +59,183 @@ func TestFieldset(t *testing.T) {
 		})
 	}
 }
+
+func TestAcceptType(t *testing.T) {
+	testtable := []struct {
+		desc  string
+		query url.Values
+		err   error
+	}{
+		{
+			desc:  "should accept known attributes",
+			query: url.Values{"fields[articles]": {"title,body"}},
+		},
+		{
+			desc:  "should fail naming unknown attribute",
+			query: url.Values{"fields[articles]": {"title,bogus"}},
+			err:   fmt.Errorf("unsupported field bogus on resource articles"),
+		},
+		{
+			desc:  "should fail if type has no attributes",
+			query: url.Values{"fields[people]": {"name"}},
+			err:   fmt.Errorf("unsupported field name on resource people"),
+		},
+		{
+			desc:  "should accept any attribute on type without restriction",
+			query: url.Values{"fields[comments]": {"anything"}},
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			fieldset := New(
+				AcceptType("articles", "title", "body"),
+				AcceptType("people"),
+				AcceptField("comments"),
+			)
+
+			_, err := fieldset.Handle(context.Background(), tt.query)
+			require.EqualValues(t, tt.err, err)
+		})
+	}
+}
+
+func TestPrimaryType(t *testing.T) {
+	t.Run("should map bare fields to root without primary type", func(t *testing.T) {
+		fieldset := New(AcceptType("articles", "title"))
+
+		ctx, err := fieldset.Handle(context.Background(), url.Values{"fields": {"title"}})
+		require.Nil(t, err)
+		require.Equal(t, []string{"title"}, fieldset.Get(ctx, ROOT))
+		require.Equal(t, []string{"title"}, fieldset.Get(ctx, PRIMARY))
+	})
+
+	t.Run("should validate bare fields against primary type", func(t *testing.T) {
+		fieldset := New(AcceptType("articles", "title"), PrimaryType("articles"))
+
+		_, err := fieldset.Handle(context.Background(), url.Values{"fields": {"body"}})
+		require.EqualError(t, err, "unsupported field body on resource articles")
+	})
+}
+
+func TestNestedPaths(t *testing.T) {
+	fieldset := New(
+		AcceptType("people", "name"),
+		PrimaryType("articles"),
+		NestedPaths("title", "author", "author.name", "comments", "comments.author", "comments.author.name"),
+	)
+
+	testtable := []struct {
+		desc    string
+		query   url.Values
+		implied []string
+		err     error
+	}{
+		{
+			desc:    "should imply relationship includes",
+			query:   url.Values{"fields": {"title,author.name,comments.author.name"}},
+			implied: []string{"author", "comments.author"},
+		},
+		{
+			desc:    "should validate typed fields as usual",
+			query:   url.Values{"fields[people]": {"name"}},
+			implied: []string{},
+		},
+		{
+			desc:    "should fail naming unknown segment",
+			query:   url.Values{"fields": {"comments.bogus.name"}},
+			implied: []string{},
+			err:     fmt.Errorf("unsupported field bogus on path comments.bogus.name"),
+		},
+		{
+			desc:    "should validate typed primary fields against the paths",
+			query:   url.Values{"fields[articles]": {"bogus"}},
+			implied: []string{},
+			err:     fmt.Errorf("unsupported field bogus on path bogus"),
+		},
+		{
+			desc:    "should accept typed primary fields on the paths",
+			query:   url.Values{"fields[articles]": {"title"}},
+			implied: []string{},
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctx, err := fieldset.Handle(context.Background(), tt.query)
+			require.EqualValues(t, tt.err, err)
+			require.Equal(t, tt.implied, fieldset.Implied(ctx))
+		})
+	}
+}
+
+func TestAlwaysAndDefaults(t *testing.T) {
+	fieldset := New(
+		AcceptType("articles", "id", "title", "body", "updated_at"),
+		AcceptType("people", "name", "email"),
+		PrimaryType("articles"),
+		Always("articles", "updated_at", "id"),
+		Defaults("articles", "title"),
+	)
+
+	testtable := []struct {
+		desc   string
+		query  url.Values
+		typ    string
+		expect []string
+	}{
+		{
+			desc:   "should add mandatory fields to requested fields",
+			query:  url.Values{"fields[articles]": {"title"}},
+			typ:    "articles",
+			expect: []string{"title", "id", "updated_at"},
+		},
+		{
+			desc:   "should not repeat requested mandatory fields",
+			query:  url.Values{"fields": {"id,body"}},
+			typ:    "articles",
+			expect: []string{"id", "body", "updated_at"},
+		},
+		{
+			desc:   "should return only mandatory fields on empty fields",
+			query:  url.Values{"fields[articles]": {""}},
+			typ:    "articles",
+			expect: []string{"id", "updated_at"},
+		},
+		{
+			desc:   "should return default selection when not requested",
+			query:  url.Values{},
+			typ:    "articles",
+			expect: []string{"title", "id", "updated_at"},
+		},
+		{
+			desc:   "should return all attributes without defaults",
+			query:  url.Values{"fields[articles]": {"title"}},
+			typ:    "people",
+			expect: []string{"email", "name"},
+		},
+	}
+
+	for _, tt := range testtable {
+		t.Run(tt.desc, func(t *testing.T) {
+			ctx, err := fieldset.Handle(context.Background(), tt.query)
+			require.Nil(t, err)
+			require.Equal(t, tt.expect, fieldset.Get(ctx, tt.typ))
+		})
+	}
+}
+
+func TestZeroValue(t *testing.T) {
+	var fieldset Fieldset
+	fieldset.AddField("tags")
+	fieldset.AddAttributes("articles", "title")
+	fieldset.AddAlways("articles", "id")
+
+	ctx, err := fieldset.Handle(context.Background(), url.Values{
+		"fields[articles]": {"title"},
+		"fields[tags]":     {"label"},
+	})
+	require.Nil(t, err)
+	require.Equal(t, []string{"title", "id"}, fieldset.Get(ctx, "articles"))
+	require.Equal(t, []string{"label"}, fieldset.Get(ctx, "tags"))
+}
@@ -0,0 +1,31 @@
+// Package openapi
+//
+// Gera os objetos de parâmetro do OpenAPI 3 a partir de um Schema compilado
+// (gosparse.Schema), evitando que a documentação da API divirja dos
+// parâmetros aceitos pelo código:
+//
+//	generator := openapi.New(gosparse.MustCompile[Article]())
+//
+//	spec, err := generator.YAML()
+//	// components:
+//	//   parameters:
+//	//     include: ...
+//	//     fields: ...
+//	//     filter: ...
+//	//     sort: ...
+//	//     page: ...
+//
+// Os parâmetros "fields", "filter" e "page" são descritos com
+// "style: deepObject", enquanto "include" e "sort" são listas separadas por
+// vírgula ("style: form" e "explode: false"). O documento gerado pode ser
+// mesclado em uma especificação existente e referenciado nas operações:
+//
+//	parameters:
+//	  - $ref: '#/components/parameters/include'
+//	  - $ref: '#/components/parameters/filter'
+//
+// # References
+//
+//   - https://spec.openapis.org/oas/v3.0.3#parameter-object
+//   - https://spec.openapis.org/oas/v3.0.3#style-values
+package openapi
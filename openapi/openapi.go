@@ -0,0 +1,319 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	stdsort "sort"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/include"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
+	"gopkg.in/yaml.v3"
+)
+
+// Parameter é um objeto de parâmetro do OpenAPI 3
+type Parameter struct {
+	Name        string  `json:"name" yaml:"name"`
+	In          string  `json:"in" yaml:"in"`
+	Description string  `json:"description,omitempty" yaml:"description,omitempty"`
+	Style       string  `json:"style" yaml:"style"`
+	Explode     bool    `json:"explode" yaml:"explode"`
+	Schema      *Schema `json:"schema" yaml:"schema"`
+}
+
+// Schema é um objeto de schema do OpenAPI 3, somente com as propriedades
+// utilizadas pelos parâmetros do gosparse.
+type Schema struct {
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Description          string             `json:"description,omitempty" yaml:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	Default              any                `json:"default,omitempty" yaml:"default,omitempty"`
+}
+
+const (
+	// DEEP_OBJECT é o estilo dos parâmetros com membros entre colchetes
+	//
+	//	filter[price_gte]=10
+	DEEP_OBJECT = "deepObject"
+	// FORM é o estilo dos parâmetros com valores separados por vírgula
+	// (explode: false)
+	//
+	//	sort=-created_at,title
+	FORM = "form"
+)
+
+// Generator gera os parâmetros de querystring aceitos por um Schema.
+type Generator struct {
+	// Schema é o Schema compilado da estrutura documentada
+	Schema *gosparse.Schema
+}
+
+// Parameters devolve os parâmetros aceitos, na ordem "include", "fields",
+// "filter", "sort" e "page".
+//
+// Os parâmetros "include", "filter" e "sort" são omitidos quando o Schema
+// não aceita nenhum relacionamento, filtro ou campo de ordenação.
+func (g Generator) Parameters() []Parameter {
+	gs := g.Schema.Gosparse()
+	parameters := make([]Parameter, 0, 5)
+
+	if len(gs.Include.Paths()) > 0 {
+		parameters = append(parameters, includeParameter(gs.Include))
+	}
+
+	parameters = append(parameters, fieldsParameter(gs.Fieldset))
+
+	if len(gs.Filter.Accepted) > 0 {
+		parameters = append(parameters, g.filterParameter(gs.Filter))
+	}
+
+	if len(gs.Sort.Accepted) > 0 {
+		parameters = append(parameters, sortParameter(gs.Sort))
+	}
+
+	return append(parameters, pageParameter(gs.Pagination))
+}
+
+// Components devolve os parâmetros com o nome de cada um como chave, no
+// formato de "components.parameters".
+func (g Generator) Components() map[string]Parameter {
+	components := make(map[string]Parameter)
+	for _, parameter := range g.Parameters() {
+		components[parameter.Name] = parameter
+	}
+
+	return components
+}
+
+// document devolve o documento que pode ser mesclado na especificação.
+func (g Generator) document() map[string]any {
+	return map[string]any{
+		"components": map[string]any{"parameters": g.Components()},
+	}
+}
+
+// JSON devolve o documento com os parâmetros em "components.parameters"
+// em JSON.
+func (g Generator) JSON() ([]byte, error) {
+	return json.MarshalIndent(g.document(), "", "  ")
+}
+
+// YAML devolve o documento com os parâmetros em "components.parameters"
+// em YAML.
+func (g Generator) YAML() ([]byte, error) {
+	var buf bytes.Buffer
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(g.document()); err != nil {
+		return nil, err
+	}
+
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// includeParameter descreve o parâmetro "include" com os caminhos de
+// relacionamento aceitos e os nomes alternativos.
+func includeParameter(includes include.Includes) Parameter {
+	paths := includes.Paths()
+	for alias := range includes.Aliases {
+		paths = append(paths, alias)
+	}
+
+	stdsort.Strings(paths)
+
+	return Parameter{
+		Name:        include.SEARCH_PARAM,
+		In:          "query",
+		Description: "Comma separated relationship paths to include.",
+		Style:       FORM,
+		Schema:      &Schema{Type: "array", Items: &Schema{Type: "string", Enum: paths}},
+	}
+}
+
+// fieldsParameter descreve o parâmetro "fields[TYPE]" com os atributos
+// aceitos para cada tipo de recurso.
+func fieldsParameter(fieldset sparsefieldsets.Fieldset) Parameter {
+	properties := make(map[string]*Schema, len(fieldset.Resources))
+
+	for typ, attrs := range fieldset.Resources {
+		items := &Schema{Type: "string"}
+		if attrs != nil {
+			items.Enum = sortedKeys(attrs)
+		}
+
+		properties[typ] = &Schema{
+			Type:        "array",
+			Description: fmt.Sprintf("Comma separated attributes of %s.", typ),
+			Items:       items,
+		}
+	}
+
+	description := "Sparse fieldsets per resource type."
+	if fieldset.Primary != "" {
+		description = fmt.Sprintf(
+			"Sparse fieldsets per resource type. Fields without type refer to %s.", fieldset.Primary,
+		)
+	}
+
+	return Parameter{
+		Name:        sparsefieldsets.SEARCH_PARAM,
+		In:          "query",
+		Description: description,
+		Style:       DEEP_OBJECT,
+		Explode:     true,
+		Schema:      object(properties),
+	}
+}
+
+// filterParameter descreve o parâmetro "filter" com uma propriedade para
+// cada campo aceito e para cada predicado permitido pelo tipo do campo
+// (veja Predicates).
+func (g Generator) filterParameter(f filter.Filter) Parameter {
+	properties := make(map[string]*Schema)
+
+	for field := range f.Accepted {
+		var t reflect.Type
+		if conf, exists := g.Schema.Field(field); exists && !conf.Relation {
+			t = conf.Type
+		}
+
+		value := valueSchema(t)
+		properties[field] = value
+
+		for _, predicate := range Predicates(t) {
+			key := field + "_" + predicate.String()
+
+			switch predicate {
+			case filter.IN, filter.NIN:
+				properties[key] = &Schema{Type: "array", Items: value}
+			case filter.NULL, filter.NOT_NULL, filter.BLANK:
+				properties[key] = &Schema{Type: "boolean"}
+			default:
+				properties[key] = value
+			}
+		}
+	}
+
+	return Parameter{
+		Name:        filter.SEARCH_PARAM,
+		In:          "query",
+		Description: "Filters by field with an optional predicate suffix, as in filter[price_gte].",
+		Style:       DEEP_OBJECT,
+		Explode:     true,
+		Schema:      object(properties),
+	}
+}
+
+// sortParameter descreve o parâmetro "sort" com os campos aceitos em
+// ordem crescente e decrescente (prefixo "-").
+func sortParameter(s sort.Sorter) Parameter {
+	fields := make([]string, 0, len(s.Accepted)*2)
+	for _, field := range sortedKeys(s.Accepted) {
+		fields = append(fields, field, "-"+field)
+	}
+
+	return Parameter{
+		Name:        sort.SORT_PARAM,
+		In:          "query",
+		Description: "Comma separated sort fields, descending when prefixed with -.",
+		Style:       FORM,
+		Schema:      &Schema{Type: "array", Items: &Schema{Type: "string", Enum: fields}},
+	}
+}
+
+// pageParameter descreve o parâmetro "page" com os limites e os valores
+// padrão configurados. O tamanho máximo só é documentado quando
+// configurado (veja gosparse.MaxPageSize).
+func pageParameter(p pagination.Pagination) Parameter {
+	if p == nil {
+		p = *pagination.New()
+	}
+
+	size := integer(1, p[pagination.SIZE])
+	if max := p[pagination.MAX]; max > 0 {
+		size.Maximum = &max
+	}
+
+	properties := map[string]*Schema{
+		string(pagination.NUMBER): integer(1, p[pagination.NUMBER]),
+		string(pagination.SIZE):   size,
+		string(pagination.OFFSET): integer(0, p[pagination.OFFSET]),
+	}
+
+	return Parameter{
+		Name:        pagination.PAGE_PARAM,
+		In:          "query",
+		Description: "Page number and size, or offset.",
+		Style:       DEEP_OBJECT,
+		Explode:     true,
+		Schema:      object(properties),
+	}
+}
+
+// Predicates devolve os predicados permitidos para o tipo do campo, na
+// ordem em que são documentados. São os mesmos predicados aceitos pelo
+// parâmetro "filter" (veja filter.Predicates):
+//
+//   - textos: todos, comparados em ordem lexicográfica
+//   - números e datas: eq, neq, in, nin, gt, gte, lt, lte, null, notnull
+//   - booleanos: eq, neq, null, notnull
+//
+// Campos sem tipo (nil) são tratados como textos.
+func Predicates(t reflect.Type) []filter.Predicate {
+	return filter.Predicates(t)
+}
+
+// valueSchema devolve o schema do valor de um filtro para o tipo do campo.
+func valueSchema(t reflect.Type) *Schema {
+	switch k := filter.KindOf(t); k {
+	case filter.DATE_TIME:
+		return &Schema{Type: string(filter.STRING), Format: string(k)}
+	default:
+		return &Schema{Type: string(k)}
+	}
+}
+
+// integer devolve o schema de um inteiro com o valor mínimo e o padrão.
+func integer(minimum, fallback int) *Schema {
+	return &Schema{Type: "integer", Minimum: &minimum, Default: fallback}
+}
+
+// object devolve o schema de um objeto que aceita somente as propriedades.
+func object(properties map[string]*Schema) *Schema {
+	additional := false
+	return &Schema{Type: "object", Properties: properties, AdditionalProperties: &additional}
+}
+
+// sortedKeys devolve as chaves do map em ordem alfabética.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	stdsort.Strings(keys)
+	return keys
+}
+
+// Constructor -----------------
+
+func New(schema *gosparse.Schema) *Generator {
+	return &Generator{Schema: schema}
+}
@@ -0,0 +1,87 @@
+package openapi_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/openapi"
+	"github.com/stretchr/testify/require"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+type Article struct {
+	_         struct{}  `gosparse:"type:articles"`
+	Title     string    `gosparse:"name:title;select;sort;filter"`
+	Price     float64   `gosparse:"name:price;select;sort;filter"`
+	Published bool      `gosparse:"name:published;filter"`
+	CreatedAt time.Time `gosparse:"name:created_at;select;sort"`
+	Author    *Author   `gosparse:"name:author;relation;alias:writer"`
+}
+
+type Author struct {
+	_    struct{} `gosparse:"type:people"`
+	Name string   `gosparse:"name:name;select"`
+}
+
+// golden compara o documento com o arquivo testdata/<name>.golden,
+// atualizado com "go test ./openapi -update".
+func golden(t *testing.T, name string, got []byte) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+	if *update {
+		require.Nil(t, os.WriteFile(path, got, 0o644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Equal(t, string(want), string(got))
+}
+
+func TestYAML(t *testing.T) {
+	schema, err := gosparse.Compile(Article{}, gosparse.AcceptPagination(25), gosparse.MaxPageSize(100))
+	require.Nil(t, err)
+
+	spec, err := openapi.New(schema).YAML()
+	require.Nil(t, err)
+
+	golden(t, "articles.yaml", spec)
+}
+
+func TestJSON(t *testing.T) {
+	spec, err := openapi.New(gosparse.MustCompile[Author]()).JSON()
+	require.Nil(t, err)
+
+	golden(t, "people.json", spec)
+}
+
+func TestParameters(t *testing.T) {
+	parameters := openapi.New(gosparse.MustCompile[Article]()).Parameters()
+
+	names := make([]string, 0, len(parameters))
+	for _, parameter := range parameters {
+		names = append(names, parameter.Name)
+	}
+
+	require.Equal(t, []string{"include", "fields", "filter", "sort", "page"}, names)
+	require.Equal(t, []string{"author", "writer"}, parameters[0].Schema.Items.Enum)
+	require.Equal(t, []string{"created_at", "-created_at", "price", "-price", "title", "-title"}, parameters[3].Schema.Items.Enum)
+}
+
+func TestPredicates(t *testing.T) {
+	require.Equal(t,
+		[]filter.Predicate{filter.EQ, filter.NEQ, filter.NULL, filter.NOT_NULL},
+		openapi.Predicates(reflect.TypeOf(true)),
+	)
+	require.Contains(t, openapi.Predicates(reflect.TypeOf(time.Time{})), filter.GTE)
+	require.NotContains(t, openapi.Predicates(reflect.TypeOf(0)), filter.START)
+	require.Contains(t, openapi.Predicates(nil), filter.START)
+	require.Contains(t, openapi.Predicates(nil), filter.GT)
+}
@@ -0,0 +1,15 @@
+package gosparse
+
+import "github.com/jeanmolossi/gosparse/internal/pagination"
+
+// Page são os valores de paginação de Query.Page
+//
+//	q.Page.Get(PageSize)   // 10
+//	q.Page.IsSet(PageSize) // false - default
+type Page = pagination.Page
+
+const (
+	PageSize   = pagination.SIZE
+	PageNumber = pagination.NUMBER
+	PageOffset = pagination.OFFSET
+)
@@ -0,0 +1,46 @@
+package gosparse
+
+import "github.com/jeanmolossi/gosparse/internal/filter"
+
+// Predicate é o predicado de um campo no parâmetro "filter"
+//
+//	filter[price_gte]=10 // GTE
+type Predicate = filter.Predicate
+
+const (
+	NONE     = filter.NONE
+	EQ       = filter.EQ
+	NEQ      = filter.NEQ
+	IN       = filter.IN
+	NIN      = filter.NIN
+	GT       = filter.GT
+	GTE      = filter.GTE
+	LT       = filter.LT
+	LTE      = filter.LTE
+	BLANK    = filter.BLANK
+	NULL     = filter.NULL
+	NOT_NULL = filter.NOT_NULL
+	START    = filter.START
+	END      = filter.END
+)
+
+// Filters são os filtros de Query.Filter, com o predicado e os valores de
+// cada campo
+type Filters = filter.Filters
+
+// FilterField é o predicado e os valores de um campo de Filters
+type FilterField = filter.Field
+
+// Condition é um nó da árvore de condições do parâmetro "filter" (veja
+// Query.Where)
+type Condition = filter.Condition
+
+// Operator é o operador lógico de uma Condition
+type Operator = filter.Operator
+
+const (
+	AND  = filter.AND
+	OR   = filter.OR
+	NOT  = filter.NOT
+	LEAF = filter.LEAF
+)
@@ -0,0 +1,238 @@
+package gosparse
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/include"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
+)
+
+// CtxKey é a chave do contexto para o Query armazenado por Handle.
+type CtxKey struct{}
+
+// Relation é um relacionamento de Query.Include
+type Relation = include.Relation
+
+// Fields são os atributos de cada tipo de recurso de Query.Fields
+type Fields = sparsefieldsets.Fields
+
+// Query é o resultado da extração de todos os parâmetros da querystring.
+//
+// Diferente dos valores armazenados no contexto, o Query não depende da
+// configuração (Gosparse) para ser consultado, portanto pode ser repassado
+// explicitamente para repositórios:
+//
+//	q, err := gs.Parse(r.URL.Query())
+//	if err != nil {
+//		// ...
+//	}
+//
+//	articles, err := repository.List(ctx, q)
+type Query struct {
+	// Resource é o tipo de recurso dos dados primários
+	Resource string
+	// Include são os relacionamentos solicitados, já resolvidos para o
+	// caminho canônico (veja Relation)
+	Include []Relation
+	// Fields são os atributos de cada tipo de recurso que devem ser
+	// retornados, já com a seleção padrão dos tipos não solicitados e os
+	// atributos obrigatórios:
+	//
+	//	// fields[articles]=title
+	//	Fields{"articles": {"title", "id"}, "people": {"name"}}
+	Fields Fields
+	// Filter são os filtros solicitados com predicado e valores
+	Filter Filters
+	// Condition são as condições das expressões (veja FilterExpressions)
+	// que não podem ser representadas em Filter, como OR entre campos
+	// diferentes, unidas por AND aos filtros de Filter:
+	//
+	//	// filter=price > 10 AND (status = "open" OR NOT archived)
+	//	Filter    // price_gt
+	//	Condition // AND(OR(status_eq, archived_eq))
+	//
+	// Para a árvore completa de condições utilize Where.
+	Condition Condition
+	// Sort são os campos ordenados, na ordem em que foram solicitados
+	Sort []Key
+	// Page são os valores de paginação já mesclados com os valores padrão
+	// (veja PageSize, PageNumber e PageOffset)
+	Page Page
+
+	// defaults são os tipos de recurso de Fields que não foram solicitados,
+	// ou seja, que receberam a seleção padrão
+	defaults map[string]struct{}
+}
+
+// Includes devolve os caminhos canônicos dos relacionamentos solicitados,
+// sem repetições.
+func (q *Query) Includes() []string {
+	paths := make([]string, 0, len(q.Include))
+	seen := make(map[string]struct{}, len(q.Include))
+
+	for _, rel := range q.Include {
+		if _, duplicate := seen[rel.Path]; duplicate {
+			continue
+		}
+
+		seen[rel.Path] = struct{}{}
+		paths = append(paths, rel.Path)
+	}
+
+	return paths
+}
+
+// Where devolve a árvore completa de condições do Query, ou seja, a
+// conjunção dos filtros de Filter com as condições de Condition. É a
+// árvore consultada pelos backends.
+func (q *Query) Where() filter.Condition {
+	return filter.And(filter.All(q.Filter), q.Condition)
+}
+
+// Select devolve os atributos que devem ser retornados para o tipo de
+// recurso. O tipo vazio (sparsefieldsets.PRIMARY) se refere ao Resource,
+// ou a sparsefieldsets.ROOT quando o Resource não é definido.
+func (q *Query) Select(typ string) []string {
+	if typ == sparsefieldsets.PRIMARY {
+		typ = q.primary()
+	}
+
+	return q.Fields[typ]
+}
+
+// Encode devolve a querystring que representa o Query, inversa de Parse:
+//
+//	q, _ := gs.Parse(query)
+//	same, _ := gs.Parse(q.Encode()) // q e same são equivalentes
+//
+// Somente os valores solicitados são codificados, ou seja, tipos de recurso
+// com a seleção padrão e valores padrão de paginação são omitidos. Os
+// atributos de Resource são codificados no parâmetro "fields" sem tipo.
+//
+// Os atributos selecionados e os valores de filtros de igualdade (como IN e
+// NIN) são codificados em ordem alfabética, portanto Queries equivalentes
+// têm a mesma codificação. Vírgulas nos valores dos filtros são escapadas
+// com barra invertida (`a\,b`). As condições de Condition são codificadas
+// como expressão no parâmetro "filter" sem colchetes.
+//
+// Para uma representação em texto determinística, com os parâmetros em
+// ordem alfabética, utilize String.
+func (q *Query) Encode() url.Values {
+	fields := make(sparsefieldsets.Fields, len(q.Fields))
+	for typ, attrs := range q.Fields {
+		if _, isDefault := q.defaults[typ]; isDefault {
+			continue
+		}
+
+		if typ == q.primary() {
+			typ = sparsefieldsets.PRIMARY
+		}
+
+		fields[typ] = attrs
+	}
+
+	query := url.Values{}
+	for _, encoded := range []url.Values{
+		include.Encode(q.Include),
+		sparsefieldsets.Encode(fields),
+		filter.Encode(q.Filter),
+		sort.Encode(q.Sort),
+		pagination.Encode(q.Page),
+	} {
+		for key, values := range encoded {
+			query[key] = values
+		}
+	}
+
+	if !q.Condition.Empty() {
+		query.Set(filter.SEARCH_PARAM, q.Condition.String())
+	}
+
+	return query
+}
+
+// primary devolve o tipo de recurso do parâmetro "fields" sem tipo.
+func (q *Query) primary() string {
+	if q.Resource == "" {
+		return sparsefieldsets.ROOT
+	}
+
+	return q.Resource
+}
+
+// String devolve a querystring canônica do Query, com os parâmetros em
+// ordem alfabética. Pode ser utilizada como chave de cache ou em links
+// de paginação.
+//
+//	q.String() // fields=title&include=author&page%5Bnumber%5D=2&sort=-id
+func (q *Query) String() string {
+	return q.Encode().Encode()
+}
+
+// Parse recebe a querystring da request e devolve o Query com todos os
+// parâmetros extraídos, sem a necessidade de um contexto.
+//
+// O Authorizer, quando configurado, recebe um context.Background, para
+// repassar o contexto da request utilize ParseContext.
+func (g Gosparse) Parse(query url.Values) (*Query, error) {
+	return g.ParseContext(context.Background(), query)
+}
+
+// ParseContext funciona como Parse, repassando o contexto recebido para
+// o Authorizer.
+func (g Gosparse) ParseContext(ctx context.Context, query url.Values) (*Query, error) {
+	ctx, err := g.handle(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.query(ctx), nil
+}
+
+// query monta o Query a partir dos valores extraídos para o contexto.
+func (g Gosparse) query(ctx context.Context) *Query {
+	requested := g.Fieldset.GetAll(ctx)
+
+	// os tipos solicitados já foram validados contra os tipos aceitos
+	fields := make(sparsefieldsets.Fields, len(g.Fieldset.Resources))
+	defaults := make(map[string]struct{})
+
+	for typ := range g.Fieldset.Resources {
+		fields[typ] = g.Fieldset.Get(ctx, typ)
+
+		if _, selected := requested[typ]; !selected {
+			defaults[typ] = struct{}{}
+		}
+	}
+
+	// sem tipo primário o parâmetro "fields" sem tipo é solicitado como
+	// sparsefieldsets.ROOT, que não é um dos tipos aceitos
+	if attrs, selected := requested[sparsefieldsets.ROOT]; selected {
+		fields[sparsefieldsets.ROOT] = attrs
+	}
+
+	return &Query{
+		Resource:  g.Fieldset.Primary,
+		Include:   g.Include.GetRelations(ctx),
+		Fields:    fields,
+		Filter:    g.Filter.GetAll(ctx),
+		Condition: g.Filter.GetCondition(ctx),
+		Sort:      g.Sort.GetOrder(ctx),
+		Page:      g.Pagination.GetPage(ctx),
+		defaults:  defaults,
+	}
+}
+
+// FromContext devolve o Query armazenado no contexto por Handle.
+func FromContext(ctx context.Context) (*Query, error) {
+	if query, present := ctx.Value(CtxKey{}).(*Query); present {
+		return query, nil
+	}
+
+	return nil, fmt.Errorf("query is not present on context")
+}
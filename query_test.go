@@ -0,0 +1,184 @@
+package gosparse
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/jeanmolossi/gosparse/internal/include"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	gs, err := Extract(Post{}, AlwaysFields("posts", "id"), AcceptPagination(20), AcceptSortBy("title"))
+	require.Nil(t, err)
+
+	query, err := gs.Parse(url.Values{
+		"include":       {"author,commentAuthors"},
+		"fields":        {"title"},
+		"filter[id_in]": {"1,2"},
+		"page[number]":  {"3"},
+		"sort":          {"title,-id"},
+	})
+	require.Nil(t, err)
+
+	require.Equal(t, "posts", query.Resource)
+	require.Equal(t, []include.Relation{
+		{Path: "author"},
+		{Path: "comments"},
+		{Path: "comments.author", Alias: "commentAuthors"},
+	}, query.Include)
+	require.Equal(t, []string{"author", "comments", "comments.author"}, query.Includes())
+
+	require.Equal(t, []string{"title", "id"}, query.Select(""))
+	require.Equal(t, []string{"name", "posts"}, query.Select("people"))
+
+	// os tipos do Query são consultados sem os pacotes internos
+	require.Equal(t, FilterField{Predicate: IN, Values: []string{"1", "2"}}, query.Filter["id"])
+	require.Equal(t, []Key{{Field: "title", Sorting: ASC}, {Field: "id", Sorting: DESC}}, query.Sort)
+
+	require.Equal(t, 3, query.Page.Get(PageNumber))
+	require.Equal(t, 20, query.Page.Get(PageSize))
+	require.False(t, query.Page.IsSet(PageSize))
+	require.Equal(t, 0, query.Page.Get(PageOffset))
+
+	_, err = gs.Parse(url.Values{"sort": {"body"}})
+	require.EqualError(t, err, "unsupported sorting by: body")
+}
+
+func TestFromContext(t *testing.T) {
+	gs, err := Extract(Dummy{})
+	require.Nil(t, err)
+
+	_, err = FromContext(context.Background())
+	require.EqualError(t, err, "query is not present on context")
+
+	ctx, err := gs.Handle(context.Background(), url.Values{"sort": {"-created_at"}})
+	require.Nil(t, err)
+
+	query, err := FromContext(ctx)
+	require.Nil(t, err)
+	require.Equal(t, []sort.Key{{Field: "created_at", Sorting: sort.DESC}}, query.Sort)
+	require.Empty(t, query.Include)
+	require.Equal(t, 10, query.Page.Get(pagination.SIZE))
+}
+
+func TestQueryEncode(t *testing.T) {
+	gs, err := Extract(Post{},
+		AcceptSortBy("title"),
+		AcceptFilters("created_at", "comments_count"),
+		AcceptPagination(20),
+	)
+	require.Nil(t, err)
+
+	query, err := gs.Parse(url.Values{
+		"include":                {"commentAuthors"},
+		"fields[posts]":          {"title"},
+		"filter[id_gte]":         {"10"},
+		"filter[created_at]":     {"2023-01-01"},
+		"page[number]":           {"2"},
+		"sort":                   {"-id,title"},
+		"fields[people]":         {"name"},
+		"fields[comments]":       {"body"},
+		"filter[comments_count]": {"1"},
+	})
+	require.Nil(t, err)
+
+	require.Equal(t, url.Values{
+		"include":                {"commentAuthors,comments"},
+		"fields":                 {"title"},
+		"fields[people]":         {"name"},
+		"fields[comments]":       {"body"},
+		"filter[id_gte]":         {"10"},
+		"filter[created_at]":     {"2023-01-01"},
+		"filter[comments_count]": {"1"},
+		"page[number]":           {"2"},
+		"sort":                   {"-id,title"},
+	}, query.Encode())
+}
+
+func TestQueryRoundTrip(t *testing.T) {
+	gs, err := Extract(Post{},
+		NestedFields(),
+		AcceptSortBy("title"),
+		AcceptFilters("created_at"),
+		AlwaysFields("posts", "id"),
+	)
+	require.Nil(t, err)
+
+	random := rand.New(rand.NewSource(42))
+	pick := func(values ...string) []string {
+		picked := make([]string, 0, len(values))
+		for _, i := range random.Perm(len(values)) {
+			if random.Intn(2) == 0 {
+				picked = append(picked, values[i])
+			}
+		}
+
+		return picked
+	}
+
+	predicates := []string{"", "_eq", "_neq", "_in", "_nin", "_gt", "_gte", "_lt", "_lte", "_start", "_end"}
+
+	for i := 0; i < 500; i++ {
+		query := url.Values{}
+
+		if include := pick("author", "author.posts", "comments", "comments.author", "commentAuthors"); len(include) > 0 {
+			query.Set("include", strings.Join(include, ","))
+		}
+
+		// a seleção pode ser omitida ou vazia (nenhum atributo)
+		if random.Intn(3) > 0 {
+			query.Set("fields", strings.Join(pick("id", "title", "author", "author.name", "comments.body"), ","))
+		}
+
+		if random.Intn(3) > 0 {
+			query.Set("fields[people]", strings.Join(pick("name", "posts"), ","))
+		}
+
+		for _, field := range pick("id", "created_at") {
+			// id é numérico, portanto não aceita start e end
+			allowed := predicates
+			if field == "id" {
+				allowed = predicates[:len(predicates)-2]
+			}
+
+			predicate := allowed[random.Intn(len(allowed))]
+			query.Set("filter["+field+predicate+"]", strings.Join(pick("1", "2", "3", "x"), ","))
+		}
+
+		if sorting := pick("id", "-id", "title", "-title"); len(sorting) > 0 {
+			query.Set("sort", strings.Join(sorting, ","))
+		}
+
+		for _, param := range pick("number", "size", "offset") {
+			// number e size começam em 1, offset em 0
+			query.Set("page["+param+"]", fmt.Sprint(random.Intn(100)+1))
+		}
+
+		parsed, err := gs.Parse(query)
+		require.Nil(t, err, query.Encode())
+
+		reparsed, err := gs.Parse(parsed.Encode())
+		require.Nil(t, err, parsed.String())
+
+		// a seleção e os valores dos filtros são codificados em ordem
+		// alfabética
+		for typ, attrs := range parsed.Fields {
+			require.ElementsMatch(t, attrs, reparsed.Fields[typ], query.Encode())
+		}
+
+		for field, f := range parsed.Filter {
+			require.ElementsMatch(t, f.Values, reparsed.Filter[field].Values, query.Encode())
+		}
+
+		reparsed.Fields, reparsed.Filter = parsed.Fields, parsed.Filter
+		require.Equal(t, parsed, reparsed, query.Encode())
+		require.Equal(t, parsed.String(), reparsed.String())
+	}
+}
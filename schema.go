@@ -0,0 +1,276 @@
+package gosparse
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sync"
+
+	"github.com/jeanmolossi/gosparse/internal/filter"
+	"github.com/jeanmolossi/gosparse/internal/include"
+	"github.com/jeanmolossi/gosparse/internal/pagination"
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/jeanmolossi/gosparse/internal/sparsefieldsets"
+)
+
+// Schema é a configuração compilada a partir da tag "gosparse" de uma
+// estrutura.
+//
+// A configuração de um Schema é congelada ao ser compilada, portanto pode
+// ser compartilhada entre goroutines e requests. Os campos e a
+// configuração são consultados somente com cópias (veja Field, FieldNames
+// e Gosparse).
+type Schema struct {
+	// Type é o tipo da estrutura compilada
+	Type reflect.Type
+	// Resource é o tipo de recurso dos dados primários
+	Resource string
+
+	// fields são os campos com a tag "gosparse", incluindo os campos das
+	// relações com o nome da relação como prefixo:
+	//
+	//	map[string]Field{
+	//		"title": {...},
+	//		"nested": {...},
+	//		"nested.dummy": {...},
+	//	}
+	fields   map[string]Field
+	gosparse Gosparse
+	storage  Mapping
+}
+
+// schemas armazena os Schemas compilados por ExtractCached e MustCompile
+// com o reflect.Type da estrutura como chave.
+var schemas sync.Map
+
+// Gosparse devolve uma cópia congelada da configuração de parâmetros
+// aceitos do Schema. Alterações na cópia não afetam o Schema, que pode
+// estar compartilhado por MustCompile e ExtractCached.
+func (s *Schema) Gosparse() Gosparse {
+	gs := s.gosparse
+	gs.freeze()
+
+	return gs
+}
+
+// Handle recebe o contexto e a querystring da request e extraí todos os
+// parâmetros a partir da configuração compilada (veja Gosparse.Handle).
+func (s *Schema) Handle(ctx context.Context, query url.Values) (context.Context, error) {
+	return s.gosparse.Handle(ctx, query)
+}
+
+// Field recebe o nome do campo na querystring e devolve uma cópia de sua
+// configuração. Os campos das relações são nomeados com o nome da relação
+// como prefixo:
+//
+//	schema.Field("nested.dummy")
+func (s *Schema) Field(name string) (Field, bool) {
+	field, exists := s.fields[name]
+	if exists {
+		field.Index = append([]int(nil), field.Index...)
+	}
+
+	return field, exists
+}
+
+// FieldNames devolve os nomes de todos os campos do Schema em ordem
+// alfabética (veja Field).
+func (s *Schema) FieldNames() []string {
+	return sortedKeys(s.fields)
+}
+
+// Compile recebe interface e compila o Schema baseado na tag "gosparse"
+// da estrutura. As opções são as mesmas de Extract.
+//
+// Compile percorre a estrutura a cada chamada, para compilar somente uma
+// vez por tipo utilize MustCompile ou ExtractCached.
+func Compile(s any, options ...GosparseOpt) (*Schema, error) {
+	value, err := getValueAndValidate(s)
+	if err != nil {
+		return nil, err
+	}
+
+	extracted, err := handleTags(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := exported(value.Type(), extracted); err != nil {
+		return nil, err
+	}
+
+	gs := Gosparse{
+		Include:    *include.New(),
+		Fieldset:   *sparsefieldsets.New(),
+		Filter:     *filter.New(),
+		Pagination: *pagination.New(),
+		Sort:       *sort.New(),
+	}
+
+	for _, opt := range options {
+		if opt == nil {
+			continue
+		}
+
+		opt(&gs)
+	}
+
+	primary := gs.Fieldset.Primary
+	if primary == "" {
+		primary = typeName(value.Type(), ROOT_TYPE)
+	}
+
+	TypeName(primary)(&gs)
+
+	relations := make([]string, 0, len(extracted))
+	filters := make([]string, 0, len(extracted))
+	sorter := make([]string, 0, len(extracted))
+
+	for field, conf := range extracted {
+		if conf.Select {
+			gs.Fieldset.AddPath(field)
+		}
+
+		if conf.Relation {
+			relations = append(relations, field)
+		}
+
+		if conf.Filter {
+			filters = append(filters, field)
+		}
+
+		if conf.Sort {
+			sorter = append(sorter, field)
+		}
+	}
+
+	AcceptRelations(relations...)(&gs)
+
+	if err := walkFieldsets(value.Type(), primary, &gs.Fieldset, map[reflect.Type]struct{}{}); err != nil {
+		return nil, err
+	}
+
+	AcceptFilters(filters...)(&gs)
+	AcceptSortBy(sorter...)(&gs)
+
+	// o tipo de cada campo restringe os predicados aceitos no filtro
+	for _, field := range filters {
+		gs.Filter.AddType(field, extracted[field].Type)
+	}
+
+	for _, field := range sortedKeys(extracted) {
+		conf := extracted[field]
+		if conf.Relation && conf.Alias != "" && !gs.Include.Has(conf.Alias) {
+			if _, exists := gs.Include.Aliases[conf.Alias]; !exists {
+				AliasRelation(conf.Alias, field)(&gs)
+			}
+		}
+	}
+
+	storage, err := compileStorage(value.Type(), extracted, gs.Storage)
+	if err != nil {
+		return nil, err
+	}
+
+	gs.freeze()
+
+	return &Schema{
+		Type:     value.Type(),
+		Resource: primary,
+		fields:   extracted,
+		gosparse: gs,
+		storage:  storage,
+	}, nil
+}
+
+// exported checa se os campos com a tag, e as relações percorridas até
+// eles, são exportados. Os valores dos campos não exportados não podem ser
+// lidos por Schema.Value e pelos backends.
+func exported(t reflect.Type, extracted map[string]Field) error {
+	for _, name := range sortedKeys(extracted) {
+		st := t
+
+		for i, index := range extracted[name].Index {
+			field := st.Field(index)
+			if !field.IsExported() {
+				return fmt.Errorf("field %s should be exported", name)
+			}
+
+			if i < len(extracted[name].Index)-1 {
+				st, _ = relationType(field)
+			}
+		}
+	}
+
+	return nil
+}
+
+// compileCached devolve o Schema compilado para o tipo, compilando-o
+// somente na primeira chamada.
+//
+// Compilações concorrentes do mesmo tipo podem acontecer, mas somente o
+// primeiro Schema armazenado será utilizado.
+func compileCached(t reflect.Type) (*Schema, error) {
+	if schema, cached := schemas.Load(t); cached {
+		return schema.(*Schema), nil
+	}
+
+	schema, err := Compile(reflect.New(t).Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	actual, _ := schemas.LoadOrStore(t, schema)
+	return actual.(*Schema), nil
+}
+
+// ExtractCached funciona como Extract, mas a estrutura é percorrida
+// somente uma vez por tipo. As chamadas seguintes devolvem uma cópia da
+// configuração já compilada (veja Schema.Gosparse). Para tratar requests
+// sem copiar os maps de campos aceitos utilize o Schema diretamente:
+//
+//	gosparse.MustCompile[Article]().Handle(r.Context(), r.URL.Query())
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		gs, err := gosparse.ExtractCached(Article{})
+//		// ...
+//	}
+//
+// Para definir opções (como TypeName) utilize Compile uma única vez.
+func ExtractCached(s any) (Gosparse, error) {
+	value, err := getValueAndValidate(s)
+	if err != nil {
+		return Gosparse{}, err
+	}
+
+	schema, err := compileCached(value.Type())
+	if err != nil {
+		return Gosparse{}, err
+	}
+
+	return schema.Gosparse(), nil
+}
+
+// MustCompile devolve o Schema compilado para o tipo T, compilando-o
+// somente uma vez. Entra em pânico caso T não possa ser compilado,
+// portanto deve ser utilizado na inicialização:
+//
+//	var articles = gosparse.MustCompile[Article]()
+func MustCompile[T any]() *Schema {
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("gosparse: can not compile %s: can extract from structs only", t))
+	}
+
+	schema, err := compileCached(t)
+	if err != nil {
+		panic(fmt.Sprintf("gosparse: can not compile %s: %s", t, err))
+	}
+
+	return schema
+}
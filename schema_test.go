@@ -0,0 +1,130 @@
+package gosparse
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	schema, err := Compile(Post{})
+	require.Nil(t, err)
+
+	require.Equal(t, reflect.TypeOf(Post{}), schema.Type)
+	require.Equal(t, "posts", schema.Resource)
+
+	field, exists := schema.Field("comments.author.posts")
+	require.True(t, exists)
+	require.True(t, field.Relation)
+	require.Equal(t, reflect.TypeOf([]Post{}), field.Type)
+	require.Equal(t, []int{5, 1, 1}, field.Index)
+
+	_, exists = schema.Field("internal")
+	require.False(t, exists)
+
+	_, err = Compile("invalid")
+	require.Error(t, err)
+
+	t.Run("should reject unexported tagged fields", func(t *testing.T) {
+		type Author struct {
+			name string `gosparse:"name:name;select"`
+		}
+
+		type Article struct {
+			Title  string  `gosparse:"name:title;select"`
+			Author *Author `gosparse:"name:author;relation"`
+		}
+
+		type Draft struct {
+			author *Author `gosparse:"name:author;relation"`
+		}
+
+		_, err := Compile(Article{})
+		require.EqualError(t, err, "field author.name should be exported")
+
+		_, err = Compile(Draft{})
+		require.EqualError(t, err, "field author should be exported")
+	})
+}
+
+func TestExtractCached(t *testing.T) {
+	first, err := ExtractCached(Post{})
+	require.Nil(t, err)
+
+	second, err := ExtractCached(&Post{})
+	require.Nil(t, err)
+
+	// a mesma configuração compilada deve ser reutilizada, mas devolvida
+	// em cópias que não compartilham maps com o Schema
+	require.Same(t, MustCompile[Post](), MustCompile[*Post]())
+	require.Equal(t, first.Fieldset.Resources, second.Fieldset.Resources)
+	require.NotEqual(t,
+		reflect.ValueOf(first.Sort.Accepted).Pointer(),
+		reflect.ValueOf(second.Sort.Accepted).Pointer(),
+	)
+
+	first.Sort.Accepted["internal"] = sort.ASC
+	first.Include.Relations["likes"] = nil
+	require.NotContains(t, MustCompile[Post]().Gosparse().Sort.Accepted, "internal")
+	require.False(t, MustCompile[Post]().Gosparse().Include.Has("likes"))
+
+	field, _ := MustCompile[Post]().Field("comments.author.posts")
+	field.Index[0] = 0
+	field, _ = MustCompile[Post]().Field("comments.author.posts")
+	require.Equal(t, []int{5, 1, 1}, field.Index)
+
+	_, err = ExtractCached(1)
+	require.Error(t, err)
+	require.Panics(t, func() { MustCompile[int]() })
+}
+
+func TestSchemaHandleConcurrent(t *testing.T) {
+	schema := MustCompile[Dummy]()
+
+	// require encerra somente a goroutine que falhou, portanto as
+	// verificações concorrentes utilizam assert
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			ctx, err := schema.Handle(context.Background(), url.Values{
+				"include": {"nested"},
+				"sort":    {"-created_at"},
+			})
+			if !assert.Nil(t, err) {
+				return
+			}
+
+			gs := schema.Gosparse()
+			assert.Equal(t, []string{"nested"}, gs.Include.Get(ctx))
+			assert.Equal(t, sort.DESC, gs.Sort.Get(ctx, "created_at"))
+		}()
+	}
+
+	wg.Wait()
+}
+
+func BenchmarkSchemaHandle(b *testing.B) {
+	schema := MustCompile[Post]()
+	query := url.Values{
+		"include":       {"author,comments.author"},
+		"fields[posts]": {"title"},
+		"sort":          {"-id"},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := schema.Handle(context.Background(), query); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
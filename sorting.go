@@ -0,0 +1,16 @@
+package gosparse
+
+import "github.com/jeanmolossi/gosparse/internal/sort"
+
+// Sorting é a ordenação de um campo no parâmetro "sort"
+//
+//	sort=-created_at // DESC
+type Sorting = sort.Sorting
+
+// Key é um campo ordenado de Query.Sort
+type Key = sort.Key
+
+const (
+	ASC  = sort.ASC
+	DESC = sort.DESC
+)
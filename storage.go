@@ -0,0 +1,353 @@
+package gosparse
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Storage é o mapeamento de um campo da querystring para o armazenamento,
+// utilizado pelos backends para montar as consultas. O backend em memória
+// (backend/memory) lê os valores com Schema.Value, que calcula os campos
+// computados.
+//
+// Um campo é virtual quando não corresponde a uma coluna, ou seja, quando
+// é calculado por uma expressão SQL (Expr) ou por uma função (Compute).
+type Storage struct {
+	// Column é a coluna (ou caminho) do campo no armazenamento, definida
+	// pela configuração "column" da tag ou pela opção MapColumn
+	//
+	//	created_at -> p.created_ts
+	Column string
+	// Expr é a expressão SQL que calcula o campo virtual (veja VirtualField)
+	//
+	//	full_name -> first_name || ' ' || last_name
+	Expr string
+	// Compute calcula o valor do campo virtual a partir da estrutura
+	// (veja ComputedField)
+	Compute ComputeFunc
+	// Depends são os campos lidos por Compute, consultados pelos backends
+	// junto do campo computado
+	Depends []string
+	// Join é a junção de uma relação com a tabela relacionada, definida
+	// pela configuração "join" da tag ou pela opção JoinRelation. Nil nos
+	// campos que não são relações ou nas relações sem junção.
+	Join *Join
+}
+
+// Join é a junção de uma relação com a tabela relacionada, utilizada pelo
+// backend SQL para filtrar e ordenar pelos atributos da relação:
+//
+//	// join:author_id=authors.id
+//	Join{Local: "author_id", Table: "authors", Remote: "id"}
+type Join struct {
+	// Local é a coluna da tabela de origem
+	Local string
+	// Table é a tabela da relação
+	Table string
+	// Remote é a coluna da tabela da relação
+	Remote string
+	// Many indica uma relação para muitos, ou seja, uma lista (slice /
+	// array) de estruturas
+	Many bool
+}
+
+// parseJoin recebe a junção no formato "local=table.remote" e devolve o
+// Join correspondente. Uma junção inválida devolve nil.
+//
+//	parseJoin("id=comments.article_id")
+//	// &Join{Local: "id", Table: "comments", Remote: "article_id"}
+func parseJoin(join string) *Join {
+	local, target, found := strings.Cut(join, "=")
+	if !found {
+		return nil
+	}
+
+	i := strings.LastIndex(target, ".")
+	if i < 0 {
+		return nil
+	}
+
+	parsed := &Join{Local: local, Table: target[:i], Remote: target[i+1:]}
+	if parsed.Local == "" || parsed.Table == "" || parsed.Remote == "" {
+		return nil
+	}
+
+	return parsed
+}
+
+// ComputeFunc recebe a estrutura (o valor, não a referência) e devolve o
+// valor calculado do campo.
+//
+//	func(item any) (any, error) {
+//		article := item.(Article)
+//		return article.Price * (1 - article.Discount), nil
+//	}
+type ComputeFunc func(item any) (any, error)
+
+// Virtual indica se o campo não corresponde a uma coluna do armazenamento.
+func (s Storage) Virtual() bool {
+	return s.Expr != "" || s.Compute != nil
+}
+
+// Computed indica se o campo é calculado por uma função, portanto não
+// pode ser filtrado ou ordenado pelo armazenamento.
+func (s Storage) Computed() bool {
+	return s.Compute != nil
+}
+
+// Mapping é um map dos campos da querystring para o mapeamento de
+// armazenamento de cada um.
+//
+//	Mapping{
+//		"created_at": {Column: "p.created_ts"},
+//		"nested.dummy": {Column: "authors.display_name"},
+//	}
+type Mapping map[string]Storage
+
+// clone devolve uma cópia do mapeamento.
+func (m Mapping) clone() Mapping {
+	if m == nil {
+		return nil
+	}
+
+	copied := make(Mapping, len(m))
+	for name, storage := range m {
+		copied[name] = storage
+	}
+
+	return copied
+}
+
+// mapStorage altera o mapeamento do campo em uma cópia do Mapping, pois a
+// configuração pode estar compartilhada com outro Gosparse.
+func (g *Gosparse) mapStorage(field string, change func(*Storage)) {
+	mapping := g.Storage.clone()
+	if mapping == nil {
+		mapping = make(Mapping, 1)
+	}
+
+	storage := mapping[field]
+	change(&storage)
+	mapping[field] = storage
+
+	g.Storage = mapping
+}
+
+// MapColumn recebe o nome do campo na querystring e a coluna (ou caminho)
+// correspondente no armazenamento. Tem precedência sobre a configuração
+// "column" da tag.
+//
+//	gosparse.MapColumn("created_at", "p.created_ts")
+func MapColumn(field, column string) GosparseOpt {
+	return func(g *Gosparse) {
+		g.mapStorage(field, func(s *Storage) {
+			s.Column = column
+		})
+	}
+}
+
+// VirtualField recebe o nome do campo na querystring e a expressão SQL que
+// o calcula. O campo pode ser selecionado, filtrado e ordenado como uma
+// coluna pelo backend SQL.
+//
+//	gosparse.VirtualField("full_name", "first_name || ' ' || last_name")
+//
+// A expressão é utilizada sem escape, portanto nunca deve ser montada a
+// partir de valores da request.
+func VirtualField(field, expr string) GosparseOpt {
+	return func(g *Gosparse) {
+		g.mapStorage(field, func(s *Storage) {
+			s.Expr = expr
+		})
+	}
+}
+
+// ComputedField recebe o nome do campo na querystring, a função que
+// calcula o seu valor a partir da estrutura e os campos lidos pela função.
+// O campo pode ser selecionado, mas não pode ser filtrado ou ordenado
+// pelos backends que consultam o armazenamento, somente em memória (veja
+// backend/memory).
+//
+// Os backends consultam as dependências junto do campo computado, mesmo
+// quando não são solicitadas (veja Schema.Requires):
+//
+//	gosparse.ComputedField("discounted", discounted, "price", "discount")
+func ComputedField(field string, compute ComputeFunc, depends ...string) GosparseOpt {
+	return func(g *Gosparse) {
+		g.mapStorage(field, func(s *Storage) {
+			s.Compute = compute
+			s.Depends = depends
+		})
+	}
+}
+
+// JoinRelation recebe o nome da relação, a coluna da tabela de origem e a
+// coluna da tabela da relação no formato "table.column". Tem precedência
+// sobre a configuração "join" da tag.
+//
+//	gosparse.JoinRelation("author", "author_id", "authors.id")
+//	gosparse.JoinRelation("comments", "id", "comments.article_id")
+func JoinRelation(relation, local, remote string) GosparseOpt {
+	return func(g *Gosparse) {
+		g.mapStorage(relation, func(s *Storage) {
+			s.Join = parseJoin(local + "=" + remote)
+			if s.Join == nil {
+				// a junção inválida é rejeitada por Compile
+				s.Join = &Join{}
+			}
+		})
+	}
+}
+
+// compileStorage monta o mapeamento de armazenamento de cada campo
+// extraído a partir da configuração "column" da tag e do Mapping das
+// opções. Campos sem coluna são mapeados para o próprio nome.
+//
+// Os campos mapeados pelas opções devem ser campos da estrutura e as
+// junções somente podem ser definidas para as relações.
+func compileStorage(t reflect.Type, extracted map[string]Field, options Mapping) (Mapping, error) {
+	for name, storage := range options {
+		conf, exists := extracted[name]
+		if !exists {
+			return nil, fmt.Errorf("mapped field %s is not a field of %s", name, t)
+		}
+
+		if storage.Join != nil && !conf.Relation {
+			return nil, fmt.Errorf("join of %s requires a relation", name)
+		}
+
+		for _, depend := range storage.Depends {
+			if _, exists := extracted[depend]; !exists {
+				return nil, fmt.Errorf("computed field %s depends on %s, not a field of %s", name, depend, t)
+			}
+		}
+	}
+
+	mapping := make(Mapping, len(extracted))
+	for name, conf := range extracted {
+		storage := options[name]
+
+		if storage.Column == "" {
+			storage.Column = conf.Column
+		}
+
+		if storage.Column == "" {
+			storage.Column = name
+		}
+
+		if conf.Join != "" && !conf.Relation {
+			return nil, fmt.Errorf("join of %s requires a relation", name)
+		}
+
+		if storage.Join == nil && conf.Join != "" {
+			storage.Join = parseJoin(conf.Join)
+			if storage.Join == nil {
+				return nil, fmt.Errorf("invalid join %s of relation %s, expected local=table.remote", conf.Join, name)
+			}
+		}
+
+		if storage.Join != nil {
+			if storage.Join.Table == "" {
+				return nil, fmt.Errorf("invalid join of relation %s, expected local=table.remote", name)
+			}
+
+			join := *storage.Join
+			join.Many = many(conf.Type)
+			storage.Join = &join
+		}
+
+		mapping[name] = storage
+	}
+
+	return mapping, nil
+}
+
+// many indica se o tipo da relação é uma lista (slice / array).
+func many(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t.Kind() == reflect.Slice || t.Kind() == reflect.Array
+}
+
+// Storage recebe o nome do campo na querystring e devolve o seu
+// mapeamento de armazenamento.
+func (s *Schema) Storage(name string) (Storage, bool) {
+	storage, exists := s.storage[name]
+	return storage, exists
+}
+
+// Requires recebe os atributos selecionados e devolve os atributos
+// acrescidos das dependências dos campos computados, sem repetições:
+//
+//	// ComputedField("discounted", discounted, "price")
+//	schema.Requires([]string{"id", "discounted"}) // id, discounted, price
+func (s *Schema) Requires(attrs []string) []string {
+	required := make([]string, 0, len(attrs))
+	seen := make(map[string]struct{}, len(attrs))
+
+	add := func(name string) {
+		if _, duplicate := seen[name]; !duplicate {
+			seen[name] = struct{}{}
+			required = append(required, name)
+		}
+	}
+
+	for _, attr := range attrs {
+		add(attr)
+	}
+
+	for _, attr := range attrs {
+		for _, depend := range s.storage[attr].Depends {
+			add(depend)
+		}
+	}
+
+	return required
+}
+
+// Value recebe a estrutura do Schema (valor ou referência) e devolve o
+// valor do campo, calculado por Compute nos campos computados.
+//
+// Referências nulas no caminho do campo devolvem nil. Campos de relações
+// em listas (slices / arrays) não podem ser lidos.
+func (s *Schema) Value(item any, name string) (any, error) {
+	field, exists := s.fields[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown field %s on %s", name, s.Type)
+	}
+
+	value := reflect.ValueOf(item)
+	if value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+
+	if !value.IsValid() || value.Type() != s.Type {
+		return nil, fmt.Errorf("can not read %s: item is not a %s", name, s.Type)
+	}
+
+	if storage := s.storage[name]; storage.Computed() {
+		return storage.Compute(value.Interface())
+	}
+
+	for i, index := range field.Index {
+		if i > 0 {
+			switch value.Kind() {
+			case reflect.Pointer:
+				if value.IsNil() {
+					return nil, nil
+				}
+
+				value = value.Elem()
+			case reflect.Slice, reflect.Array:
+				return nil, fmt.Errorf("can not read %s: relation is a list", name)
+			}
+		}
+
+		value = value.Field(index)
+	}
+
+	return value.Interface(), nil
+}
@@ -0,0 +1,126 @@
+package gosparse
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/jeanmolossi/gosparse/internal/sort"
+	"github.com/stretchr/testify/require"
+)
+
+type Mapped struct {
+	Title     string  `gosparse:"name:title;select;sort;column:headline"`
+	Price     float64 `gosparse:"name:price;select"`
+	Final     float64 `gosparse:"name:final;select"`
+	Author    *Author `gosparse:"name:author;relation"`
+	CreatedAt string  `gosparse:"name:created_at;select;sort"`
+}
+
+func TestCompileStorage(t *testing.T) {
+	schema, err := Compile(Mapped{},
+		MapColumn("created_at", "p.created_ts"),
+		VirtualField("author.name", "authors.first_name || authors.last_name"),
+		ComputedField("final", func(item any) (any, error) {
+			return item.(Mapped).Price * 2, nil
+		}),
+	)
+	require.Nil(t, err)
+
+	storage, exists := schema.Storage("title")
+	require.True(t, exists)
+	require.Equal(t, Storage{Column: "headline"}, storage)
+
+	storage, _ = schema.Storage("created_at")
+	require.Equal(t, "p.created_ts", storage.Column)
+	require.False(t, storage.Virtual())
+
+	storage, _ = schema.Storage("author.name")
+	require.True(t, storage.Virtual())
+	require.False(t, storage.Computed())
+
+	storage, _ = schema.Storage("final")
+	require.True(t, storage.Computed())
+
+	_, exists = schema.Storage("unknown")
+	require.False(t, exists)
+
+	_, err = Compile(Mapped{}, MapColumn("unknown", "column"))
+	require.EqualError(t, err, "mapped field unknown is not a field of gosparse.Mapped")
+
+	_, err = Compile(Mapped{}, ComputedField("final", nil, "cost"))
+	require.EqualError(t, err, "computed field final depends on cost, not a field of gosparse.Mapped")
+}
+
+func TestSchemaRequires(t *testing.T) {
+	schema, err := Compile(Mapped{}, ComputedField("final", func(item any) (any, error) {
+		return item.(Mapped).Price * 2, nil
+	}, "price", "title"))
+	require.Nil(t, err)
+
+	require.Equal(t, []string{"final", "title", "price"}, schema.Requires([]string{"final", "title"}))
+	require.Equal(t, []string{"title"}, schema.Requires([]string{"title"}))
+}
+
+func TestSchemaValue(t *testing.T) {
+	schema, err := Compile(Mapped{}, ComputedField("final", func(item any) (any, error) {
+		return item.(Mapped).Price * 2, nil
+	}))
+	require.Nil(t, err)
+
+	item := Mapped{Title: "gosparse", Price: 5}
+
+	value, err := schema.Value(item, "title")
+	require.Nil(t, err)
+	require.Equal(t, "gosparse", value)
+
+	value, err = schema.Value(&item, "final")
+	require.Nil(t, err)
+	require.Equal(t, 10.0, value)
+
+	value, err = schema.Value(item, "author.name")
+	require.Nil(t, err)
+	require.Nil(t, value)
+
+	item.Author = &Author{Name: "Jean"}
+	value, err = schema.Value(item, "author.name")
+	require.Nil(t, err)
+	require.Equal(t, "Jean", value)
+
+	_, err = schema.Value(item, "author.posts.title")
+	require.EqualError(t, err, "can not read author.posts.title: relation is a list")
+
+	_, err = schema.Value(Post{}, "title")
+	require.EqualError(t, err, "can not read title: item is not a gosparse.Mapped")
+}
+
+func TestCompileJoin(t *testing.T) {
+	type Joined struct {
+		ID       int       `gosparse:"name:id;select;filter"`
+		Author   *Author   `gosparse:"name:author;relation;join:author_id=people.id"`
+		Comments []Comment `gosparse:"name:comments;relation"`
+	}
+
+	schema, err := Compile(Joined{}, JoinRelation("comments", "id", "public.comments.post_id"))
+	require.Nil(t, err)
+
+	storage, _ := schema.Storage("author")
+	require.Equal(t, &Join{Local: "author_id", Table: "people", Remote: "id"}, storage.Join)
+
+	storage, _ = schema.Storage("comments")
+	require.Equal(t, &Join{Local: "id", Table: "public.comments", Remote: "post_id", Many: true}, storage.Join)
+
+	query, err := schema.Gosparse().Parse(url.Values{
+		"sort": {"-author.name,comments.author.name"},
+	})
+	require.Nil(t, err)
+	require.Equal(t, []sort.Key{
+		{Field: "author.name", Sorting: sort.DESC},
+		{Field: "comments.author.name", Sorting: sort.ASC},
+	}, query.Sort)
+
+	_, err = Compile(Joined{}, JoinRelation("author", "author_id", "people"))
+	require.EqualError(t, err, "invalid join of relation author, expected local=table.remote")
+
+	_, err = Compile(Joined{}, JoinRelation("id", "id", "people.id"))
+	require.EqualError(t, err, "join of id requires a relation")
+}
@@ -0,0 +1,21 @@
+// Package typed
+//
+// Os valores do parâmetro "filter" são extraídos como texto. O pacote typed
+// converte os valores de um filtro para o tipo do campo da estrutura sem
+// reflexão, com um conversor (Parser) por tipo:
+//
+//	price, err := typed.Get(q, "price", typed.Float[float64])
+//	if err != nil {
+//		// filter[price_gte]=abc
+//		// invalid filter price: value abc is not a valid float64
+//	}
+//
+//	if price.Present && price.Predicate == gosparse.GTE {
+//		// price.Values[0] é um float64
+//	}
+//
+// É utilizado pelo código gerado pelo comando gosparse-gen, que declara um
+// método para cada campo filtrável da estrutura:
+//
+//	price, err := q.Filters.Price()
+package typed
@@ -0,0 +1,160 @@
+package typed
+
+import (
+	"encoding"
+	"fmt"
+	"strconv"
+	"time"
+	"unsafe"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/jeanmolossi/gosparse/internal/backend"
+	"github.com/jeanmolossi/gosparse/internal/convert"
+	"github.com/jeanmolossi/gosparse/internal/filter"
+)
+
+// Filter é o filtro de um campo com os valores convertidos para o tipo do
+// campo da estrutura.
+//
+//	// filter[price_gte]=10
+//	Filter[float64]{Present: true, Predicate: gosparse.GTE, Values: []float64{10}}
+type Filter[T any] struct {
+	// Present indica se o campo foi filtrado
+	Present bool
+	// Predicate é o predicado solicitado
+	Predicate gosparse.Predicate
+	// Values são os valores convertidos. Vazio nos predicados de presença
+	// (null, notnull e blank), que utilizam Flag.
+	Values []T
+	// Flag é o valor dos predicados de presença. Sem valor o predicado é
+	// verdadeiro:
+	//
+	//	filter[deleted_at_null]        // true
+	//	filter[deleted_at_null]=false  // false
+	Flag bool
+}
+
+// Get devolve o filtro do campo no Query com os valores convertidos por
+// parse (veja os Parsers). Caso o campo não tenha sido filtrado, Present
+// será false.
+//
+//	typed.Get(q, "price", typed.Float[float64])
+func Get[T any](q *gosparse.Query, field string, parse func(raw string) (T, error)) (Filter[T], error) {
+	f, present := q.Filter[field]
+	if !present {
+		return Filter[T]{}, nil
+	}
+
+	result := Filter[T]{Present: true, Predicate: f.Predicate}
+
+	switch f.Predicate {
+	case filter.NULL, filter.NOT_NULL, filter.BLANK:
+		flag, err := backend.Flag(field, f)
+		if err != nil {
+			return Filter[T]{}, err
+		}
+
+		result.Flag = flag
+		return result, nil
+	}
+
+	result.Values = make([]T, 0, len(f.Values))
+	for _, raw := range f.Values {
+		value, err := parse(raw)
+		if err != nil {
+			return Filter[T]{}, fmt.Errorf("invalid filter %s: %w", field, err)
+		}
+
+		result.Values = append(result.Values, value)
+	}
+
+	return result, nil
+}
+
+// Parsers -----------------------------
+
+// String converte o texto para o tipo texto do campo.
+func String[T ~string](raw string) (T, error) {
+	return T(raw), nil
+}
+
+// Int converte o texto para o tipo inteiro do campo, respeitando o tamanho
+// do tipo (por exemplo int8).
+func Int[T ~int | ~int8 | ~int16 | ~int32 | ~int64](raw string) (T, error) {
+	var zero T
+
+	n, err := strconv.ParseInt(raw, 10, bits(zero))
+	if err != nil {
+		return zero, invalid(raw, zero)
+	}
+
+	return T(n), nil
+}
+
+// Uint converte o texto para o tipo inteiro sem sinal do campo.
+func Uint[T ~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64](raw string) (T, error) {
+	var zero T
+
+	n, err := strconv.ParseUint(raw, 10, bits(zero))
+	if err != nil {
+		return zero, invalid(raw, zero)
+	}
+
+	return T(n), nil
+}
+
+// Float converte o texto para o tipo decimal do campo.
+func Float[T ~float32 | ~float64](raw string) (T, error) {
+	var zero T
+
+	n, err := strconv.ParseFloat(raw, bits(zero))
+	if err != nil {
+		return zero, invalid(raw, zero)
+	}
+
+	return T(n), nil
+}
+
+// Bool converte o texto para o tipo booleano do campo.
+func Bool[T ~bool](raw string) (T, error) {
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		var zero T
+		return zero, invalid(raw, zero)
+	}
+
+	return T(b), nil
+}
+
+// Time converte o texto para time.Time no formato time.RFC3339 ou somente
+// com a data ("2006-01-02").
+func Time(raw string) (time.Time, error) {
+	return convert.Time(raw)
+}
+
+// Text converte o texto para o tipo do campo que implementa
+// encoding.TextUnmarshaler.
+//
+//	typed.Text[netip.Addr]
+func Text[T any, PT interface {
+	*T
+	encoding.TextUnmarshaler
+}](raw string) (T, error) {
+	var value T
+
+	if err := PT(&value).UnmarshalText([]byte(raw)); err != nil {
+		return value, invalid(raw, value)
+	}
+
+	return value, nil
+}
+
+// bits devolve o tamanho em bits do tipo numérico.
+func bits[T any](zero T) int {
+	return int(unsafe.Sizeof(zero)) * 8
+}
+
+// invalid devolve o erro de conversão do texto para o tipo.
+func invalid[T any](raw string, zero T) error {
+	return fmt.Errorf("value %s is not a valid %T", raw, zero)
+}
@@ -0,0 +1,202 @@
+package typed
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jeanmolossi/gosparse"
+	"github.com/stretchr/testify/require"
+)
+
+type level int8
+
+type name string
+
+type code [2]byte
+
+func (c *code) UnmarshalText(text []byte) error {
+	if len(text) != 2 {
+		return errInvalidCode
+	}
+
+	copy(c[:], text)
+	return nil
+}
+
+var errInvalidCode = errors.New("invalid code")
+
+func TestParsers(t *testing.T) {
+	testtable := []struct {
+		desc   string
+		parse  func(string) (any, error)
+		raw    string
+		expect any
+		err    string
+	}{
+		{
+			desc:   "should parse strings",
+			parse:  wrap(String[name]),
+			raw:    "ana",
+			expect: name("ana"),
+		},
+		{
+			desc:   "should parse integers",
+			parse:  wrap(Int[level]),
+			raw:    "-12",
+			expect: level(-12),
+		},
+		{
+			desc:  "should reject integers out of the type range",
+			parse: wrap(Int[level]),
+			raw:   "200",
+			err:   "value 200 is not a valid typed.level",
+		},
+		{
+			desc:   "should parse unsigned integers",
+			parse:  wrap(Uint[uint16]),
+			raw:    "65535",
+			expect: uint16(65535),
+		},
+		{
+			desc:  "should reject negative unsigned integers",
+			parse: wrap(Uint[uint]),
+			raw:   "-1",
+			err:   "value -1 is not a valid uint",
+		},
+		{
+			desc:   "should parse floats",
+			parse:  wrap(Float[float32]),
+			raw:    "1.5",
+			expect: float32(1.5),
+		},
+		{
+			desc:   "should parse booleans",
+			parse:  wrap(Bool[bool]),
+			raw:    "true",
+			expect: true,
+		},
+		{
+			desc:  "should reject invalid booleans",
+			parse: wrap(Bool[bool]),
+			raw:   "yes",
+			err:   "value yes is not a valid bool",
+		},
+		{
+			desc:   "should parse RFC3339 times",
+			parse:  wrap(Time),
+			raw:    "2024-01-02T03:04:05Z",
+			expect: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+		{
+			desc:   "should parse dates",
+			parse:  wrap(Time),
+			raw:    "2024-01-02",
+			expect: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			desc:  "should reject invalid times",
+			parse: wrap(Time),
+			raw:   "yesterday",
+			err:   "value yesterday is not a valid time.Time",
+		},
+		{
+			desc:   "should parse text unmarshalers",
+			parse:  wrap(Text[code]),
+			raw:    "br",
+			expect: code{'b', 'r'},
+		},
+		{
+			desc:  "should reject invalid texts",
+			parse: wrap(Text[code]),
+			raw:   "bra",
+			err:   "value bra is not a valid typed.code",
+		},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			value, err := tc.parse(tc.raw)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.expect, value)
+		})
+	}
+}
+
+func TestGet(t *testing.T) {
+	gs := gosparse.New(gosparse.AcceptFilters("level", "deleted_at"))
+
+	testtable := []struct {
+		desc   string
+		query  url.Values
+		field  string
+		expect Filter[level]
+		err    string
+	}{
+		{
+			desc:   "should return absent filters",
+			query:  url.Values{},
+			field:  "level",
+			expect: Filter[level]{},
+		},
+		{
+			desc:   "should convert the values",
+			query:  url.Values{"filter[level_in]": {"1,2"}},
+			field:  "level",
+			expect: Filter[level]{Present: true, Predicate: gosparse.IN, Values: []level{1, 2}},
+		},
+		{
+			desc:  "should reject invalid values",
+			query: url.Values{"filter[level]": {"high"}},
+			field: "level",
+			err:   "invalid filter level: value high is not a valid typed.level",
+		},
+		{
+			desc:   "should set the flag of presence predicates",
+			query:  url.Values{"filter[deleted_at_null]": {""}},
+			field:  "deleted_at",
+			expect: Filter[level]{Present: true, Predicate: gosparse.NULL, Flag: true},
+		},
+		{
+			desc:   "should parse the flag of presence predicates",
+			query:  url.Values{"filter[deleted_at_notnull]": {"false"}},
+			field:  "deleted_at",
+			expect: Filter[level]{Present: true, Predicate: gosparse.NOT_NULL},
+		},
+		{
+			desc:  "should reject invalid flags",
+			query: url.Values{"filter[deleted_at_null]": {"maybe"}},
+			field: "deleted_at",
+			err:   "filter deleted_at with predicate null requires a boolean value",
+		},
+	}
+
+	for _, tc := range testtable {
+		t.Run(tc.desc, func(t *testing.T) {
+			q, err := gs.Parse(tc.query)
+			require.Nil(t, err)
+
+			got, err := Get(q, tc.field, Int[level])
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.expect, got)
+		})
+	}
+}
+
+// wrap adapta o conversor para a tabela de testes.
+func wrap[T any](parse func(string) (T, error)) func(string) (any, error) {
+	return func(raw string) (any, error) {
+		return parse(raw)
+	}
+}